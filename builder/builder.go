@@ -27,8 +27,8 @@ import (
 	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
 	"github.com/ethereum/go-ethereum/flashbotsextra"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/flashbots/go-boost-utils/bls"
-	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner"
 	boostTypes "github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/holiman/uint256"
@@ -43,6 +43,9 @@ const (
 	BlockResubmitIntervalDefault = 500 * time.Millisecond
 
 	SubmissionOffsetFromEndOfSlotSecondsDefault = 3 * time.Second
+
+	// SpeculativeHeadsDefault only builds on the latest head of a slot
+	SpeculativeHeadsDefault = 1
 )
 
 type PubkeyHex string
@@ -76,7 +79,7 @@ type Builder struct {
 	ignoreLatePayloadAttributes bool
 	validator                   *blockvalidation.BlockValidationAPI
 	beaconClient                IBeaconClient
-	builderSecretKey            *bls.SecretKey
+	builderSigner               Signer
 	builderPublicKey            phase0.BLSPubKey
 	builderSigningDomain        phase0.Domain
 	builderResubmitInterval     time.Duration
@@ -84,18 +87,27 @@ type Builder struct {
 
 	limiter                       *rate.Limiter
 	submissionOffsetFromEndOfSlot time.Duration
+	speculativeHeads              int
+	gasLimitPolicies              *blockvalidation.GasLimitPolicies
+	fallbackMode                  miner.FallbackMode
 
-	slotMu        sync.Mutex
-	slotAttrs     types.BuilderPayloadAttributes
-	slotCtx       context.Context
-	slotCtxCancel context.CancelFunc
+	slotMu   sync.Mutex
+	slot     uint64
+	slotJobs []*buildingJob // building jobs of the current slot, least recently requested first
 
 	stop chan struct{}
 }
 
+// buildingJob is a building job on one of the candidate parents of a slot.
+type buildingJob struct {
+	attrs  types.BuilderPayloadAttributes
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
 // BuilderArgs is a struct that contains all the arguments needed to create a new Builder
 type BuilderArgs struct {
-	sk                            *bls.SecretKey
+	signer                        Signer
 	ds                            flashbotsextra.IDatabaseService
 	blockConsumer                 flashbotsextra.BlockConsumer
 	relay                         IRelay
@@ -108,6 +120,12 @@ type BuilderArgs struct {
 	validator                     *blockvalidation.BlockValidationAPI
 	beaconClient                  IBeaconClient
 	submissionOffsetFromEndOfSlot time.Duration
+	// speculativeHeads is the number of candidate parents built on concurrently in a slot
+	speculativeHeads int
+	// gasLimitPolicies adjust the gas limits registered by validators, nil to follow them
+	gasLimitPolicies *blockvalidation.GasLimitPolicies
+	// fallbackMode is the kind of block submitted when regular blocks cannot be built
+	fallbackMode miner.FallbackMode
 
 	limiter *rate.Limiter
 }
@@ -136,16 +154,13 @@ type SubmitBlockOpts struct {
 	ValidatorData ValidatorData
 	// PayloadAttributes are the payload attributes used for block building
 	PayloadAttributes *types.BuilderPayloadAttributes
+	// Fallback is set for fallback blocks, built when regular blocks cannot be built
+	Fallback bool
 }
 
 func NewBuilder(args BuilderArgs) (*Builder, error) {
-	blsPk, err := bls.PublicKeyFromSecretKey(args.sk)
-	if err != nil {
-		return nil, err
-	}
-	pk, err := utils.BlsPublicKeyToPublicKey(blsPk)
-	if err != nil {
-		return nil, err
+	if args.signer == nil {
+		return nil, errors.New("builder signer is required")
 	}
 
 	if args.limiter == nil {
@@ -160,7 +175,10 @@ func NewBuilder(args BuilderArgs) (*Builder, error) {
 		args.submissionOffsetFromEndOfSlot = SubmissionOffsetFromEndOfSlotSecondsDefault
 	}
 
-	slotCtx, slotCtxCancel := context.WithCancel(context.Background())
+	if args.speculativeHeads <= 0 {
+		args.speculativeHeads = SpeculativeHeadsDefault
+	}
+
 	return &Builder{
 		ds:                            args.ds,
 		blockConsumer:                 args.blockConsumer,
@@ -170,16 +188,17 @@ func NewBuilder(args BuilderArgs) (*Builder, error) {
 		ignoreLatePayloadAttributes:   args.ignoreLatePayloadAttributes,
 		validator:                     args.validator,
 		beaconClient:                  args.beaconClient,
-		builderSecretKey:              args.sk,
-		builderPublicKey:              pk,
+		builderSigner:                 args.signer,
+		builderPublicKey:              args.signer.PublicKey(),
 		builderSigningDomain:          args.builderSigningDomain,
 		builderResubmitInterval:       args.builderBlockResubmitInterval,
 		discardRevertibleTxOnErr:      args.discardRevertibleTxOnErr,
 		submissionOffsetFromEndOfSlot: args.submissionOffsetFromEndOfSlot,
+		speculativeHeads:              args.speculativeHeads,
+		gasLimitPolicies:              args.gasLimitPolicies,
+		fallbackMode:                  args.fallbackMode,
 
-		limiter:       args.limiter,
-		slotCtx:       slotCtx,
-		slotCtxCancel: slotCtxCancel,
+		limiter: args.limiter,
 
 		stop: make(chan struct{}, 1),
 	}, nil
@@ -198,7 +217,8 @@ func (b *Builder) Start() error {
 			case <-b.stop:
 				return
 			case payloadAttributes := <-c:
-				// Right now we are building only on a single head. This might change in the future!
+				// Attributes on other heads of the current slot start building on them
+				// speculatively, up to the configured number of heads.
 				if payloadAttributes.Slot < currentSlot {
 					continue
 				} else if payloadAttributes.Slot == currentSlot {
@@ -233,6 +253,10 @@ func (b *Builder) Start() error {
 
 func (b *Builder) Stop() error {
 	close(b.stop)
+
+	b.slotMu.Lock()
+	b.cancelSlotJobs()
+	b.slotMu.Unlock()
 	return nil
 }
 
@@ -293,8 +317,11 @@ func (b *Builder) onSealedBlock(opts SubmitBlockOpts) error {
 		}
 	}
 
+	if opts.Fallback && metrics.EnabledBuilder {
+		fallbackSubmissionMeter.Mark(1)
+	}
 	log.Info("submitted block", "version", dataVersion.String(), "slot", opts.PayloadAttributes.Slot, "value", opts.BlockValue.String(), "parent", opts.Block.ParentHash().String(),
-		"hash", opts.Block.Hash(), "#commitedBundles", len(opts.CommitedBundles))
+		"hash", opts.Block.Hash(), "#commitedBundles", len(opts.CommitedBundles), "fallback", opts.Fallback)
 
 	return nil
 }
@@ -306,7 +333,7 @@ func (b *Builder) getBlockRequest(executableData *engine.ExecutionPayloadEnvelop
 		return nil, err
 	}
 
-	signature, err := ssz.SignMessage(blockBidMsg, b.builderSigningDomain, b.builderSecretKey)
+	signature, err := b.builderSigner.Sign(blockBidMsg, b.builderSigningDomain)
 	if err != nil {
 		log.Error("could not sign builder bid", "err", err)
 		return nil, err
@@ -378,39 +405,74 @@ func (b *Builder) OnPayloadAttribute(attrs *types.BuilderPayloadAttributes) erro
 		return fmt.Errorf("parent block hash not found in block tree given head block hash %s", attrs.HeadHash)
 	}
 
+	targetGasLimit, err := b.gasLimitPolicies.For(string(vd.Pubkey)).Target(vd.GasLimit)
+	if err != nil {
+		return fmt.Errorf("not building for slot %d - %w", attrs.Slot, err)
+	}
+
 	attrs.SuggestedFeeRecipient = [20]byte(vd.FeeRecipient)
-	attrs.GasLimit = core.CalcGasLimit(parentBlock.GasLimit(), vd.GasLimit)
+	attrs.GasLimit = core.CalcGasLimit(parentBlock.GasLimit(), targetGasLimit)
 
 	proposerPubkey, err := utils.HexToPubkey(string(vd.Pubkey))
 	if err != nil {
 		return fmt.Errorf("could not parse pubkey (%s) - %w", vd.Pubkey, err)
 	}
 
-	if !b.eth.Synced() {
-		return errors.New("backend not Synced")
+	// An unsynced node can only submit fallback blocks, built on the requested parent
+	fallbackOnly := !b.eth.Synced()
+	if fallbackOnly {
+		if b.fallbackMode == miner.FallbackNone {
+			return errors.New("backend not Synced")
+		}
+		log.Warn("backend not synced, building fallback blocks", "slot", attrs.Slot, "parent", attrs.HeadHash, "mode", b.fallbackMode)
 	}
 
 	b.slotMu.Lock()
 	defer b.slotMu.Unlock()
 
-	if attrs.Equal(&b.slotAttrs) {
-		log.Debug("ignoring known payload attribute", "slot", attrs.Slot, "hash", attrs.HeadHash)
-		return nil
+	if attrs.Slot != b.slot {
+		b.cancelSlotJobs()
+		b.slot = attrs.Slot
+	}
+
+	// Attributes on a known head either repeat the job's or replace them
+	for i, job := range b.slotJobs {
+		if job.attrs.HeadHash != attrs.HeadHash {
+			continue
+		}
+		b.slotJobs = append(b.slotJobs[:i], b.slotJobs[i+1:]...)
+		if attrs.Equal(&job.attrs) {
+			log.Debug("ignoring known payload attribute", "slot", attrs.Slot, "hash", attrs.HeadHash)
+			b.slotJobs = append(b.slotJobs, job)
+			return nil
+		}
+		job.cancel()
+		break
 	}
 
-	if b.slotCtxCancel != nil {
-		b.slotCtxCancel()
+	// Make room for the new head by cancelling the jobs on the heads requested least recently
+	for len(b.slotJobs) >= b.speculativeHeads {
+		stale := b.slotJobs[0]
+		log.Info("cancelling building job on stale head", "slot", attrs.Slot, "parent", stale.attrs.HeadHash, "head", attrs.HeadHash)
+		stale.cancel()
+		b.slotJobs = b.slotJobs[1:]
 	}
 
 	slotCtx, slotCtxCancel := context.WithTimeout(context.Background(), 12*time.Second)
-	b.slotAttrs = *attrs
-	b.slotCtx = slotCtx
-	b.slotCtxCancel = slotCtxCancel
+	b.slotJobs = append(b.slotJobs, &buildingJob{attrs: *attrs, ctx: slotCtx, cancel: slotCtxCancel})
 
-	go b.runBuildingJob(b.slotCtx, proposerPubkey, vd, attrs)
+	go b.runBuildingJob(slotCtx, proposerPubkey, vd, attrs, fallbackOnly)
 	return nil
 }
 
+// cancelSlotJobs cancels all the building jobs of the current slot. The slot lock must be held.
+func (b *Builder) cancelSlotJobs() {
+	for _, job := range b.slotJobs {
+		job.cancel()
+	}
+	b.slotJobs = nil
+}
+
 type blockQueueEntry struct {
 	block           *types.Block
 	blockValue      *big.Int
@@ -420,9 +482,10 @@ type blockQueueEntry struct {
 	commitedBundles []types.SimulatedBundle
 	allBundles      []types.SimulatedBundle
 	usedSbundles    []types.UsedSBundle
+	fallback        bool
 }
 
-func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes) {
+func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes, fallbackOnly bool) {
 	ctx, cancel := context.WithTimeout(slotCtx, 12*time.Second)
 	defer cancel()
 
@@ -458,6 +521,7 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 				ProposerPubkey:    proposerPubkey,
 				ValidatorData:     vd,
 				PayloadAttributes: attrs,
+				Fallback:          queueBestEntry.fallback,
 			}
 			err := b.onSealedBlock(submitBlockOpts)
 
@@ -478,19 +542,26 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 	// Empties queue, submits the best block for current job with rate limit (global for all jobs)
 	go runResubmitLoop(ctx, b.limiter, queueSignal, submitBestBlock, slotSubmitStartTime)
 
-	// Populates queue with submissions that increase block profit
-	blockHook := func(block *types.Block, blockValue *big.Int, sidecars []*types.BlobTxSidecar, ordersCloseTime time.Time,
-		committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
-	) {
-		if ctx.Err() != nil {
-			return
-		}
+	// Populates queue with submissions that increase block profit. Fallback blocks never
+	// replace regular blocks of the job.
+	newBlockHook := func(fallback bool) miner.BlockHookFn {
+		return func(block *types.Block, blockValue *big.Int, sidecars []*types.BlobTxSidecar, ordersCloseTime time.Time,
+			committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
+		) {
+			if ctx.Err() != nil {
+				return
+			}
 
-		sealedAt := time.Now()
+			sealedAt := time.Now()
 
-		queueMu.Lock()
-		defer queueMu.Unlock()
-		if block.Hash() != queueLastSubmittedHash {
+			queueMu.Lock()
+			defer queueMu.Unlock()
+			if fallback && queueBestEntry.block != nil && !queueBestEntry.fallback {
+				return
+			}
+			if block.Hash() == queueLastSubmittedHash {
+				return
+			}
 			queueBestEntry = blockQueueEntry{
 				block:           block,
 				blockValue:      new(big.Int).Set(blockValue),
@@ -500,6 +571,7 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 				commitedBundles: committedBundles,
 				allBundles:      allBundles,
 				usedSbundles:    usedSbundles,
+				fallback:        fallback,
 			}
 
 			select {
@@ -508,6 +580,23 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 			}
 		}
 	}
+	blockHook, fallbackHook := newBlockHook(false), newBlockHook(true)
+
+	// hasRegularBlock reports whether a regular block was sealed for the job
+	hasRegularBlock := func() bool {
+		queueMu.Lock()
+		defer queueMu.Unlock()
+		return queueBestEntry.block != nil && !queueBestEntry.fallback
+	}
+
+	buildFallbackBlock := func() {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := b.eth.BuildFallbackBlock(attrs, b.fallbackMode, fallbackHook); err != nil {
+			log.Warn("Failed to build fallback block", "slot", attrs.Slot, "mode", b.fallbackMode, "err", err)
+		}
+	}
 
 	// resubmits block builder requests every builderBlockResubmitInterval
 	runRetryLoop(ctx, b.builderResubmitInterval, func() {
@@ -515,9 +604,16 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 			"slot", attrs.Slot,
 			"parent", attrs.HeadHash,
 			"resubmit-interval", b.builderResubmitInterval.String())
+		if fallbackOnly {
+			buildFallbackBlock()
+			return
+		}
 		err := b.eth.BuildBlock(attrs, blockHook)
 		if err != nil {
 			log.Warn("Failed to build block", "err", err)
+			if b.fallbackMode != miner.FallbackNone && !hasRegularBlock() {
+				buildFallbackBlock()
+			}
 		}
 	})
 }
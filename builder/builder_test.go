@@ -1,11 +1,13 @@
 package builder
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
 
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/beacon/engine"
@@ -13,12 +15,15 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
 	"github.com/ethereum/go-ethereum/flashbotsextra"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 func TestOnPayloadAttributes(t *testing.T) {
@@ -50,7 +55,7 @@ func TestOnPayloadAttributes(t *testing.T) {
 		},
 	}
 
-	sk, err := bls.SecretKeyFromBytes(hexutil.MustDecode("0x31ee185dad1220a8c88ca5275e64cf5a5cb09cb621cb30df52c9bee8fbaaf8d7"))
+	signer, err := NewLocalSignerFromHex("0x31ee185dad1220a8c88ca5275e64cf5a5cb09cb621cb30df52c9bee8fbaaf8d7")
 	require.NoError(t, err)
 
 	bDomain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{})
@@ -86,7 +91,7 @@ func TestOnPayloadAttributes(t *testing.T) {
 
 	testEthService := &testEthereumService{synced: true, testExecutableData: testExecutableData, testBlock: testBlock, testBlockValue: big.NewInt(10)}
 	builderArgs := BuilderArgs{
-		sk:                          sk,
+		signer:                      signer,
 		ds:                          flashbotsextra.NilDbService{},
 		relay:                       &testRelay,
 		builderSigningDomain:        bDomain,
@@ -170,3 +175,252 @@ func TestOnPayloadAttributes(t *testing.T) {
 	time.Sleep(2200 * time.Millisecond)
 	require.NotNil(t, testRelay.submittedMsg)
 }
+
+// headEthereumService builds an empty block on the head of the payload attributes.
+type headEthereumService struct {
+	testEthereumService
+}
+
+func (s *headEthereumService) BuildBlock(attrs *types.BuilderPayloadAttributes, sealedBlockCallback miner.BlockHookFn) error {
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHash: attrs.HeadHash,
+		Number:     big.NewInt(10),
+		GasLimit:   attrs.GasLimit,
+		Time:       uint64(attrs.Timestamp),
+		Extra:      attrs.HeadHash[:4],
+		BaseFee:    big.NewInt(16),
+	})
+	sealedBlockCallback(block, big.NewInt(10), nil, time.Now(), nil, nil, nil)
+	return nil
+}
+
+// submissionsRelay passes the submissions of concurrent jobs on a channel.
+type submissionsRelay struct {
+	testRelay
+	submissions chan *builderSpec.VersionedSubmitBlockRequest
+}
+
+func (r *submissionsRelay) SubmitBlock(msg *builderSpec.VersionedSubmitBlockRequest, _ ValidatorData) error {
+	r.submissions <- msg
+	return nil
+}
+
+func TestSpeculativeHeads(t *testing.T) {
+	validator := NewRandomValidator()
+	testRelay := submissionsRelay{
+		testRelay:   testRelay{gvsVd: ValidatorData{Pubkey: PubkeyHex(validator.Pk.String()), GasLimit: 30_000_000}},
+		submissions: make(chan *builderSpec.VersionedSubmitBlockRequest, 16),
+	}
+	signer, err := NewLocalSignerFromHex("0x31ee185dad1220a8c88ca5275e64cf5a5cb09cb621cb30df52c9bee8fbaaf8d7")
+	require.NoError(t, err)
+	builder, err := NewBuilder(BuilderArgs{
+		signer:                       signer,
+		ds:                           flashbotsextra.NilDbService{},
+		blockConsumer:                flashbotsextra.NilDbService{},
+		relay:                        &testRelay,
+		eth:                          &headEthereumService{testEthereumService{synced: true, testBlock: types.NewBlockWithHeader(&types.Header{GasLimit: 30_000_000})}},
+		beaconClient:                 &testBeaconClient{validator: validator},
+		builderBlockResubmitInterval: 100 * time.Millisecond,
+		limiter:                      rate.NewLimiter(rate.Inf, 0),
+		speculativeHeads:             2,
+	})
+	require.NoError(t, err)
+	defer builder.Stop()
+
+	attrs := func(slot uint64, head common.Hash) *types.BuilderPayloadAttributes {
+		return &types.BuilderPayloadAttributes{Slot: slot, HeadHash: head, Timestamp: hexutil.Uint64(time.Now().Unix())}
+	}
+	// waitParents waits for submissions on the given parents, in any order.
+	waitParents := func(parents ...common.Hash) {
+		expected := make(map[common.Hash]bool)
+		for _, parent := range parents {
+			expected[parent] = true
+		}
+		for len(expected) > 0 {
+			select {
+			case msg := <-testRelay.submissions:
+				parent := common.Hash(msg.Bellatrix.Message.ParentHash)
+				require.True(t, expected[parent], "unexpected submission on parent %s", parent)
+				delete(expected, parent)
+			case <-time.After(2 * time.Second):
+				t.Fatalf("missing submissions on parents %v", expected)
+			}
+		}
+	}
+	jobParents := func() []common.Hash {
+		builder.slotMu.Lock()
+		defer builder.slotMu.Unlock()
+		var parents []common.Hash
+		for _, job := range builder.slotJobs {
+			parents = append(parents, job.attrs.HeadHash)
+		}
+		return parents
+	}
+	headA, headB, headC := common.Hash{0x0a}, common.Hash{0x0b}, common.Hash{0x0c}
+
+	// Both candidate heads of the slot are built on and submitted.
+	require.NoError(t, builder.OnPayloadAttribute(attrs(1, headA)))
+	require.NoError(t, builder.OnPayloadAttribute(attrs(1, headB)))
+	waitParents(headA, headB)
+	require.Equal(t, []common.Hash{headA, headB}, jobParents())
+
+	// Flipping back to a known head keeps its job and makes it the most recent one.
+	builder.slotMu.Lock()
+	jobA := builder.slotJobs[0]
+	builder.slotMu.Unlock()
+	require.NoError(t, builder.OnPayloadAttribute(&jobA.attrs))
+	require.Equal(t, []common.Hash{headB, headA}, jobParents())
+	require.NoError(t, jobA.ctx.Err())
+
+	// A third head evicts the head requested least recently.
+	builder.slotMu.Lock()
+	jobB := builder.slotJobs[0]
+	builder.slotMu.Unlock()
+	require.NoError(t, builder.OnPayloadAttribute(attrs(1, headC)))
+	waitParents(headC)
+	require.Equal(t, []common.Hash{headA, headC}, jobParents())
+	require.Error(t, jobB.ctx.Err())
+	require.NoError(t, jobA.ctx.Err())
+
+	// A new slot cancels all the jobs of the previous one.
+	require.NoError(t, builder.OnPayloadAttribute(attrs(2, headC)))
+	waitParents(headC)
+	require.Equal(t, []common.Hash{headC}, jobParents())
+	require.Error(t, jobA.ctx.Err())
+}
+
+func TestGasLimitPolicies(t *testing.T) {
+	validator := NewRandomValidator()
+	pubkey := PubkeyHex(validator.Pk.String())
+	testRelay := submissionsRelay{
+		testRelay:   testRelay{gvsVd: ValidatorData{Pubkey: pubkey, GasLimit: 36_000_000}},
+		submissions: make(chan *builderSpec.VersionedSubmitBlockRequest, 16),
+	}
+	signer, err := NewLocalSignerFromHex("0x31ee185dad1220a8c88ca5275e64cf5a5cb09cb621cb30df52c9bee8fbaaf8d7")
+	require.NoError(t, err)
+	policies := &blockvalidation.GasLimitPolicies{Default: blockvalidation.GasLimitPolicy{Mode: blockvalidation.GasLimitFollow}}
+	builder, err := NewBuilder(BuilderArgs{
+		signer:                       signer,
+		ds:                           flashbotsextra.NilDbService{},
+		blockConsumer:                flashbotsextra.NilDbService{},
+		relay:                        &testRelay,
+		eth:                          &headEthereumService{testEthereumService{synced: true, testBlock: types.NewBlockWithHeader(&types.Header{GasLimit: 30_000_000})}},
+		beaconClient:                 &testBeaconClient{validator: validator},
+		builderBlockResubmitInterval: 100 * time.Millisecond,
+		limiter:                      rate.NewLimiter(rate.Inf, 0),
+		gasLimitPolicies:             policies,
+	})
+	require.NoError(t, err)
+	defer builder.Stop()
+
+	buildGasLimit := func(slot uint64) uint64 {
+		attrs := &types.BuilderPayloadAttributes{Slot: slot, HeadHash: common.Hash{byte(slot)}, Timestamp: hexutil.Uint64(time.Now().Unix())}
+		require.NoError(t, builder.OnPayloadAttribute(attrs))
+		select {
+		case msg := <-testRelay.submissions:
+			return msg.Bellatrix.Message.GasLimit
+		case <-time.After(2 * time.Second):
+			t.Fatal("missing submission")
+			return 0
+		}
+	}
+
+	// By default the registered gas limit is followed.
+	require.Equal(t, core.CalcGasLimit(30_000_000, 36_000_000), buildGasLimit(1))
+
+	// A clamping policy of the validator caps the targeted gas limit.
+	policies.Validators = map[string]blockvalidation.GasLimitPolicy{string(pubkey): {Mode: blockvalidation.GasLimitClamp, Limit: 29_000_000}}
+	require.Equal(t, core.CalcGasLimit(30_000_000, 29_000_000), buildGasLimit(2))
+
+	// A refusing policy of the validator stops the builder from building for it.
+	policies.Validators[string(pubkey)] = blockvalidation.GasLimitPolicy{Mode: blockvalidation.GasLimitRefuse, Limit: 30_000_000}
+	err = builder.OnPayloadAttribute(&types.BuilderPayloadAttributes{Slot: 3, HeadHash: common.Hash{3}})
+	require.ErrorIs(t, err, blockvalidation.ErrGasLimitRefused)
+}
+
+// failingEthereumService fails to build regular blocks and builds empty fallback blocks on
+// the head of the payload attributes.
+type failingEthereumService struct {
+	headEthereumService
+	fallbackModes chan miner.FallbackMode
+}
+
+func (s *failingEthereumService) BuildBlock(attrs *types.BuilderPayloadAttributes, sealedBlockCallback miner.BlockHookFn) error {
+	return errors.New("building failed")
+}
+
+func (s *failingEthereumService) BuildFallbackBlock(attrs *types.BuilderPayloadAttributes, mode miner.FallbackMode, sealedBlockCallback miner.BlockHookFn) error {
+	select {
+	case s.fallbackModes <- mode:
+	default:
+	}
+	return s.headEthereumService.BuildBlock(attrs, sealedBlockCallback)
+}
+
+func TestFallbackBlocks(t *testing.T) {
+	validator := NewRandomValidator()
+	testRelay := submissionsRelay{
+		testRelay:   testRelay{gvsVd: ValidatorData{Pubkey: PubkeyHex(validator.Pk.String()), GasLimit: 30_000_000}},
+		submissions: make(chan *builderSpec.VersionedSubmitBlockRequest, 16),
+	}
+	signer, err := NewLocalSignerFromHex("0x31ee185dad1220a8c88ca5275e64cf5a5cb09cb621cb30df52c9bee8fbaaf8d7")
+	require.NoError(t, err)
+	eth := &failingEthereumService{
+		headEthereumService: headEthereumService{testEthereumService{synced: true, testBlock: types.NewBlockWithHeader(&types.Header{GasLimit: 30_000_000})}},
+		fallbackModes:       make(chan miner.FallbackMode, 16),
+	}
+	newBuilder := func(mode miner.FallbackMode) *Builder {
+		builder, err := NewBuilder(BuilderArgs{
+			signer:                       signer,
+			ds:                           flashbotsextra.NilDbService{},
+			blockConsumer:                flashbotsextra.NilDbService{},
+			relay:                        &testRelay,
+			eth:                          eth,
+			beaconClient:                 &testBeaconClient{validator: validator},
+			builderBlockResubmitInterval: 100 * time.Millisecond,
+			limiter:                      rate.NewLimiter(rate.Inf, 0),
+			fallbackMode:                 mode,
+		})
+		require.NoError(t, err)
+		return builder
+	}
+	attrs := func(slot uint64) *types.BuilderPayloadAttributes {
+		return &types.BuilderPayloadAttributes{Slot: slot, HeadHash: common.Hash{byte(slot)}, Timestamp: hexutil.Uint64(time.Now().Unix())}
+	}
+	waitFallback := func(mode miner.FallbackMode, head common.Hash) {
+		select {
+		case built := <-eth.fallbackModes:
+			require.Equal(t, mode, built)
+		case <-time.After(2 * time.Second):
+			t.Fatal("missing fallback block")
+		}
+		// Submissions of the job of the previous slot may still be in flight
+		for {
+			select {
+			case msg := <-testRelay.submissions:
+				if common.Hash(msg.Bellatrix.Message.ParentHash) == head {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("missing fallback submission")
+			}
+		}
+	}
+
+	// Without fallback blocks, an unsynced builder does not build.
+	builder := newBuilder(miner.FallbackNone)
+	eth.synced = false
+	require.Error(t, builder.OnPayloadAttribute(attrs(1)))
+	builder.Stop()
+
+	// An unsynced builder submits fallback blocks.
+	builder = newBuilder(miner.FallbackPayout)
+	defer builder.Stop()
+	require.NoError(t, builder.OnPayloadAttribute(attrs(2)))
+	waitFallback(miner.FallbackPayout, common.Hash{2})
+
+	// A synced builder submits fallback blocks when building regular blocks fails.
+	eth.synced = true
+	require.NoError(t, builder.OnPayloadAttribute(attrs(3)))
+	waitFallback(miner.FallbackPayout, common.Hash{3})
+}
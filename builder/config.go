@@ -1,6 +1,10 @@
 package builder
 
-import "time"
+import (
+	"time"
+
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
+)
 
 type Config struct {
 	Enabled                          bool          `toml:",omitempty"`
@@ -13,6 +17,14 @@ type Config struct {
 	IgnoreLatePayloadAttributes      bool          `toml:",omitempty"`
 	BuilderSecretKey                 string        `toml:",omitempty"`
 	RelaySecretKey                   string        `toml:",omitempty"`
+	BuilderKeystore                  string        `toml:",omitempty"`
+	BuilderKeystorePasswordFile      string        `toml:",omitempty"`
+	RelayKeystore                    string        `toml:",omitempty"`
+	RelayKeystorePasswordFile        string        `toml:",omitempty"`
+	BuilderRemoteSigner              string        `toml:",omitempty"`
+	BuilderRemoteSignerPubkey        string        `toml:",omitempty"`
+	RelayRemoteSigner                string        `toml:",omitempty"`
+	RelayRemoteSignerPubkey          string        `toml:",omitempty"`
 	ListenAddr                       string        `toml:",omitempty"`
 	GenesisForkVersion               string        `toml:",omitempty"`
 	BellatrixForkVersion             string        `toml:",omitempty"`
@@ -30,6 +42,12 @@ type Config struct {
 	DiscardRevertibleTxOnErr         bool          `toml:",omitempty"`
 	EnableCancellations              bool          `toml:",omitempty"`
 	BlockProcessorURL                string        `toml:",omitempty"`
+	SpeculativeHeads                 int           `toml:",omitempty"`
+	GasLimitPolicy                   string        `toml:",omitempty"`
+	GasLimitPolicyFile               string        `toml:",omitempty"`
+	FallbackBlock                    string        `toml:",omitempty"`
+	OrderSources                     []string      `toml:",omitempty"`
+	BundleNotifyChannel              string        `toml:",omitempty"`
 }
 
 // DefaultConfig is the default config for the builder.
@@ -58,6 +76,42 @@ var DefaultConfig = Config{
 	BuilderRateLimitMaxBurst:      RateLimitBurstDefault,
 	DiscardRevertibleTxOnErr:      false,
 	EnableCancellations:           false,
+	SpeculativeHeads:              SpeculativeHeadsDefault,
+	GasLimitPolicy:                string(blockvalidation.GasLimitFollow),
+	FallbackBlock:                 "none",
+}
+
+// SignerConfig is the config of a BLS signing key. The key is held by a remote signer if
+// its url is set, else read from an EIP-2335 keystore if set, else taken from the hex
+// encoded secret key.
+type SignerConfig struct {
+	SecretKey            string
+	Keystore             string
+	KeystorePasswordFile string
+	RemoteSigner         string
+	RemoteSignerPubkey   string
+}
+
+// BuilderSignerConfig returns the config of the key signing the builder submissions.
+func (cfg *Config) BuilderSignerConfig() SignerConfig {
+	return SignerConfig{
+		SecretKey:            cfg.BuilderSecretKey,
+		Keystore:             cfg.BuilderKeystore,
+		KeystorePasswordFile: cfg.BuilderKeystorePasswordFile,
+		RemoteSigner:         cfg.BuilderRemoteSigner,
+		RemoteSignerPubkey:   cfg.BuilderRemoteSignerPubkey,
+	}
+}
+
+// RelaySignerConfig returns the config of the key signing the bids of the local relay.
+func (cfg *Config) RelaySignerConfig() SignerConfig {
+	return SignerConfig{
+		SecretKey:            cfg.RelaySecretKey,
+		Keystore:             cfg.RelayKeystore,
+		KeystorePasswordFile: cfg.RelayKeystorePasswordFile,
+		RemoteSigner:         cfg.RelayRemoteSigner,
+		RemoteSignerPubkey:   cfg.RelayRemoteSignerPubkey,
+	}
 }
 
 // RelayConfig is the config for a single remote relay.
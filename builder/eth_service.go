@@ -16,6 +16,7 @@ import (
 
 type IEthereumService interface {
 	BuildBlock(attrs *types.BuilderPayloadAttributes, sealedBlockCallback miner.BlockHookFn) error
+	BuildFallbackBlock(attrs *types.BuilderPayloadAttributes, mode miner.FallbackMode, sealedBlockCallback miner.BlockHookFn) error
 	GetBlockByHash(hash common.Hash) *types.Block
 	Config() *params.ChainConfig
 	Synced() bool
@@ -37,6 +38,11 @@ func (t *testEthereumService) BuildBlock(attrs *types.BuilderPayloadAttributes,
 	return nil
 }
 
+func (t *testEthereumService) BuildFallbackBlock(attrs *types.BuilderPayloadAttributes, mode miner.FallbackMode, sealedBlockCallback miner.BlockHookFn) error {
+	sealedBlockCallback(t.testBlock, t.testBlockValue, t.testBlobSidecar, time.Now(), nil, nil, nil)
+	return nil
+}
+
 func (t *testEthereumService) GetBlockByHash(hash common.Hash) *types.Block { return t.testBlock }
 
 func (t *testEthereumService) Config() *params.ChainConfig { return params.TestChainConfig }
@@ -93,6 +99,26 @@ func (s *EthereumService) BuildBlock(attrs *types.BuilderPayloadAttributes, seal
 	}
 }
 
+// BuildFallbackBlock builds a single fallback block of the given mode, without bundles or
+// the configured algorithm, for when regular blocks cannot be built.
+func (s *EthereumService) BuildFallbackBlock(attrs *types.BuilderPayloadAttributes, mode miner.FallbackMode, sealedBlockCallback miner.BlockHookFn) error {
+	args := &miner.BuildPayloadArgs{
+		Parent:       attrs.HeadHash,
+		Timestamp:    uint64(attrs.Timestamp),
+		FeeRecipient: attrs.SuggestedFeeRecipient,
+		GasLimit:     attrs.GasLimit,
+		Random:       attrs.Random,
+		Withdrawals:  attrs.Withdrawals,
+		BeaconRoot:   attrs.ParentBeaconBlockRoot,
+		BlockHook:    sealedBlockCallback,
+	}
+	if err := s.eth.Miner().BuildFallbackBlock(args, mode); err != nil {
+		log.Error("Failed to build fallback block", "mode", mode, "err", err)
+		return err
+	}
+	return nil
+}
+
 func (s *EthereumService) GetBlockByHash(hash common.Hash) *types.Block {
 	return s.eth.BlockChain().GetBlockByHash(hash)
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,10 +23,10 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	eth2UtilBellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
-	"github.com/flashbots/go-boost-utils/utils"
 	"github.com/gorilla/mux"
 	"github.com/holiman/uint256"
 )
@@ -46,15 +47,17 @@ type FullValidatorData struct {
 type LocalRelay struct {
 	beaconClient IBeaconClient
 
-	relaySecretKey        *bls.SecretKey
+	relaySigner           Signer
 	relayPublicKey        phase0.BLSPubKey
 	serializedRelayPubkey hexutil.Bytes
 
 	builderSigningDomain  phase0.Domain
 	proposerSigningDomain phase0.Domain
 
+	db             ethdb.KeyValueStore // nil if registrations are not persisted
 	validatorsLock sync.RWMutex
 	validators     map[PubkeyHex]FullValidatorData
+	registrations  map[PubkeyHex]builderApiV1.SignedValidatorRegistration
 
 	enableBeaconChecks bool
 
@@ -65,17 +68,13 @@ type LocalRelay struct {
 
 	indexTemplate *template.Template
 	fd            ForkData
+
+	// gasLimitPolicies applied by the builder, reported by the status endpoint
+	gasLimitPolicies *blockvalidation.GasLimitPolicies
 }
 
-func NewLocalRelay(sk *bls.SecretKey, beaconClient IBeaconClient, builderSigningDomain, proposerSigningDomain phase0.Domain, fd ForkData, enableBeaconChecks bool) (*LocalRelay, error) {
-	blsPk, err := bls.PublicKeyFromSecretKey(sk)
-	if err != nil {
-		return nil, err
-	}
-	pk, err := utils.BlsPublicKeyToPublicKey(blsPk)
-	if err != nil {
-		return nil, err
-	}
+func NewLocalRelay(signer Signer, db ethdb.KeyValueStore, beaconClient IBeaconClient, builderSigningDomain, proposerSigningDomain phase0.Domain, fd ForkData, enableBeaconChecks bool) (*LocalRelay, error) {
+	pk := signer.PublicKey()
 
 	indexTemplate, err := parseIndexTemplate()
 	if err != nil {
@@ -83,23 +82,29 @@ func NewLocalRelay(sk *bls.SecretKey, beaconClient IBeaconClient, builderSigning
 		indexTemplate = nil
 	}
 
-	return &LocalRelay{
+	r := &LocalRelay{
 		beaconClient: beaconClient,
 
-		relaySecretKey: sk,
+		relaySigner:    signer,
 		relayPublicKey: pk,
 
 		builderSigningDomain:  builderSigningDomain,
 		proposerSigningDomain: proposerSigningDomain,
-		serializedRelayPubkey: bls.PublicKeyToBytes(blsPk),
+		serializedRelayPubkey: pk[:],
 
-		validators: make(map[PubkeyHex]FullValidatorData),
+		db:            db,
+		validators:    make(map[PubkeyHex]FullValidatorData),
+		registrations: make(map[PubkeyHex]builderApiV1.SignedValidatorRegistration),
 
 		enableBeaconChecks: enableBeaconChecks,
 
 		indexTemplate: indexTemplate,
 		fd:            fd,
-	}, nil
+	}
+	if err := r.loadValidatorRegistrations(); err != nil {
+		return nil, fmt.Errorf("could not load validator registrations: %w", err)
+	}
+	return r, nil
 }
 
 func (r *LocalRelay) Start() error {
@@ -145,37 +150,52 @@ func (r *LocalRelay) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		return
 	}
 
+	if err := r.registerValidators(payload); err != nil {
+		if errors.Is(err, errRegistrationStore) {
+			log.Error("could not store validator registrations", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// registerValidators verifies the signed validator registrations and makes them the current
+// registrations of their validators. Either all registrations are accepted or none is.
+func (r *LocalRelay) registerValidators(payload []builderApiV1.SignedValidatorRegistration) error {
 	for _, registerRequest := range payload {
+		if registerRequest.Message == nil {
+			return errors.New("invalid payload")
+		}
+
 		if len(registerRequest.Message.Pubkey) != 48 {
-			respondError(w, http.StatusBadRequest, "invalid pubkey")
-			return
+			return errors.New("invalid pubkey")
 		}
 
 		if len(registerRequest.Signature) != 96 {
-			respondError(w, http.StatusBadRequest, "invalid signature")
-			return
+			return errors.New("invalid signature")
 		}
 
 		ok, err := ssz.VerifySignature(registerRequest.Message, r.builderSigningDomain, registerRequest.Message.Pubkey[:], registerRequest.Signature[:])
 		if !ok || err != nil {
 			log.Error("error verifying signature", "err", err)
-			respondError(w, http.StatusBadRequest, "invalid signature")
-			return
+			return errors.New("invalid signature")
 		}
 
 		// Do not check timestamp before signature, as it would leak validator data
 		if registerRequest.Message.Timestamp.Unix() > time.Now().Add(10*time.Second).Unix() {
 			log.Error("invalid timestamp", "timestamp", registerRequest.Message.Timestamp)
-			respondError(w, http.StatusBadRequest, "invalid payload")
-			return
+			return errors.New("invalid payload")
 		}
 	}
 
 	for _, registerRequest := range payload {
 		pubkeyHex := PubkeyHex(registerRequest.Message.Pubkey.String())
 		if !r.beaconClient.isValidator(pubkeyHex) {
-			respondError(w, http.StatusBadRequest, "not a validator")
-			return
+			return errors.New("not a validator")
 		}
 	}
 
@@ -186,32 +206,28 @@ func (r *LocalRelay) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		pubkeyHex := PubkeyHex(registerRequest.Message.Pubkey.String())
 		if previousValidatorData, ok := r.validators[pubkeyHex]; ok {
 			if uint64(registerRequest.Message.Timestamp.Unix()) < previousValidatorData.Timestamp {
-				respondError(w, http.StatusBadRequest, "invalid timestamp")
-				return
+				return errors.New("invalid timestamp")
 			}
 
 			if uint64(registerRequest.Message.Timestamp.Unix()) == previousValidatorData.Timestamp && (registerRequest.Message.FeeRecipient != previousValidatorData.FeeRecipient || registerRequest.Message.GasLimit != previousValidatorData.GasLimit) {
-				respondError(w, http.StatusBadRequest, "invalid timestamp")
-				return
+				return errors.New("invalid timestamp")
 			}
 		}
 	}
 
-	for _, registerRequest := range payload {
-		pubkeyHex := PubkeyHex(strings.ToLower(registerRequest.Message.Pubkey.String()))
-		r.validators[pubkeyHex] = FullValidatorData{
-			ValidatorData: ValidatorData{
-				Pubkey:       pubkeyHex,
-				FeeRecipient: registerRequest.Message.FeeRecipient,
-				GasLimit:     registerRequest.Message.GasLimit,
-			},
-			Timestamp: uint64(registerRequest.Message.Timestamp.Unix()),
+	if r.db != nil {
+		if err := writeValidatorRegistrations(r.db, payload); err != nil {
+			return fmt.Errorf("%w: %v", errRegistrationStore, err)
 		}
+	}
 
+	for _, registerRequest := range payload {
+		r.setValidatorRegistration(registerRequest)
+
+		pubkeyHex := PubkeyHex(strings.ToLower(registerRequest.Message.Pubkey.String()))
 		log.Info("registered validator", "pubkey", pubkeyHex, "data", r.validators[pubkeyHex])
 	}
-
-	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 func (r *LocalRelay) GetValidatorForSlot(nextSlot uint64) (ValidatorData, error) {
@@ -273,7 +289,7 @@ func (r *LocalRelay) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 		Value:  profit,
 		Pubkey: r.relayPublicKey,
 	}
-	signature, err := ssz.SignMessage(&bid, r.builderSigningDomain, r.relaySecretKey)
+	signature, err := r.relaySigner.Sign(&bid, r.builderSigningDomain)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "internal server error")
 		return
@@ -415,8 +431,44 @@ func respondError(w http.ResponseWriter, code int, message string) {
 	}
 }
 
+// registrationGasLimit is the gas limit targeted for a registered validator.
+type registrationGasLimit struct {
+	Pubkey             PubkeyHex                      `json:"pubkey"`
+	RegisteredGasLimit uint64                         `json:"registered_gas_limit,string"`
+	Policy             blockvalidation.GasLimitPolicy `json:"policy"`
+	TargetGasLimit     uint64                         `json:"target_gas_limit,string,omitempty"`
+	Refused            bool                           `json:"refused,omitempty"`
+}
+
+type statusResponse struct {
+	GasLimitPolicies *blockvalidation.GasLimitPolicies `json:"gas_limit_policies"`
+	Registrations    []registrationGasLimit            `json:"registrations"`
+}
+
 func (r *LocalRelay) handleStatus(w http.ResponseWriter, req *http.Request) {
+	policies := r.gasLimitPolicies
+	if policies == nil {
+		policies = &blockvalidation.GasLimitPolicies{Default: blockvalidation.GasLimitPolicy{Mode: blockvalidation.GasLimitFollow}}
+	}
+
+	r.validatorsLock.RLock()
+	status := statusResponse{GasLimitPolicies: policies, Registrations: make([]registrationGasLimit, 0, len(r.validators))}
+	for pubkey, vd := range r.validators {
+		registration := registrationGasLimit{Pubkey: pubkey, RegisteredGasLimit: vd.GasLimit, Policy: policies.For(string(pubkey))}
+		target, err := registration.Policy.Target(vd.GasLimit)
+		registration.TargetGasLimit, registration.Refused = target, err != nil
+		status.Registrations = append(status.Registrations, registration)
+	}
+	r.validatorsLock.RUnlock()
+	sort.Slice(status.Registrations, func(i, j int) bool {
+		return status.Registrations[i].Pubkey < status.Registrations[j].Pubkey
+	})
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Error("could not encode status", "err", err)
+	}
 }
 
 func ExecutionPayloadHeaderEqual(l, r *bellatrix.ExecutionPayloadHeader) bool {
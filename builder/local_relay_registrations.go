@@ -0,0 +1,135 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var errRegistrationStore = errors.New("could not store validator registrations")
+
+// writeValidatorRegistrations stores the signed validator registrations in the database.
+func writeValidatorRegistrations(db ethdb.KeyValueStore, registrations []builderApiV1.SignedValidatorRegistration) error {
+	batch := db.NewBatch()
+	for i := range registrations {
+		enc, err := registrations[i].MarshalSSZ()
+		if err != nil {
+			return err
+		}
+		rawdb.WriteValidatorRegistration(batch, registrations[i].Message.Pubkey[:], enc)
+	}
+	return batch.Write()
+}
+
+// readValidatorRegistrations reads all the signed validator registrations stored in the
+// database.
+func readValidatorRegistrations(db ethdb.KeyValueStore) ([]builderApiV1.SignedValidatorRegistration, error) {
+	it := rawdb.IterateValidatorRegistrations(db)
+	defer it.Release()
+
+	var registrations []builderApiV1.SignedValidatorRegistration
+	for it.Next() {
+		var registration builderApiV1.SignedValidatorRegistration
+		if err := registration.UnmarshalSSZ(it.Value()); err != nil {
+			return nil, fmt.Errorf("invalid validator registration %x: %w", it.Key(), err)
+		}
+		registrations = append(registrations, registration)
+	}
+	return registrations, it.Error()
+}
+
+// loadValidatorRegistrations restores the validator registrations stored in the database.
+// They were verified when registered, so they are not verified again.
+func (r *LocalRelay) loadValidatorRegistrations() error {
+	if r.db == nil {
+		return nil
+	}
+	registrations, err := readValidatorRegistrations(r.db)
+	if err != nil {
+		return err
+	}
+
+	r.validatorsLock.Lock()
+	defer r.validatorsLock.Unlock()
+	for _, registration := range registrations {
+		r.setValidatorRegistration(registration)
+	}
+	log.Info("Loaded validator registrations", "count", len(registrations))
+	return nil
+}
+
+// setValidatorRegistration makes the given registration the current one of its validator.
+// The validators lock must be held.
+func (r *LocalRelay) setValidatorRegistration(registration builderApiV1.SignedValidatorRegistration) {
+	pubkeyHex := PubkeyHex(strings.ToLower(registration.Message.Pubkey.String()))
+	r.validators[pubkeyHex] = FullValidatorData{
+		ValidatorData: ValidatorData{
+			Pubkey:       pubkeyHex,
+			FeeRecipient: registration.Message.FeeRecipient,
+			GasLimit:     registration.Message.GasLimit,
+		},
+		Timestamp: uint64(registration.Message.Timestamp.Unix()),
+	}
+	r.registrations[pubkeyHex] = registration
+}
+
+// ValidatorRegistrations returns the current signed registrations of all validators, sorted
+// by public key.
+func (r *LocalRelay) ValidatorRegistrations() []builderApiV1.SignedValidatorRegistration {
+	r.validatorsLock.RLock()
+	defer r.validatorsLock.RUnlock()
+
+	registrations := make([]builderApiV1.SignedValidatorRegistration, 0, len(r.registrations))
+	for _, registration := range r.registrations {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return bytes.Compare(registrations[i].Message.Pubkey[:], registrations[j].Message.Pubkey[:]) < 0
+	})
+	return registrations
+}
+
+// LocalRelayAdminAPI exports and imports the validator registrations of the local relay, so
+// that they can be moved to another node without waiting for validators to re-register.
+type LocalRelayAdminAPI struct {
+	relay *LocalRelay
+}
+
+func NewLocalRelayAdminAPI(relay *LocalRelay) *LocalRelayAdminAPI {
+	return &LocalRelayAdminAPI{relay: relay}
+}
+
+// ExportValidatorRegistrations returns the current signed registrations of all validators.
+func (api *LocalRelayAdminAPI) ExportValidatorRegistrations() []builderApiV1.SignedValidatorRegistration {
+	return api.relay.ValidatorRegistrations()
+}
+
+// ImportValidatorRegistrations registers the given signed registrations as if they were
+// submitted by the validators, and returns the number of registrations imported.
+// Registrations that are invalid or older than the current registration of their validator
+// are skipped.
+func (api *LocalRelayAdminAPI) ImportValidatorRegistrations(registrations []builderApiV1.SignedValidatorRegistration) (int, error) {
+	imported := 0
+	for _, registration := range registrations {
+		if registration.Message == nil {
+			continue
+		}
+		err := api.relay.registerValidators([]builderApiV1.SignedValidatorRegistration{registration})
+		if errors.Is(err, errRegistrationStore) {
+			return imported, err
+		}
+		if err != nil {
+			log.Warn("Skipping validator registration import", "pubkey", registration.Message.Pubkey, "err", err)
+			continue
+		}
+		imported++
+	}
+	return imported, nil
+}
@@ -0,0 +1,103 @@
+package builder
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalRelayWithDB(t *testing.T, db ethdb.KeyValueStore, validator *ValidatorPrivateData) *LocalRelay {
+	sk, err := bls.GenerateRandomSecretKey()
+	require.NoError(t, err)
+	signer, err := NewLocalSigner(sk)
+	require.NoError(t, err)
+	bDomain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{})
+	relay, err := NewLocalRelay(signer, db, &testBeaconClient{validator: validator}, bDomain, phase0.Domain{}, ForkData{}, true)
+	require.NoError(t, err)
+	return relay
+}
+
+// signedRegistration returns a registration of the validator with the given timestamp.
+func signedRegistration(t *testing.T, relay *LocalRelay, v *ValidatorPrivateData, timestamp time.Time) []builderApiV1.SignedValidatorRegistration {
+	payload, err := prepareRegistrationMessage(t, relay.builderSigningDomain, v)
+	require.NoError(t, err)
+	payload[0].Message.Timestamp = timestamp
+	payload[0].Signature, err = v.Sign(payload[0].Message, relay.builderSigningDomain)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestValidatorRegistrationPersistence(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	validator := NewRandomValidator()
+	relay := newTestLocalRelayWithDB(t, db, validator)
+
+	payload, err := prepareRegistrationMessage(t, relay.builderSigningDomain, validator)
+	require.NoError(t, err)
+	rr := testRequest(t, relay, http.MethodPost, _PathRegisterValidator, payload)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// A relay restarted on the same database knows the proposer without re-registration.
+	restarted := newTestLocalRelayWithDB(t, db, validator)
+	vd, err := restarted.GetValidatorForSlot(1)
+	require.NoError(t, err)
+	require.Equal(t, relay.validators, restarted.validators)
+	require.EqualValues(t, testLocalRelayValidatorGasLimit, vd.GasLimit)
+
+	// Timestamps must still be monotonic for the restored registrations.
+	stale := signedRegistration(t, relay, validator, payload[0].Message.Timestamp.Add(-time.Minute))
+	rr = testRequest(t, restarted, http.MethodPost, _PathRegisterValidator, stale)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, `{"code":400,"message":"invalid timestamp"}`+"\n", rr.Body.String())
+}
+
+func TestValidatorRegistrationExportImport(t *testing.T) {
+	validators := []*ValidatorPrivateData{NewRandomValidator(), NewRandomValidator()}
+	source := newTestLocalRelayWithDB(t, nil, validators[0])
+	for _, v := range validators {
+		registerValidator(t, v, source)
+	}
+	exported := NewLocalRelayAdminAPI(source).ExportValidatorRegistrations()
+	require.Len(t, exported, 2)
+
+	db := rawdb.NewMemoryDatabase()
+	target := newTestLocalRelayWithDB(t, db, validators[0])
+	api := NewLocalRelayAdminAPI(target)
+
+	// Registrations newer than the imported ones are kept, the others are imported.
+	newer := signedRegistration(t, target, validators[1], time.Now().Add(5*time.Second))
+	rr := testRequest(t, target, http.MethodPost, _PathRegisterValidator, newer)
+	require.Equal(t, http.StatusOK, rr.Code)
+	imported, err := api.ImportValidatorRegistrations(exported)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported)
+	require.Equal(t, source.validators[PubkeyHex(validators[0].Pk.String())], target.validators[PubkeyHex(validators[0].Pk.String())])
+	require.NotEqual(t, source.validators[PubkeyHex(validators[1].Pk.String())], target.validators[PubkeyHex(validators[1].Pk.String())])
+
+	// Tampered registrations are rejected.
+	tampered := exported[0]
+	message := *tampered.Message
+	message.GasLimit++
+	tampered.Message = &message
+	imported, err = api.ImportValidatorRegistrations([]builderApiV1.SignedValidatorRegistration{tampered})
+	require.NoError(t, err)
+	require.Zero(t, imported)
+
+	// Imported registrations are persisted.
+	restarted := newTestLocalRelayWithDB(t, db, validators[0])
+	require.Equal(t, target.validators, restarted.validators)
+	expected, err := json.Marshal(api.ExportValidatorRegistrations())
+	require.NoError(t, err)
+	restored, err := json.Marshal(NewLocalRelayAdminAPI(restarted).ExportValidatorRegistrations())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(restored))
+}
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
 	"github.com/ethereum/go-ethereum/flashbotsextra"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/flashbots/go-boost-utils/bls"
@@ -40,14 +42,15 @@ const (
 func newTestBackend(t *testing.T, forkchoiceData *engine.ExecutableData, block *types.Block, blockValue *big.Int) (*Builder, *LocalRelay, *ValidatorPrivateData) {
 	validator := NewRandomValidator()
 	sk, _ := bls.GenerateRandomSecretKey()
+	signer, _ := NewLocalSigner(sk)
 	bDomain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{})
 	genesisValidatorsRoot := phase0.Root(common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000000"))
 	cDomain := ssz.ComputeDomain(ssz.DomainTypeBeaconProposer, [4]byte{0x02, 0x0, 0x0, 0x0}, genesisValidatorsRoot)
 	beaconClient := &testBeaconClient{validator: validator}
-	localRelay, _ := NewLocalRelay(sk, beaconClient, bDomain, cDomain, ForkData{}, true)
+	localRelay, _ := NewLocalRelay(signer, nil, beaconClient, bDomain, cDomain, ForkData{}, true)
 	ethService := &testEthereumService{synced: true, testExecutableData: forkchoiceData, testBlock: block, testBlockValue: blockValue}
 	builderArgs := BuilderArgs{
-		sk:                          sk,
+		signer:                      signer,
 		ds:                          flashbotsextra.NilDbService{},
 		relay:                       localRelay,
 		builderSigningDomain:        bDomain,
@@ -109,6 +112,24 @@ func TestValidatorRegistration(t *testing.T) {
 	// TODO: cover all errors
 }
 
+func TestStatusGasLimitPolicies(t *testing.T) {
+	_, relay, _ := newTestBackend(t, nil, nil, nil)
+	v := NewRandomValidator()
+	registerValidator(t, v, relay)
+	pubkey := strings.ToLower(v.Pk.String())
+	relay.gasLimitPolicies = &blockvalidation.GasLimitPolicies{
+		Default:    blockvalidation.GasLimitPolicy{Mode: blockvalidation.GasLimitFollow},
+		Validators: map[string]blockvalidation.GasLimitPolicy{pubkey: {Mode: blockvalidation.GasLimitRefuse, Limit: 10_000_000}},
+	}
+
+	rr := testRequest(t, relay, http.MethodGet, _PathStatus, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, fmt.Sprintf(`{
+		"gas_limit_policies": {"default": "follow", "validators": {%[1]q: "refuse:10000000"}},
+		"registrations": [{"pubkey": %[1]q, "registered_gas_limit": "%[2]d", "policy": "refuse:10000000", "refused": true}]
+	}`, pubkey, testLocalRelayValidatorGasLimit), rr.Body.String())
+}
+
 func prepareRegistrationMessage(t *testing.T, domain phase0.Domain, v *ValidatorPrivateData) ([]builderApiV1.SignedValidatorRegistration, error) {
 	var pubkey phase0.BLSPubKey
 	copy(pubkey[:], v.Pk)
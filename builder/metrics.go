@@ -0,0 +1,7 @@
+package builder
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var fallbackSubmissionMeter = metrics.NewRegisteredMeter("builder/submission/fallback", nil)
@@ -17,9 +17,9 @@ import (
 	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
 	"github.com/ethereum/go-ethereum/flashbotsextra"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/flashbots/go-utils/httplogger"
 	"github.com/gorilla/mux"
@@ -129,9 +129,15 @@ func NewService(listenAddr string, localRelay *LocalRelay, builder IBuilder) *Se
 }
 
 func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
-	envBuilderSkBytes, err := hexutil.Decode(cfg.BuilderSecretKey)
+	builderSigner, err := NewSigner(cfg.BuilderSignerConfig())
 	if err != nil {
-		return errors.New("incorrect builder API secret key provided")
+		return fmt.Errorf("incorrect builder API signing key provided: %w", err)
+	}
+	if cfg.BuilderRemoteSigner == "" && cfg.BuilderKeystore == "" && cfg.BuilderSecretKey == DefaultConfig.BuilderSecretKey {
+		log.Warn("Builder submissions are signed with the well-known default secret key")
+	}
+	if cfg.EnableLocalRelay && cfg.RelayRemoteSigner == "" && cfg.RelayKeystore == "" && cfg.RelaySecretKey == DefaultConfig.RelaySecretKey {
+		log.Warn("Local relay headers are signed with the well-known default secret key")
 	}
 
 	genesisForkVersionBytes, err := hexutil.Decode(cfg.GenesisForkVersion)
@@ -162,22 +168,28 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		beaconClient = NewMultiBeaconClient(cfg.BeaconEndpoints, cfg.SlotsInEpoch, cfg.SecondsInSlot)
 	}
 
+	gasLimitPolicies, err := blockvalidation.NewGasLimitPolicies(cfg.GasLimitPolicy, cfg.GasLimitPolicyFile)
+	if err != nil {
+		return fmt.Errorf("invalid gas limit policy: %w", err)
+	}
+
+	fallbackMode, err := miner.ParseFallbackMode(cfg.FallbackBlock)
+	if err != nil {
+		return err
+	}
+
 	var localRelay *LocalRelay
 	if cfg.EnableLocalRelay {
-		envRelaySkBytes, err := hexutil.Decode(cfg.RelaySecretKey)
-		if err != nil {
-			return errors.New("incorrect builder API secret key provided")
-		}
-
-		relaySk, err := bls.SecretKeyFromBytes(envRelaySkBytes[:])
+		relaySigner, err := NewSigner(cfg.RelaySignerConfig())
 		if err != nil {
-			return errors.New("incorrect builder API secret key provided")
+			return fmt.Errorf("incorrect local relay signing key provided: %w", err)
 		}
 
-		localRelay, err = NewLocalRelay(relaySk, beaconClient, builderSigningDomain, proposerSigningDomain, ForkData{cfg.GenesisForkVersion, cfg.BellatrixForkVersion, cfg.GenesisValidatorsRoot}, cfg.EnableValidatorChecks)
+		localRelay, err = NewLocalRelay(relaySigner, backend.ChainDb(), beaconClient, builderSigningDomain, proposerSigningDomain, ForkData{cfg.GenesisForkVersion, cfg.BellatrixForkVersion, cfg.GenesisValidatorsRoot}, cfg.EnableValidatorChecks)
 		if err != nil {
 			return fmt.Errorf("failed to create local relay: %w", err)
 		}
+		localRelay.gasLimitPolicies = gasLimitPolicies
 	}
 
 	var relay IRelay
@@ -215,6 +227,7 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 			}
 		}
 		validator = blockvalidation.NewBlockValidationAPI(backend, accessVerifier, cfg.ValidationUseCoinbaseDiff, cfg.ValidationExcludeWithdrawals)
+		validator.SetGasLimitPolicies(gasLimitPolicies)
 	}
 
 	// Set up builder rate limiter based on environment variables or CLI flags.
@@ -273,24 +286,34 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		ds = flashbotsextra.NilDbService{}
 	}
 
-	// Bundle fetcher
-	if !cfg.DisableBundleFetcher {
+	// Bundle fetcher, polling the database unless disabled and receiving the bundles of the
+	// configured order sources
+	if !cfg.DisableBundleFetcher || len(cfg.OrderSources) > 0 {
 		mevBundleCh := make(chan []types.MevBundle)
 		blockNumCh := make(chan int64)
-		bundleFetcher := flashbotsextra.NewBundleFetcher(backend, ds, blockNumCh, mevBundleCh, true)
-		backend.RegisterBundleFetcher(bundleFetcher)
+		var fetcherDs flashbotsextra.IDatabaseService
+		if !cfg.DisableBundleFetcher {
+			fetcherDs = ds
+		}
+		bundleFetcher := flashbotsextra.NewBundleFetcher(backend, fetcherDs, blockNumCh, mevBundleCh, true)
+		bundleFetcher.ListenBundleNotifications(cfg.BundleNotifyChannel)
+		for _, rawurl := range cfg.OrderSources {
+			source, err := flashbotsextra.NewOrderSource(rawurl)
+			if err != nil {
+				return fmt.Errorf("invalid order source: %w", err)
+			}
+			bundleFetcher.AddOrderSource(source)
+		}
+		if fetcherDs != nil {
+			backend.RegisterBundleFetcher(bundleFetcher)
+		}
 		go bundleFetcher.Run()
 	}
 
 	ethereumService := NewEthereumService(backend)
 
-	builderSk, err := bls.SecretKeyFromBytes(envBuilderSkBytes[:])
-	if err != nil {
-		return errors.New("incorrect builder API secret key provided")
-	}
-
 	builderArgs := BuilderArgs{
-		sk:                            builderSk,
+		signer:                        builderSigner,
 		blockConsumer:                 blockConsumer,
 		ds:                            ds,
 		dryRun:                        cfg.DryRun,
@@ -304,6 +327,9 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		validator:                     validator,
 		beaconClient:                  beaconClient,
 		limiter:                       limiter,
+		speculativeHeads:              cfg.SpeculativeHeads,
+		gasLimitPolicies:              gasLimitPolicies,
+		fallbackMode:                  fallbackMode,
 	}
 
 	builderBackend, err := NewBuilder(builderArgs)
@@ -321,6 +347,14 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 			Authenticated: true,
 		},
 	})
+	if localRelay != nil {
+		stack.RegisterAPIs([]rpc.API{
+			{
+				Namespace: "admin",
+				Service:   NewLocalRelayAdminAPI(localRelay),
+			},
+		})
+	}
 
 	stack.RegisterLifecycle(builderService)
 
@@ -0,0 +1,372 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// RemoteSignerTimeoutDefault bounds a signing request to a remote signer. Signing is
+	// on the path of every block submission, a signer slower than that is unusable.
+	RemoteSignerTimeoutDefault = 2 * time.Second
+
+	remoteSignerSignPath       = "/builder/v1/sign/"
+	remoteSignerPublicKeysPath = "/builder/v1/publicKeys"
+)
+
+var (
+	ErrKeystoreChecksum     = errors.New("keystore checksum mismatch, wrong password?")
+	ErrRemoteSignerKey      = errors.New("remote signer does not hold the key")
+	ErrRemoteSignerResponse = errors.New("invalid remote signer signature")
+)
+
+// Signer signs the messages of the builder API, such as the bids submitted to the relays
+// and the bids of the local relay, with a BLS key.
+type Signer interface {
+	// PublicKey returns the public key of the signing key.
+	PublicKey() phase0.BLSPubKey
+	// Sign signs the message in the given domain.
+	Sign(msg ssz.ObjWithHashTreeRoot, domain phase0.Domain) (phase0.BLSSignature, error)
+}
+
+// LocalSigner signs messages with a secret key held in memory.
+type LocalSigner struct {
+	sk *bls.SecretKey
+	pk phase0.BLSPubKey
+}
+
+func NewLocalSigner(sk *bls.SecretKey) (*LocalSigner, error) {
+	blsPk, err := bls.PublicKeyFromSecretKey(sk)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := utils.BlsPublicKeyToPublicKey(blsPk)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSigner{sk: sk, pk: pk}, nil
+}
+
+// NewLocalSignerFromHex creates a signer from a hex encoded secret key.
+func NewLocalSignerFromHex(skHex string) (*LocalSigner, error) {
+	skBytes, err := hexutil.Decode(skHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	sk, err := bls.SecretKeyFromBytes(skBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	return NewLocalSigner(sk)
+}
+
+func (s *LocalSigner) PublicKey() phase0.BLSPubKey {
+	return s.pk
+}
+
+func (s *LocalSigner) Sign(msg ssz.ObjWithHashTreeRoot, domain phase0.Domain) (phase0.BLSSignature, error) {
+	return ssz.SignMessage(msg, domain, s.sk)
+}
+
+// keystoreModule is a function with its parameters and output in an EIP-2335 keystore.
+type keystoreModule struct {
+	Function string          `json:"function"`
+	Params   json.RawMessage `json:"params"`
+	Message  hexutil.Bytes   `json:"message"`
+}
+
+// keystoreJSON is an EIP-2335 BLS keystore.
+type keystoreJSON struct {
+	Crypto struct {
+		Kdf      keystoreModule `json:"kdf"`
+		Checksum keystoreModule `json:"checksum"`
+		Cipher   keystoreModule `json:"cipher"`
+	} `json:"crypto"`
+	Pubkey  string `json:"pubkey"`
+	Version int    `json:"version"`
+}
+
+type scryptParams struct {
+	Dklen int           `json:"dklen"`
+	N     int           `json:"n"`
+	P     int           `json:"p"`
+	R     int           `json:"r"`
+	Salt  hexutil.Bytes `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	Dklen int           `json:"dklen"`
+	C     int           `json:"c"`
+	Prf   string        `json:"prf"`
+	Salt  hexutil.Bytes `json:"salt"`
+}
+
+type aesParams struct {
+	IV hexutil.Bytes `json:"iv"`
+}
+
+// keystorePassword processes a password the way EIP-2335 requires: NFKD normalised, with
+// the control codes removed.
+func keystorePassword(password string) []byte {
+	password = norm.NFKD.String(password)
+	return []byte(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, password))
+}
+
+// DecryptKeystore decrypts the secret key of an EIP-2335 keystore.
+func DecryptKeystore(keystore []byte, password string) (*bls.SecretKey, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(keystore, &ks); err != nil {
+		return nil, fmt.Errorf("invalid keystore: %w", err)
+	}
+	if ks.Version != 4 {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+
+	var (
+		kdf = ks.Crypto.Kdf
+		dk  []byte
+		err error
+	)
+	switch kdf.Function {
+	case "scrypt":
+		var params scryptParams
+		if err := json.Unmarshal(kdf.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid scrypt params: %w", err)
+		}
+		dk, err = scrypt.Key(keystorePassword(password), params.Salt, params.N, params.R, params.P, params.Dklen)
+		if err != nil {
+			return nil, err
+		}
+	case "pbkdf2":
+		var params pbkdf2Params
+		if err := json.Unmarshal(kdf.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid pbkdf2 params: %w", err)
+		}
+		if params.Prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf %s", params.Prf)
+		}
+		dk = pbkdf2.Key(keystorePassword(password), params.Salt, params.C, params.Dklen, sha256.New)
+	default:
+		return nil, fmt.Errorf("unsupported keystore kdf %s", kdf.Function)
+	}
+	if len(dk) < 32 {
+		return nil, fmt.Errorf("keystore derived key too short: %d bytes", len(dk))
+	}
+
+	if ks.Crypto.Checksum.Function != "sha256" {
+		return nil, fmt.Errorf("unsupported keystore checksum %s", ks.Crypto.Checksum.Function)
+	}
+	checksum := sha256.Sum256(append(dk[16:32:32], ks.Crypto.Cipher.Message...))
+	if !bytes.Equal(checksum[:], ks.Crypto.Checksum.Message) {
+		return nil, ErrKeystoreChecksum
+	}
+
+	if ks.Crypto.Cipher.Function != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported keystore cipher %s", ks.Crypto.Cipher.Function)
+	}
+	var params aesParams
+	if err := json.Unmarshal(ks.Crypto.Cipher.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid cipher params: %w", err)
+	}
+	block, err := aes.NewCipher(dk[:16])
+	if err != nil {
+		return nil, err
+	}
+	if len(params.IV) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid cipher iv length %d", len(params.IV))
+	}
+	secret := make([]byte, len(ks.Crypto.Cipher.Message))
+	cipher.NewCTR(block, params.IV).XORKeyStream(secret, ks.Crypto.Cipher.Message)
+
+	sk, err := bls.SecretKeyFromBytes(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore secret key: %w", err)
+	}
+	if ks.Pubkey != "" {
+		pk, err := bls.PublicKeyFromSecretKey(sk)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(strings.TrimPrefix(ks.Pubkey, "0x"), hexutil.Encode(bls.PublicKeyToBytes(pk))[2:]) {
+			return nil, fmt.Errorf("keystore public key %s does not match its secret key", ks.Pubkey)
+		}
+	}
+	return sk, nil
+}
+
+// NewKeystoreSigner creates a signer from an EIP-2335 keystore, decrypted with the password
+// stored in the given file. Trailing newlines of the password file are ignored.
+func NewKeystoreSigner(keystorePath, passwordPath string) (*LocalSigner, error) {
+	keystore, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read keystore: %w", err)
+	}
+	password, err := os.ReadFile(passwordPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read keystore password: %w", err)
+	}
+	sk, err := DecryptKeystore(keystore, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalSigner(sk)
+}
+
+// RemoteSigner signs messages with a key held by a remote signer implementing the builder
+// signing API, and verifies the returned signatures before use. The API has two JSON
+// endpoints relative to the URL of the signer:
+//
+//	GET  /builder/v1/publicKeys       the hex encoded public keys held by the signer
+//	POST /builder/v1/sign/<pubkey>    {"signingRoot": "0x..."} -> {"signature": "0x..."}
+//
+// The signer signs the signing root as is, which commits to the builder API domain of the
+// message. It cannot check what it signs, so its keys must not be used for anything else.
+// Web3Signer has no endpoint signing arbitrary roots and does not implement this API.
+type RemoteSigner struct {
+	endpoint string
+	pk       phase0.BLSPubKey
+	client   http.Client
+}
+
+type remoteSignRequest struct {
+	SigningRoot phase0.Root `json:"signingRoot"`
+}
+
+type remoteSignResponse struct {
+	Signature phase0.BLSSignature `json:"signature"`
+}
+
+// NewRemoteSigner creates a signer for the given key of the remote signer at the endpoint.
+// If no public key is given, the signer must hold exactly one key.
+func NewRemoteSigner(endpoint, pubkeyHex string, timeout time.Duration) (*RemoteSigner, error) {
+	if timeout == 0 {
+		timeout = RemoteSignerTimeoutDefault
+	}
+	s := &RemoteSigner{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		client:   http.Client{Timeout: timeout},
+	}
+
+	keys, err := s.publicKeys()
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch remote signer keys: %w", err)
+	}
+	if pubkeyHex == "" {
+		if len(keys) != 1 {
+			return nil, fmt.Errorf("remote signer holds %d keys, the public key to sign with must be set", len(keys))
+		}
+		s.pk = keys[0]
+		return s, nil
+	}
+	pk, err := utils.HexToPubkey(pubkeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signer public key: %w", err)
+	}
+	for _, key := range keys {
+		if key == pk {
+			s.pk = pk
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRemoteSignerKey, pubkeyHex)
+}
+
+func (s *RemoteSigner) publicKeys() ([]phase0.BLSPubKey, error) {
+	resp, err := s.client.Get(s.endpoint + remoteSignerPublicKeysPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var keys []phase0.BLSPubKey
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *RemoteSigner) PublicKey() phase0.BLSPubKey {
+	return s.pk
+}
+
+func (s *RemoteSigner) Sign(msg ssz.ObjWithHashTreeRoot, domain phase0.Domain) (phase0.BLSSignature, error) {
+	root, err := ssz.ComputeSigningRoot(msg, domain)
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+	body, err := json.Marshal(remoteSignRequest{SigningRoot: phase0.Root(root)})
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+remoteSignerSignPath+s.pk.String(), bytes.NewReader(body))
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return phase0.BLSSignature{}, fmt.Errorf("remote signer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return phase0.BLSSignature{}, fmt.Errorf("remote signer returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var result remoteSignResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return phase0.BLSSignature{}, fmt.Errorf("%w: %v", ErrRemoteSignerResponse, err)
+	}
+	signature := result.Signature
+	if ok, err := bls.VerifySignatureBytes(root[:], signature[:], s.pk[:]); err != nil || !ok {
+		return phase0.BLSSignature{}, ErrRemoteSignerResponse
+	}
+	return signature, nil
+}
+
+// NewSigner creates the signer of the given key config.
+func NewSigner(cfg SignerConfig) (Signer, error) {
+	switch {
+	case cfg.RemoteSigner != "":
+		return NewRemoteSigner(cfg.RemoteSigner, cfg.RemoteSignerPubkey, RemoteSignerTimeoutDefault)
+	case cfg.Keystore != "":
+		return NewKeystoreSigner(cfg.Keystore, cfg.KeystorePasswordFile)
+	default:
+		return NewLocalSignerFromHex(cfg.SecretKey)
+	}
+}
@@ -0,0 +1,224 @@
+package builder
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/gorilla/mux"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptTestKeystore encrypts the secret key into an EIP-2335 keystore, with cheap kdf
+// parameters.
+func encryptTestKeystore(t *testing.T, sk *bls.SecretKey, password, kdf string) []byte {
+	salt, iv := make([]byte, 32), make([]byte, 16)
+	rand.Read(salt)
+	rand.Read(iv)
+
+	var (
+		dk     []byte
+		params any
+		err    error
+	)
+	switch kdf {
+	case "scrypt":
+		dk, err = scrypt.Key(keystorePassword(password), salt, 1024, 8, 1, 32)
+		require.NoError(t, err)
+		params = scryptParams{Dklen: 32, N: 1024, R: 8, P: 1, Salt: salt}
+	case "pbkdf2":
+		dk = pbkdf2.Key(keystorePassword(password), salt, 1000, 32, sha256.New)
+		params = pbkdf2Params{Dklen: 32, C: 1000, Prf: "hmac-sha256", Salt: salt}
+	}
+
+	block, err := aes.NewCipher(dk[:16])
+	require.NoError(t, err)
+	secret := bls.SecretKeyToBytes(sk)
+	ciphertext := make([]byte, len(secret))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, secret)
+	checksum := sha256.Sum256(append(dk[16:32:32], ciphertext...))
+
+	pk, err := bls.PublicKeyFromSecretKey(sk)
+	require.NoError(t, err)
+	raw := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+	var ks keystoreJSON
+	ks.Crypto.Kdf = keystoreModule{Function: kdf, Params: raw(params)}
+	ks.Crypto.Checksum = keystoreModule{Function: "sha256", Params: raw(struct{}{}), Message: checksum[:]}
+	ks.Crypto.Cipher = keystoreModule{Function: "aes-128-ctr", Params: raw(aesParams{IV: iv}), Message: ciphertext}
+	ks.Pubkey = hexutil.Encode(bls.PublicKeyToBytes(pk))[2:]
+	ks.Version = 4
+	return raw(ks)
+}
+
+func testBidTrace() *builderApiV1.BidTrace {
+	return &builderApiV1.BidTrace{
+		Slot:  42,
+		Value: uint256.NewInt(1000),
+	}
+}
+
+func requireValidSignature(t *testing.T, signer Signer, domain phase0.Domain) {
+	msg := testBidTrace()
+	signature, err := signer.Sign(msg, domain)
+	require.NoError(t, err)
+	pk := signer.PublicKey()
+	ok, err := ssz.VerifySignature(msg, domain, pk[:], signature[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	sk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	expected, err := NewLocalSigner(sk)
+	require.NoError(t, err)
+	domain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{})
+
+	for _, kdf := range []string{"scrypt", "pbkdf2"} {
+		t.Run(kdf, func(t *testing.T) {
+			// Passwords are normalised and stripped of control codes before use.
+			keystore := encryptTestKeystore(t, sk, "pass\u0000wordÅ", kdf)
+			dir := t.TempDir()
+			keystorePath, passwordPath := filepath.Join(dir, "keystore.json"), filepath.Join(dir, "password.txt")
+			require.NoError(t, os.WriteFile(keystorePath, keystore, 0o600))
+			require.NoError(t, os.WriteFile(passwordPath, []byte("passwordÅ\n"), 0o600))
+
+			signer, err := NewKeystoreSigner(keystorePath, passwordPath)
+			require.NoError(t, err)
+			require.Equal(t, expected.PublicKey(), signer.PublicKey())
+			requireValidSignature(t, signer, domain)
+
+			_, err = DecryptKeystore(keystore, "wrong password")
+			require.ErrorIs(t, err, ErrKeystoreChecksum)
+		})
+	}
+}
+
+// testRemoteSigner is a stand-in for a remote signer holding the given keys.
+type testRemoteSigner struct {
+	keys     map[phase0.BLSPubKey]*bls.SecretKey
+	corrupt  bool // answer signing requests with invalid signatures
+	requests int
+}
+
+func newTestRemoteSigner(t *testing.T, keys ...*bls.SecretKey) (*testRemoteSigner, *httptest.Server) {
+	s := &testRemoteSigner{keys: make(map[phase0.BLSPubKey]*bls.SecretKey)}
+	for _, sk := range keys {
+		signer, err := NewLocalSigner(sk)
+		require.NoError(t, err)
+		s.keys[signer.PublicKey()] = sk
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc(remoteSignerPublicKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		keys := make([]phase0.BLSPubKey, 0, len(s.keys))
+		for pk := range s.keys {
+			keys = append(keys, pk)
+		}
+		json.NewEncoder(w).Encode(keys)
+	}).Methods(http.MethodGet)
+	router.HandleFunc(remoteSignerSignPath+"{pubkey}", func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		var pk phase0.BLSPubKey
+		if err := pk.UnmarshalJSON([]byte(fmt.Sprintf("%q", mux.Vars(r)["pubkey"]))); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sk, ok := s.keys[pk]
+		if !ok {
+			http.Error(w, "key not found", http.StatusNotFound)
+			return
+		}
+		var req remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if s.corrupt {
+			req.SigningRoot[0] ^= 0xff
+		}
+		signature := hexutil.Encode(bls.SignatureToBytes(bls.Sign(sk, req.SigningRoot[:])))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"signature": signature})
+	}).Methods(http.MethodPost)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func TestRemoteSigner(t *testing.T) {
+	sk1, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	sk2, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	local1, err := NewLocalSigner(sk1)
+	require.NoError(t, err)
+	local2, err := NewLocalSigner(sk2)
+	require.NoError(t, err)
+	domain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{})
+
+	single, singleServer := newTestRemoteSigner(t, sk1)
+	signer, err := NewRemoteSigner(singleServer.URL, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, local1.PublicKey(), signer.PublicKey())
+	requireValidSignature(t, signer, domain)
+	require.Equal(t, 1, single.requests)
+
+	single.corrupt = true
+	_, err = signer.Sign(testBidTrace(), domain)
+	require.ErrorIs(t, err, ErrRemoteSignerResponse)
+
+	// The key to sign with must be chosen when the signer holds several.
+	_, multiServer := newTestRemoteSigner(t, sk1, sk2)
+	_, err = NewRemoteSigner(multiServer.URL, "", 0)
+	require.Error(t, err)
+	signer, err = NewRemoteSigner(multiServer.URL+"/", local2.PublicKey().String(), 0)
+	require.NoError(t, err)
+	require.Equal(t, local2.PublicKey(), signer.PublicKey())
+	requireValidSignature(t, signer, domain)
+
+	_, err = NewRemoteSigner(singleServer.URL, local2.PublicKey().String(), 0)
+	require.ErrorIs(t, err, ErrRemoteSignerKey)
+}
+
+func TestNewSigner(t *testing.T) {
+	sk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	local, err := NewLocalSigner(sk)
+	require.NoError(t, err)
+	_, server := newTestRemoteSigner(t, sk)
+
+	signer, err := NewSigner(SignerConfig{SecretKey: DefaultConfig.BuilderSecretKey, RemoteSigner: server.URL})
+	require.NoError(t, err)
+	require.IsType(t, &RemoteSigner{}, signer)
+	require.Equal(t, local.PublicKey(), signer.PublicKey())
+
+	signer, err = NewSigner(SignerConfig{SecretKey: hexutil.Encode(bls.SecretKeyToBytes(sk))})
+	require.NoError(t, err)
+	require.Equal(t, local.PublicKey(), signer.PublicKey())
+
+	_, err = NewSigner(SignerConfig{Keystore: filepath.Join(t.TempDir(), "missing.json")})
+	require.True(t, strings.Contains(err.Error(), "could not read keystore"))
+}
@@ -0,0 +1,366 @@
+package builder
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/flashbotsextra"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+const (
+	simulatedSecondsPerSlot  = 12
+	simulatedRegisteredSlots = 64
+	simulatedGasLimit        = 30_000_000
+)
+
+var (
+	simulatedBuilderTxKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	simulatedBuilderAddr     = crypto.PubkeyToAddress(simulatedBuilderTxKey.PublicKey)
+	simulatedUserKey, _      = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	simulatedUserAddr        = crypto.PubkeyToAddress(simulatedUserKey.PublicKey)
+
+	simulatedProposer = ValidatorData{
+		Pubkey:       "0xb67d2c11bcab8c4394fc2faa9601d0b99c7f4b37e14911101da7d97077917862eed4563203d34b91b5cf0aa44d6cfa05",
+		FeeRecipient: [20]byte{0xfe, 0xe0},
+		GasLimit:     simulatedGasLimit,
+	}
+)
+
+type simulatedBuilderConfig struct {
+	relays           []string      // names of the simulated relays, the first one is the primary relay
+	slotDuration     time.Duration // wall clock time the builder is given every slot
+	resubmitInterval time.Duration
+}
+
+// simulatedBuilder runs a builder against an in-process chain driven by a simulated
+// beacon node and submitting to simulated relays. Every slot the builder is handed the
+// payload attributes of the next slot, and the most valuable bid received by the relays
+// becomes the next block. Slot timestamps are derived from the slot number, so the
+// transcript of a scenario only depends on the order flow and not on the wall clock.
+type simulatedBuilder struct {
+	t       *testing.T
+	config  simulatedBuilderConfig
+	node    *node.Node
+	eth     *eth.Ethereum
+	beacon  *catalyst.SimulatedBeacon
+	builder *Builder
+	relays  []*simulatedRelay
+
+	slot       uint64
+	transcript []string
+}
+
+func newSimulatedBuilder(t *testing.T, config simulatedBuilderConfig) *simulatedBuilder {
+	t.Helper()
+
+	if len(config.relays) == 0 {
+		config.relays = []string{"relay"}
+	}
+	if config.slotDuration == 0 {
+		config.slotDuration = time.Second
+	}
+	if config.resubmitInterval == 0 {
+		config.resubmitInterval = 100 * time.Millisecond
+	}
+	s := &simulatedBuilder{t: t, config: config}
+
+	registrations := make(map[uint64]ValidatorData, simulatedRegisteredSlots)
+	for slot := uint64(1); slot <= simulatedRegisteredSlots; slot++ {
+		registrations[slot] = simulatedProposer
+	}
+	for _, name := range config.relays {
+		relay := newSimulatedRelay(name, registrations)
+		t.Cleanup(relay.close)
+		s.relays = append(s.relays, relay)
+	}
+
+	s.startNode()
+	s.startBuilder()
+	return s
+}
+
+func (s *simulatedBuilder) startNode() {
+	n, err := node.New(&node.Config{
+		P2P: p2p.Config{
+			ListenAddr:  "127.0.0.1:0",
+			NoDiscovery: true,
+			MaxPeers:    0,
+		},
+	})
+	require.NoError(s.t, err)
+	s.t.Cleanup(func() { n.Close() })
+
+	genesis := &core.Genesis{
+		Config:     params.AllDevChainProtocolChanges,
+		GasLimit:   simulatedGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(0),
+		Alloc: types.GenesisAlloc{
+			simulatedBuilderAddr: {Balance: big.NewInt(params.Ether)},
+			simulatedUserAddr:    {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))},
+		},
+	}
+	ethcfg := ethconfig.Defaults
+	ethcfg.Genesis = genesis
+	ethcfg.SyncMode = downloader.FullSync
+	ethcfg.Miner.Etherbase = simulatedBuilderAddr
+	ethcfg.Miner.BuilderTxSigningKey = simulatedBuilderTxKey
+	ethcfg.Miner.AlgoType = miner.ALGO_GREEDY
+	ethservice, err := eth.New(n, &ethcfg)
+	require.NoError(s.t, err)
+
+	beacon, err := catalyst.NewSimulatedBeacon(0, ethservice)
+	require.NoError(s.t, err)
+	n.RegisterLifecycle(beacon)
+
+	require.NoError(s.t, n.Start())
+	ethservice.SetSynced()
+
+	s.node, s.eth, s.beacon = n, ethservice, beacon
+}
+
+func (s *simulatedBuilder) startBuilder() {
+	var relay IRelay = NewRemoteRelay(RelayConfig{Endpoint: s.relays[0].endpoint()}, nil, false)
+	if len(s.relays) > 1 {
+		secondary := make([]IRelay, 0, len(s.relays)-1)
+		for _, r := range s.relays[1:] {
+			secondary = append(secondary, NewRemoteRelay(RelayConfig{Endpoint: r.endpoint()}, nil, false))
+		}
+		relay = NewRemoteRelayAggregator(relay, secondary)
+	}
+
+	signer, err := NewLocalSignerFromHex(DefaultConfig.BuilderSecretKey)
+	require.NoError(s.t, err)
+	b, err := NewBuilder(BuilderArgs{
+		signer:                       signer,
+		ds:                           flashbotsextra.NilDbService{},
+		blockConsumer:                flashbotsextra.NilDbService{},
+		relay:                        relay,
+		builderSigningDomain:         ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{}),
+		builderBlockResubmitInterval: s.config.resubmitInterval,
+		eth:                          NewEthereumService(s.eth),
+		beaconClient:                 &NilBeaconClient{},
+		limiter:                      rate.NewLimiter(rate.Inf, 0),
+	})
+	require.NoError(s.t, err)
+	s.t.Cleanup(func() { b.Stop() })
+	s.builder = b
+}
+
+func (s *simulatedBuilder) logf(format string, args ...interface{}) {
+	s.transcript = append(s.transcript, fmt.Sprintf("slot %d: ", s.slot)+fmt.Sprintf(format, args...))
+}
+
+// sendTx adds a transfer from the funded user account to the txpool.
+func (s *simulatedBuilder) sendTx(nonce uint64, tip int64) *types.Transaction {
+	signer := types.LatestSigner(s.eth.BlockChain().Config())
+	tx := types.MustSignNewTx(simulatedUserKey, signer, &types.DynamicFeeTx{
+		ChainID:   s.eth.BlockChain().Config().ChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(10 * params.InitialBaseFee),
+		Gas:       params.TxGas,
+		To:        &common.Address{0x01},
+		Value:     big.NewInt(1),
+	})
+	require.NoError(s.t, s.eth.APIBackend.SendTx(context.Background(), tx, false))
+	require.NoError(s.t, s.eth.TxPool().Sync())
+	return tx
+}
+
+// startSlot advances to the next slot and hands the payload attributes building on the
+// current head to the builder, which cancels the job of the previous slot.
+func (s *simulatedBuilder) startSlot() {
+	s.slot++
+	head := s.eth.BlockChain().CurrentBlock()
+	s.logf("head %d %s", head.Number.Uint64(), head.Hash())
+
+	attrs := &types.BuilderPayloadAttributes{
+		Timestamp:   hexutil.Uint64(s.slot * simulatedSecondsPerSlot),
+		Random:      crypto.Keccak256Hash(binary.BigEndian.AppendUint64(nil, s.slot)),
+		Withdrawals: types.Withdrawals{},
+		Slot:        s.slot,
+		HeadHash:    head.Hash(),
+	}
+	if err := s.builder.OnPayloadAttribute(attrs); err != nil {
+		s.logf("payload attributes rejected: %v", err)
+	}
+}
+
+// endSlot picks the most valuable bid received by the relays for the current slot and
+// imports it as the next block. It returns nil if the slot was missed.
+func (s *simulatedBuilder) endSlot() *types.Block {
+	var (
+		winner      *relaySubmission
+		winnerRelay string
+	)
+	for _, relay := range s.relays {
+		bid := relay.bestBid(s.slot)
+		if bid == nil {
+			s.logf("%s: no bid", relay.name)
+			continue
+		}
+		s.logf("%s: bid block %d %s value %s gas used %d", relay.name, bid.request.Capella.ExecutionPayload.BlockNumber, common.Hash(bid.bid.BlockHash), bid.bid.Value.Dec(), bid.bid.GasUsed)
+		if winner == nil || bid.bid.Value.Cmp(winner.bid.Value) > 0 {
+			winner, winnerRelay = bid, relay.name
+		}
+	}
+	if winner == nil {
+		s.logf("missed")
+		return nil
+	}
+
+	block, err := submissionToBlock(winner.request)
+	require.NoError(s.t, err)
+	require.NoError(s.t, s.beacon.InsertPayload(engine.BlockToExecutableData(block, nil, nil).ExecutionPayload))
+	require.NoError(s.t, s.eth.TxPool().Sync())
+	s.logf("delivered block %d %s from %s with %d txs", block.NumberU64(), block.Hash(), winnerRelay, len(block.Transactions()))
+	return block
+}
+
+// runSlot gives the builder one slot to submit and delivers the winning bid.
+func (s *simulatedBuilder) runSlot() *types.Block {
+	s.startSlot()
+	time.Sleep(s.config.slotDuration)
+	return s.endSlot()
+}
+
+func (s *simulatedBuilder) transcriptString() string {
+	return strings.Join(s.transcript, "\n")
+}
+
+func submissionToBlock(request *builderSpec.VersionedSubmitBlockRequest) (*types.Block, error) {
+	if request.Version != spec.DataVersionCapella {
+		return nil, fmt.Errorf("unsupported submission version %s", request.Version)
+	}
+	return engine.ExecutionPayloadV2ToBlock(request.Capella.ExecutionPayload)
+}
+
+func blockHasTx(block *types.Block, hash common.Hash) bool {
+	return block != nil && block.Transaction(hash) != nil
+}
+
+func TestSimulatedBuilderTranscript(t *testing.T) {
+	run := func() string {
+		s := newSimulatedBuilder(t, simulatedBuilderConfig{})
+
+		tx0, tx1 := s.sendTx(0, params.GWei), s.sendTx(1, params.GWei)
+		block := s.runSlot()
+		require.True(t, blockHasTx(block, tx0.Hash()))
+		require.True(t, blockHasTx(block, tx1.Hash()))
+		// The proposer is paid by the last transaction of the block.
+		require.Equal(t, common.Address(simulatedProposer.FeeRecipient), *block.Transactions()[2].To())
+
+		// Without any order flow the empty block is delivered.
+		block = s.runSlot()
+		require.NotNil(t, block)
+		require.Empty(t, block.Transactions())
+
+		tx2 := s.sendTx(2, 2*params.GWei)
+		require.True(t, blockHasTx(s.runSlot(), tx2.Hash()))
+		return s.transcriptString()
+	}
+
+	first := run()
+	require.Contains(t, first, "slot 3: delivered block 3")
+	require.Equal(t, first, run())
+}
+
+func TestSimulatedBuilderResubmit(t *testing.T) {
+	s := newSimulatedBuilder(t, simulatedBuilderConfig{})
+	relay := s.relays[0]
+
+	s.sendTx(0, params.GWei)
+	s.startSlot()
+	require.Eventually(t, func() bool {
+		bid := relay.bestBid(s.slot)
+		return bid != nil && len(bid.request.Capella.ExecutionPayload.Transactions) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	first := relay.bestBid(s.slot)
+
+	// A more valuable transaction arriving mid slot is picked up by the next rebuild.
+	tx := s.sendTx(1, 5*params.GWei)
+	require.Eventually(t, func() bool {
+		return relay.bestBid(s.slot).bid.Value.Cmp(first.bid.Value) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	block := s.endSlot()
+	require.True(t, blockHasTx(block, tx.Hash()))
+	require.Len(t, block.Transactions(), 3)
+}
+
+func TestSimulatedBuilderRelayFailover(t *testing.T) {
+	s := newSimulatedBuilder(t, simulatedBuilderConfig{relays: []string{"primary", "secondary"}})
+	primary, secondary := s.relays[0], s.relays[1]
+
+	// The primary relay rejects every submission, the block is delivered by the secondary.
+	primary.setSubmitStatus(http.StatusInternalServerError)
+	tx := s.sendTx(0, params.GWei)
+	block := s.runSlot()
+	require.True(t, blockHasTx(block, tx.Hash()))
+	require.NotEmpty(t, primary.slotSubmissions(1))
+	require.Nil(t, primary.bestBid(1))
+	require.Equal(t, block.Hash(), common.Hash(secondary.bestBid(1).bid.BlockHash))
+	require.Contains(t, s.transcriptString(), "slot 1: primary: no bid")
+	require.Contains(t, s.transcriptString(), "from secondary")
+
+	// Once the primary relay recovers both relays receive the bids again.
+	primary.setSubmitStatus(http.StatusOK)
+	tx = s.sendTx(1, params.GWei)
+	block = s.runSlot()
+	require.True(t, blockHasTx(block, tx.Hash()))
+	require.Equal(t, block.Hash(), common.Hash(primary.bestBid(2).bid.BlockHash))
+	require.Equal(t, block.Hash(), common.Hash(secondary.bestBid(2).bid.BlockHash))
+}
+
+func TestSimulatedBuilderCancellation(t *testing.T) {
+	s := newSimulatedBuilder(t, simulatedBuilderConfig{})
+	relay := s.relays[0]
+
+	s.sendTx(0, params.GWei)
+	s.startSlot()
+	require.Eventually(t, func() bool { return relay.bestBid(1) != nil }, 5*time.Second, 10*time.Millisecond)
+
+	// Slot 1 is missed, the attributes of slot 2 cancel its building job.
+	s.startSlot()
+	tx := s.sendTx(1, params.GWei)
+	require.Eventually(t, func() bool {
+		bid := relay.bestBid(2)
+		return bid != nil && len(bid.request.Capella.ExecutionPayload.Transactions) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, submission := range relay.slotSubmissions(1) {
+		block, err := submissionToBlock(submission.request)
+		require.NoError(t, err)
+		require.False(t, blockHasTx(block, tx.Hash()), "slot 1 job still running")
+	}
+	block := s.endSlot()
+	require.Equal(t, uint64(1), block.NumberU64())
+	require.True(t, blockHasTx(block, tx.Hash()))
+}
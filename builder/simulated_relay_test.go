@@ -0,0 +1,202 @@
+package builder
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/gorilla/mux"
+)
+
+// relaySubmission is a block submission received by the simulated relay.
+type relaySubmission struct {
+	receivedAt time.Time
+	status     int // status code the relay answered with
+	bid        *builderApiV1.BidTrace
+	request    *builderSpec.VersionedSubmitBlockRequest
+}
+
+// simulatedRelay is an in-process relay that serves the validator registrations of the
+// builder API, records the block submissions and fails them on demand.
+type simulatedRelay struct {
+	name   string
+	server *httptest.Server
+
+	mu               sync.Mutex
+	registrations    map[uint64]ValidatorData
+	validatorsStatus int // status code returned for the validators request, 200 if zero
+	submitStatus     int // status code returned for block submissions, 200 if zero
+	submissions      []*relaySubmission
+}
+
+func newSimulatedRelay(name string, registrations map[uint64]ValidatorData) *simulatedRelay {
+	r := &simulatedRelay{
+		name:          name,
+		registrations: registrations,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/relay/v1/builder/validators", r.handleValidators).Methods(http.MethodGet)
+	router.HandleFunc("/relay/v1/builder/blocks", r.handleSubmitBlock).Methods(http.MethodPost)
+	r.server = httptest.NewServer(router)
+	return r
+}
+
+func (r *simulatedRelay) endpoint() string { return r.server.URL }
+
+func (r *simulatedRelay) close() { r.server.Close() }
+
+// setSubmitStatus makes the relay answer all following block submissions with the given
+// status code. Submissions answered with an error status are recorded but never win.
+func (r *simulatedRelay) setSubmitStatus(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.submitStatus = code
+}
+
+// setValidatorsStatus makes the relay answer all following validators requests with the
+// given status code.
+func (r *simulatedRelay) setValidatorsStatus(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validatorsStatus = code
+}
+
+func (r *simulatedRelay) handleValidators(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	status := r.validatorsStatus
+	slots := make([]uint64, 0, len(r.registrations))
+	for slot := range r.registrations {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+	resp := make(GetValidatorRelayResponse, len(slots))
+	for i, slot := range slots {
+		vd := r.registrations[slot]
+		resp[i].Slot = slot
+		resp[i].Entry.Message.FeeRecipient = vd.FeeRecipient.String()
+		resp[i].Entry.Message.GasLimit = vd.GasLimit
+		resp[i].Entry.Message.Pubkey = string(vd.Pubkey)
+	}
+	r.mu.Unlock()
+
+	if status != 0 && status != http.StatusOK {
+		http.Error(w, "simulated failure", status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (r *simulatedRelay) handleSubmitBlock(w http.ResponseWriter, req *http.Request) {
+	submission := &relaySubmission{receivedAt: time.Now()}
+	request, err := decodeSubmitBlockRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	submission.request = request
+	if submission.bid, err = request.BidTrace(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	submission.status = http.StatusOK
+	if r.submitStatus != 0 {
+		submission.status = r.submitStatus
+	}
+	r.submissions = append(r.submissions, submission)
+	r.mu.Unlock()
+
+	if submission.status != http.StatusOK {
+		http.Error(w, "simulated failure", submission.status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// decodeSubmitBlockRequest decodes a json or ssz encoded block submission.
+func decodeSubmitBlockRequest(req *http.Request) (*builderSpec.VersionedSubmitBlockRequest, error) {
+	body := req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	deneb, capella := new(builderApiDeneb.SubmitBlockRequest), new(builderApiCapella.SubmitBlockRequest)
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/octet-stream") {
+		if err := deneb.UnmarshalSSZ(data); err == nil {
+			return &builderSpec.VersionedSubmitBlockRequest{Version: spec.DataVersionDeneb, Deneb: deneb}, nil
+		}
+		if err := capella.UnmarshalSSZ(data); err != nil {
+			return nil, fmt.Errorf("invalid ssz submission: %w", err)
+		}
+		return &builderSpec.VersionedSubmitBlockRequest{Version: spec.DataVersionCapella, Capella: capella}, nil
+	}
+
+	// Only deneb submissions carry a blobs bundle.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["blobs_bundle"]; ok {
+		if err := json.Unmarshal(data, deneb); err != nil {
+			return nil, err
+		}
+		return &builderSpec.VersionedSubmitBlockRequest{Version: spec.DataVersionDeneb, Deneb: deneb}, nil
+	}
+	if err := json.Unmarshal(data, capella); err != nil {
+		return nil, err
+	}
+	return &builderSpec.VersionedSubmitBlockRequest{Version: spec.DataVersionCapella, Capella: capella}, nil
+}
+
+// slotSubmissions returns the submissions received for the given slot, in arrival order.
+func (r *simulatedRelay) slotSubmissions(slot uint64) []*relaySubmission {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var submissions []*relaySubmission
+	for _, s := range r.submissions {
+		if s.bid.Slot == slot {
+			submissions = append(submissions, s)
+		}
+	}
+	return submissions
+}
+
+// bestBid returns the most valuable accepted submission for the given slot. Equal bids
+// are resolved in favour of the earliest one, like a relay does.
+func (r *simulatedRelay) bestBid(slot uint64) *relaySubmission {
+	var best *relaySubmission
+	for _, s := range r.slotSubmissions(slot) {
+		if s.status != http.StatusOK {
+			continue
+		}
+		if best == nil || s.bid.Value.Cmp(best.bid.Value) > 0 {
+			best = s
+		}
+	}
+	return best
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// backtest replays the block building jobs recorded with --builder.record_dir through
+// the block building algorithms and reports the value, gas used and build time of each.
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+var app = flags.NewApp("block building algorithm backtest tool")
+
+var (
+	recordsFlag = &cli.StringFlag{
+		Name:     "records",
+		Usage:    "Directory with the slot records written by --builder.record_dir",
+		Required: true,
+	}
+	algosFlag = &cli.StringFlag{
+		Name:  "algos",
+		Usage: "Comma separated list of algorithms to compare",
+		Value: strings.Join([]string{
+			miner.ALGO_GREEDY.String(),
+			miner.ALGO_GREEDY_BUCKETS.String(),
+			miner.ALGO_GREEDY_MULTISNAP.String(),
+			miner.ALGO_GREEDY_BUCKETS_MULTISNAP.String(),
+		}, ","),
+	}
+	priceCutoffPercentFlag = &cli.IntFlag{
+		Name:  "price_cutoff_percent",
+		Usage: "Effective gas price cutoff used by the bucketing algorithms",
+		Value: miner.DefaultConfig.PriceCutoffPercent,
+	}
+	discardRevertibleTxOnErrFlag = &cli.BoolFlag{
+		Name:  "discard_revertible_tx_on_error",
+		Usage: "Discard revertible bundle transactions failing on commit",
+	}
+	verbosityFlag = &cli.IntFlag{
+		Name:  "verbosity",
+		Usage: "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value: 2,
+	}
+)
+
+func init() {
+	app.Action = backtest
+	app.Flags = flags.Merge([]cli.Flag{
+		recordsFlag,
+		algosFlag,
+		priceCutoffPercentFlag,
+		discardRevertibleTxOnErrFlag,
+		verbosityFlag,
+		utils.CacheFlag,
+		utils.CacheDatabaseFlag,
+		utils.CacheTrieFlag,
+		utils.CacheGCFlag,
+		utils.CacheNoPrefetchFlag,
+		utils.CachePreimagesFlag,
+		utils.FDLimitFlag,
+		utils.GCModeFlag,
+		utils.SnapshotFlag,
+		utils.StateHistoryFlag,
+		utils.SyncModeFlag,
+	}, utils.NetworkFlags, utils.DatabaseFlags)
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// algoTotals accumulates the results of one algorithm over all records.
+type algoTotals struct {
+	value     *big.Int
+	gasUsed   uint64
+	buildTime time.Duration
+	wins      int
+	failures  int
+}
+
+func backtest(ctx *cli.Context) error {
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.FromLegacyLevel(ctx.Int(verbosityFlag.Name)), false)))
+
+	var algos []miner.AlgoType
+	for _, name := range strings.Split(ctx.String(algosFlag.Name), ",") {
+		algo, err := miner.AlgoTypeFlagToEnum(strings.TrimSpace(name))
+		if err != nil {
+			return fmt.Errorf("invalid algorithm %q: %w", name, err)
+		}
+		algos = append(algos, algo)
+	}
+	paths, err := miner.ListSlotRecords(ctx.String(recordsFlag.Name))
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no slot records found in %s", ctx.String(recordsFlag.Name))
+	}
+
+	stack, err := node.New(&node.Config{Name: "geth", DataDir: utils.MakeDataDir(ctx)})
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	// The builder key only signs the proposer payment, any key will do for the comparison.
+	builderKey, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	config := miner.DefaultConfig
+	config.BuilderTxSigningKey = builderKey
+	config.PriceCutoffPercent = ctx.Int(priceCutoffPercentFlag.Name)
+	config.DiscardRevertibleTxOnErr = ctx.Bool(discardRevertibleTxOnErrFlag.Name)
+
+	totals := make(map[miner.AlgoType]*algoTotals, len(algos))
+	for _, algo := range algos {
+		totals[algo] = &algoTotals{value: new(big.Int)}
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(out, "BLOCK\tPARENT\tALGORITHM\tVALUE (ETH)\tGAS USED\tTXS\tBUNDLES\tSBUNDLES\tBUILD TIME")
+	for _, path := range paths {
+		record, err := miner.ReadSlotRecord(path)
+		if err != nil {
+			log.Error("Skipping slot record", "path", path, "err", err)
+			continue
+		}
+		var (
+			best     *big.Int
+			bestAlgo []miner.AlgoType
+		)
+		for _, algo := range algos {
+			result, err := miner.Backtest(chain, &config, record, algo)
+			if err != nil {
+				totals[algo].failures++
+				fmt.Fprintf(out, "%d\t%s\t%s\tfailed: %v\t\t\t\t\t\n", record.Parent.Number.Uint64()+1, record.Parent.Hash().TerminalString(), algo, err)
+				continue
+			}
+			totals[algo].value.Add(totals[algo].value, result.Value)
+			totals[algo].gasUsed += result.GasUsed
+			totals[algo].buildTime += result.BuildTime
+
+			switch {
+			case best == nil || result.Value.Cmp(best) > 0:
+				best, bestAlgo = result.Value, []miner.AlgoType{algo}
+			case result.Value.Cmp(best) == 0:
+				bestAlgo = append(bestAlgo, algo)
+			}
+			fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%v\n", result.Block.NumberU64(), record.Parent.Hash().TerminalString(), algo,
+				weiToEther(result.Value), result.GasUsed, result.Txs, result.Bundles, result.SBundles, result.BuildTime)
+		}
+		for _, algo := range bestAlgo {
+			totals[algo].wins++
+		}
+	}
+	out.Flush()
+
+	fmt.Printf("\nTotals over %d slots\n", len(paths))
+	out = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(out, "ALGORITHM\tVALUE (ETH)\tGAS USED\tAVG BUILD TIME\tBEST\tFAILED")
+	for _, algo := range algos {
+		t := totals[algo]
+		var avg time.Duration
+		if built := len(paths) - t.failures; built > 0 {
+			avg = t.buildTime / time.Duration(built)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%d\t%v\t%d\t%d\n", algo, weiToEther(t.value), t.gasUsed, avg, t.wins, t.failures)
+	}
+	return out.Flush()
+}
+
+func weiToEther(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether)).Text('f', 18)
+}
@@ -213,6 +213,14 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if ctx.IsSet(utils.BuilderBlockValidationExcludeWithdrawals.Name) {
 		bvConfig.ExcludeWithdrawals = ctx.Bool(utils.BuilderBlockValidationExcludeWithdrawals.Name)
 	}
+	if ctx.IsSet(utils.BuilderBlockValidationBatchWorkers.Name) {
+		bvConfig.BatchWorkers = ctx.Int(utils.BuilderBlockValidationBatchWorkers.Name)
+	}
+	if ctx.IsSet(utils.BuilderBlockValidationCacheSize.Name) {
+		bvConfig.CacheSize = ctx.Int(utils.BuilderBlockValidationCacheSize.Name)
+	}
+	bvConfig.GasLimitPolicy = ctx.String(utils.BuilderGasLimitPolicy.Name)
+	bvConfig.GasLimitPolicyFile = ctx.String(utils.BuilderGasLimitPolicyFile.Name)
 
 	if err := blockvalidationapi.Register(stack, eth, bvConfig); err != nil {
 		utils.Fatalf("Failed to register the Block Validation API: %v", err)
@@ -31,12 +31,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -79,6 +81,7 @@ Remove blockchain and state databases`,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
+			dbRebuildLogIndexCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -203,6 +206,18 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Shows metadata about the chain status.",
 	}
+	dbRebuildLogIndexCmd = &cli.Command{
+		Action: rebuildLogIndex,
+		Name:   "rebuild-logindex",
+		Usage:  "Rebuild the index of the log addresses and topics",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			utils.CacheFlag,
+			utils.CacheDatabaseFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command deletes the log index maintained with --history.logindex and
+indexes the logs of the canonical chain again, up to the confirmed head.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -759,3 +774,50 @@ func showMetaData(ctx *cli.Context) error {
 	table.Render()
 	return nil
 }
+
+func rebuildLogIndex(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+
+	log.Info("Deleting log index")
+	rawdb.DeleteLogIndex(db)
+
+	var (
+		head   = chain.CurrentBlock().Number.Uint64()
+		target uint64
+	)
+	if head+1 >= params.BloomConfirms {
+		target = (head + 1 - params.BloomConfirms) / params.BloomBitsBlocks
+	}
+	indexer := core.NewLogIndexer(db, params.BloomBitsBlocks, params.BloomConfirms)
+	defer indexer.Close()
+	indexer.Start(chain)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	var (
+		start  = time.Now()
+		ticker = time.NewTicker(8 * time.Second)
+	)
+	defer ticker.Stop()
+	for {
+		sections, _, _ := indexer.Sections()
+		if sections >= target {
+			log.Info("Rebuilt log index", "sections", sections, "blocks", sections*params.BloomBitsBlocks, "elapsed", common.PrettyDuration(time.Since(start)))
+			return nil
+		}
+		select {
+		case <-ticker.C:
+			log.Info("Rebuilding log index", "sections", sections, "target", target, "elapsed", common.PrettyDuration(time.Since(start)))
+		case <-time.After(100 * time.Millisecond):
+		case <-interrupt:
+			return fmt.Errorf("interrupted after %d of %d sections", sections, target)
+		}
+	}
+}
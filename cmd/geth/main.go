@@ -91,6 +91,7 @@ var (
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.StateHistoryFlag,
+		utils.LogIndexFlag,
 		utils.LightServeFlag,    // deprecated
 		utils.LightIngressFlag,  // deprecated
 		utils.LightEgressFlag,   // deprecated
@@ -161,14 +162,26 @@ var (
 		utils.BuilderBlockValidationBlacklistSourceFilePath,
 		utils.BuilderBlockValidationUseBalanceDiff,
 		utils.BuilderBlockValidationExcludeWithdrawals,
+		utils.BuilderBlockValidationBatchWorkers,
+		utils.BuilderBlockValidationCacheSize,
 		utils.BuilderEnableLocalRelay,
 		utils.BuilderSecondsInSlot,
 		utils.BuilderSlotsInEpoch,
 		utils.BuilderDisableBundleFetcher,
+		utils.BuilderOrderSources,
+		utils.BuilderBundleNotifyChannel,
 		utils.BuilderDryRun,
 		utils.BuilderIgnoreLatePayloadAttributes,
 		utils.BuilderSecretKey,
 		utils.BuilderRelaySecretKey,
+		utils.BuilderKeystore,
+		utils.BuilderKeystorePasswordFile,
+		utils.BuilderRemoteSigner,
+		utils.BuilderRemoteSignerPubkey,
+		utils.BuilderRelayKeystore,
+		utils.BuilderRelayKeystorePasswordFile,
+		utils.BuilderRelayRemoteSigner,
+		utils.BuilderRelayRemoteSignerPubkey,
 		utils.BuilderListenAddr,
 		utils.BuilderGenesisForkVersion,
 		utils.BuilderBellatrixForkVersion,
@@ -181,7 +194,18 @@ var (
 		utils.BuilderBlockResubmitInterval,
 		utils.BuilderSubmissionOffset,
 		utils.BuilderDiscardRevertibleTxOnErr,
+		utils.BuilderRecordDirFlag,
+		utils.BuilderSearcherReputationFlag,
+		utils.BuilderSearcherRateLimitFlag,
+		utils.BuilderBundleSimulationTimeoutFlag,
+		utils.BuilderBlobAwarePackingFlag,
+		utils.BuilderTopOfBlockSlotsFlag,
+		utils.BuilderTopOfBlockMinBidFlag,
 		utils.BuilderEnableCancellations,
+		utils.BuilderSpeculativeHeads,
+		utils.BuilderGasLimitPolicy,
+		utils.BuilderGasLimitPolicyFile,
+		utils.BuilderFallbackBlock,
 		utils.BuilderBlockProcessorURL,
 	}
 
@@ -212,9 +236,15 @@ var (
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCTraceExportDirFlag,
+		utils.RPCFullBundlesFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCLimitsFlag,
+		utils.RPCRecordFileFlag,
+		utils.RPCRecordMaxSizeFlag,
+		utils.RPCRecordMethodsFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -273,6 +303,8 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See tracecmd.go
+		exportTracesCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
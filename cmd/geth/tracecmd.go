@@ -0,0 +1,151 @@
+// Copyright 2024 flashbots
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	exportTracesEndpointFlag = &cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node tracing the blocks (default = IPC endpoint of the datadir)",
+	}
+	exportTracesDirFlag = &cli.StringFlag{
+		Name:  "dir",
+		Usage: "Directory the trace files are written to, relative to the trace export directory of the node",
+	}
+	exportTracesTracerFlag = &cli.StringFlag{
+		Name:  "tracer",
+		Usage: "Tracer producing the traces, the callTracer traces are exported with one record per call frame",
+		Value: "callTracer",
+	}
+	exportTracesTracerConfigFlag = &cli.StringFlag{
+		Name:  "tracerconfig",
+		Usage: "JSON configuration of the tracer",
+	}
+	exportTracesChunkFlag = &cli.Uint64Flag{
+		Name:  "chunk",
+		Usage: "Number of blocks per trace file",
+		Value: 10000,
+	}
+
+	exportTracesCommand = &cli.Command{
+		Action:    exportTraces,
+		Name:      "export-traces",
+		Usage:     "Export the traces of a block range to files (connect to node)",
+		ArgsUsage: "<first> <last>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			exportTracesEndpointFlag,
+			exportTracesDirFlag,
+			exportTracesTracerFlag,
+			exportTracesTracerConfigFlag,
+			exportTracesChunkFlag,
+		},
+		Description: `
+The export-traces command makes a running node trace the blocks from first to
+last, both included, with debug_exportTraces. The traces are written to one file
+of length-prefixed RLP records per chunk of blocks, in the trace export directory
+of the node (--rpc.traceexportdir). Running the command again
+resumes an interrupted export, skipping the chunks already exported.`,
+	}
+)
+
+// exportTraces subscribes to debug_exportTraces and logs the exported chunks until
+// the export completes or fails.
+func exportTraces(ctx *cli.Context) error {
+	if ctx.Args().Len() != 2 {
+		utils.Fatalf("This command requires two arguments.")
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer")
+	}
+	endpoint := ctx.String(exportTracesEndpointFlag.Name)
+	if endpoint == "" {
+		cfg := defaultNodeConfig()
+		utils.SetDataDir(ctx, &cfg)
+		endpoint = cfg.IPCEndpoint()
+	}
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to geth: %v", err)
+	}
+	defer client.Close()
+
+	var (
+		tracer = ctx.String(exportTracesTracerFlag.Name)
+		chunk  = hexutil.Uint64(ctx.Uint64(exportTracesChunkFlag.Name))
+		config = &tracers.ExportTracesConfig{
+			TraceConfig: tracers.TraceConfig{Tracer: &tracer},
+			Dir:         ctx.String(exportTracesDirFlag.Name),
+			ChunkSize:   &chunk,
+		}
+	)
+	if cfg := ctx.String(exportTracesTracerConfigFlag.Name); cfg != "" {
+		config.TracerConfig = json.RawMessage(cfg)
+	}
+	type exportResult struct {
+		From    hexutil.Uint64 `json:"from"`
+		To      hexutil.Uint64 `json:"to"`
+		File    string         `json:"file"`
+		Records hexutil.Uint64 `json:"records"`
+		Skipped bool           `json:"skipped"`
+		Error   string         `json:"error"`
+	}
+	results := make(chan exportResult)
+	sub, err := client.Subscribe(context.Background(), "debug", results, "exportTraces", rpc.BlockNumber(first), rpc.BlockNumber(last), config)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case res := <-results:
+			if res.Error != "" {
+				return fmt.Errorf("export of blocks %d-%d failed: %s", res.From, res.To, res.Error)
+			}
+			if res.Skipped {
+				log.Info("Skipped exported traces", "from", uint64(res.From), "to", uint64(res.To), "file", res.File)
+			} else {
+				log.Info("Exported traces", "from", uint64(res.From), "to", uint64(res.To), "records", uint64(res.Records), "file", res.File)
+			}
+			if uint64(res.To) >= last {
+				return nil
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2024 flashbots
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// rpcreplay sends the calls recorded with --rpc.record.file to another node and
+// reports the calls whose responses differ from the recorded ones.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var app = flags.NewApp("RPC call replay tool")
+
+var (
+	endpointFlag = &cli.StringFlag{
+		Name:     "endpoint",
+		Usage:    "RPC endpoint of the node the calls are replayed to",
+		Required: true,
+	}
+	methodsFlag = &cli.StringFlag{
+		Name:  "methods",
+		Usage: "Comma separated list of the methods replayed (default = all)",
+	}
+	timeoutFlag = &cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Timeout of each replayed call",
+		Value: 30 * time.Second,
+	}
+	quietFlag = &cli.BoolFlag{
+		Name:  "quiet",
+		Usage: "Only print the summary, not the differing responses",
+	}
+)
+
+func init() {
+	app.ArgsUsage = "<record file> [<record file>...]"
+	app.Action = replay
+	app.Flags = []cli.Flag{
+		endpointFlag,
+		methodsFlag,
+		timeoutFlag,
+		quietFlag,
+	}
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// methodTotals counts the replayed calls of a method.
+type methodTotals struct {
+	calls    int
+	matching int
+	slower   int
+	duration time.Duration // recorded duration of the calls
+	replayed time.Duration // duration of the replayed calls
+}
+
+func replay(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return errors.New("no record file given")
+	}
+	var methods map[string]bool
+	if ctx.IsSet(methodsFlag.Name) {
+		methods = make(map[string]bool)
+		for _, method := range utils.SplitAndTrim(ctx.String(methodsFlag.Name)) {
+			methods[method] = true
+		}
+	}
+	client, err := rpc.DialContext(ctx.Context, ctx.String(endpointFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var (
+		totals  = make(map[string]*methodTotals)
+		timeout = ctx.Duration(timeoutFlag.Name)
+		quiet   = ctx.Bool(quietFlag.Name)
+	)
+	for _, path := range ctx.Args().Slice() {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = rpc.ReadRecordedCalls(f, func(call *rpc.RecordedCall) error {
+			if methods != nil && !methods[call.Method] {
+				return nil
+			}
+			t := totals[call.Method]
+			if t == nil {
+				t = new(methodTotals)
+				totals[call.Method] = t
+			}
+			result, rpcErr, duration, err := replayCall(ctx.Context, client, call, timeout)
+			if err != nil {
+				return err
+			}
+			t.calls++
+			t.duration += call.Duration
+			t.replayed += duration
+			if duration > call.Duration {
+				t.slower++
+			}
+			if equalJSON(call.Result, result) && equalJSON(call.Error, rpcErr) {
+				t.matching++
+				return nil
+			}
+			if !quiet {
+				fmt.Printf("%s %s\n", call.Method, call.Params)
+				fmt.Printf("  recorded: %s\n", response(call.Result, call.Error))
+				fmt.Printf("  replayed: %s\n", response(result, rpcErr))
+			}
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	names := make([]string, 0, len(totals))
+	for method := range totals {
+		names = append(names, method)
+	}
+	sort.Strings(names)
+	out := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(out, "METHOD\tCALLS\tMATCHING\tDIFFERING\tAVG RECORDED\tAVG REPLAYED\tSLOWER")
+	for _, method := range names {
+		t := totals[method]
+		fmt.Fprintf(out, "%s\t%d\t%d\t%d\t%v\t%v\t%d\n", method, t.calls, t.matching, t.calls-t.matching,
+			t.duration/time.Duration(t.calls), t.replayed/time.Duration(t.calls), t.slower)
+	}
+	return out.Flush()
+}
+
+// replayCall sends the recorded call to the node, returning the json-encoded result
+// or error of the call. Errors of the transport are returned as err.
+func replayCall(ctx context.Context, client *rpc.Client, call *rpc.RecordedCall, timeout time.Duration) (result, rpcErr json.RawMessage, duration time.Duration, err error) {
+	var params []json.RawMessage
+	if len(call.Params) > 0 {
+		if err := json.Unmarshal(call.Params, &params); err != nil {
+			return nil, nil, 0, fmt.Errorf("invalid params of %s call: %w", call.Method, err)
+		}
+	}
+	args := make([]interface{}, len(params))
+	for i := range params {
+		args[i] = params[i]
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = client.CallContext(ctx, &result, call.Method, args...)
+	duration = time.Since(start)
+	if err == nil {
+		return result, nil, duration, nil
+	}
+	var callErr rpc.Error
+	if !errors.As(err, &callErr) {
+		return nil, nil, 0, fmt.Errorf("%s call failed: %w", call.Method, err)
+	}
+	// Encode the error as the server does
+	encoded := map[string]interface{}{
+		"code":    callErr.ErrorCode(),
+		"message": callErr.Error(),
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		encoded["data"] = dataErr.ErrorData()
+	}
+	rpcErr, err = json.Marshal(encoded)
+	return nil, rpcErr, duration, err
+}
+
+// equalJSON reports whether the json values are equal, regardless of formatting and
+// of the order of object keys.
+func equalJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func response(result, rpcErr json.RawMessage) string {
+	if len(rpcErr) > 0 {
+		return "error " + string(rpcErr)
+	}
+	return string(result)
+}
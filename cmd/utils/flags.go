@@ -283,6 +283,11 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "history.logindex",
+		Usage:    "Maintain an index of the log addresses and topics to speed up log queries",
+		Category: flags.StateCategory,
+	}
 	// Transaction pool settings
 	TxPoolLocalsFlag = &cli.StringFlag{
 		Name:     "txpool.locals",
@@ -552,6 +557,16 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCTraceExportDirFlag = &cli.StringFlag{
+		Name:     "rpc.traceexportdir",
+		Usage:    "Directory the traces exported with debug_exportTraces are confined to (default = inside the datadir)",
+		Category: flags.APICategory,
+	}
+	RPCFullBundlesFlag = &cli.BoolFlag{
+		Name:     "rpc.fullbundles",
+		Usage:    "Send the full bundles to the newBundles subscribers rather than their hints (only with trusted subscribers)",
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -652,6 +667,18 @@ var (
 		Value:    false,
 		Category: flags.BuilderCategory,
 	}
+	BuilderBlockValidationBatchWorkers = &cli.IntFlag{
+		Name:     "builder.validation_batch_workers",
+		Usage:    "Number of submissions of a batch validated concurrently by the block validation API (0 = number of CPUs).",
+		Value:    0,
+		Category: flags.BuilderCategory,
+	}
+	BuilderBlockValidationCacheSize = &cli.IntFlag{
+		Name:     "builder.validation_cache_size",
+		Usage:    "Number of outcomes cached by the block validation API, keyed by block hash and bid (0 = disabled).",
+		Value:    0,
+		Category: flags.BuilderCategory,
+	}
 	BuilderEnableLocalRelay = &cli.BoolFlag{
 		Name:     "builder.local_relay",
 		Usage:    "Enable the local relay",
@@ -674,6 +701,23 @@ var (
 		Usage:    "Disable the bundle fetcher",
 		Category: flags.BuilderCategory,
 	}
+	BuilderBundleNotifyChannel = &cli.StringFlag{
+		Name: "builder.bundle_notify_channel",
+		Usage: "Postgres channel on which the ids of inserted bundles are notified. When set, the bundle fetcher fetches bundles as they " +
+			"are notified instead of polling them, polling only after reconnecting to the database. Notified bundles are pushed in order of " +
+			"arrival within the per block limits of polled ones (500 high priority and 100 low priority bundles)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BUNDLE_NOTIFY_CHANNEL"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderOrderSources = &cli.StringSliceFlag{
+		Name: "builder.order_source",
+		Usage: "Additional source of bundles: a JSON lines file (jsonl:<path>, jsonl:- for stdin) or the newBundles subscription of " +
+			"an upstream endpoint (ws://... or wss://...). The query parameters name, rate, burst and token_file set the name of the source " +
+			"in metrics, its rate limits in bundles per second and a file holding a bearer token. Upstream geth nodes must run with " +
+			"--rpc.fullbundles, as they otherwise only send bundle hints. This flag can be given multiple times",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ORDER_SOURCE"},
+		Category: flags.BuilderCategory,
+	}
 	BuilderDryRun = &cli.BoolFlag{
 		Name:     "builder.dry-run",
 		Usage:    "Builder only validates blocks without submission to the relay",
@@ -698,6 +742,54 @@ var (
 		Value:    "0x2fc12ae741f29701f8e30f5de6350766c020cb80768a0ff01e6838ffd2431e11",
 		Category: flags.BuilderCategory,
 	}
+	BuilderKeystore = &cli.StringFlag{
+		Name:     "builder.keystore",
+		Usage:    "EIP-2335 keystore of the builder key used for signing blocks, takes precedence over --builder.secret_key",
+		EnvVars:  []string{"BUILDER_KEYSTORE"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderKeystorePasswordFile = &cli.StringFlag{
+		Name:     "builder.keystore_password_file",
+		Usage:    "File containing the password of the builder keystore",
+		EnvVars:  []string{"BUILDER_KEYSTORE_PASSWORD_FILE"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderRemoteSigner = &cli.StringFlag{
+		Name:     "builder.remote_signer_url",
+		Usage:    "URL of a remote signer implementing the builder signing API holding the builder key used for signing blocks, takes precedence over --builder.keystore",
+		EnvVars:  []string{"BUILDER_REMOTE_SIGNER_URL"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderRemoteSignerPubkey = &cli.StringFlag{
+		Name:     "builder.remote_signer_pubkey",
+		Usage:    "Public key of the builder key held by the remote signer, required if the signer holds several keys",
+		EnvVars:  []string{"BUILDER_REMOTE_SIGNER_PUBKEY"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderRelayKeystore = &cli.StringFlag{
+		Name:     "builder.relay_keystore",
+		Usage:    "EIP-2335 keystore of the local relay key used for signing headers, takes precedence over --builder.relay_secret_key",
+		EnvVars:  []string{"BUILDER_RELAY_KEYSTORE"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderRelayKeystorePasswordFile = &cli.StringFlag{
+		Name:     "builder.relay_keystore_password_file",
+		Usage:    "File containing the password of the local relay keystore",
+		EnvVars:  []string{"BUILDER_RELAY_KEYSTORE_PASSWORD_FILE"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderRelayRemoteSigner = &cli.StringFlag{
+		Name:     "builder.relay_remote_signer_url",
+		Usage:    "URL of a remote signer implementing the builder signing API holding the local relay key used for signing headers, takes precedence over --builder.relay_keystore",
+		EnvVars:  []string{"BUILDER_RELAY_REMOTE_SIGNER_URL"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderRelayRemoteSignerPubkey = &cli.StringFlag{
+		Name:     "builder.relay_remote_signer_pubkey",
+		Usage:    "Public key of the local relay key held by the remote signer, required if the signer holds several keys",
+		EnvVars:  []string{"BUILDER_RELAY_REMOTE_SIGNER_PUBKEY"},
+		Category: flags.BuilderCategory,
+	}
 	BuilderListenAddr = &cli.StringFlag{
 		Name:     "builder.listen_addr",
 		Usage:    "Listening address for builder endpoint",
@@ -797,6 +889,52 @@ var (
 		Value:    builder.DefaultConfig.DiscardRevertibleTxOnErr,
 		Category: flags.BuilderCategory,
 	}
+	BuilderRecordDirFlag = &cli.StringFlag{
+		Name:     "builder.record_dir",
+		Usage:    "Directory to record the inputs of every block building job to (parent, payload attributes, pending txs and bundles). Records can be replayed with the backtest tool.",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_RECORD_DIR"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherReputationFlag = &cli.BoolFlag{
+		Name:     "builder.searcher_reputation",
+		Usage:    "Simulate the bundles of searchers with the best track record first, on a bounded number of workers. Searchers are identified by the authenticated signing address of the bundles in the database, the other bundles share one rate limit and are simulated last",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_REPUTATION"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherRateLimitFlag = &cli.Float64Flag{
+		Name:     "builder.searcher_rate_limit",
+		Usage:    "Maximum bundle simulations per second of a reliable searcher, scaled down with their success rate (0 = unlimited). Requires --builder.searcher_reputation",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_RATE_LIMIT"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderBundleSimulationTimeoutFlag = &cli.DurationFlag{
+		Name:     "builder.bundle_simulation_timeout",
+		Usage:    "Time budget of bundle simulation per block, bundles not simulated in time are skipped (0 = unlimited). Requires --builder.searcher_reputation",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BUNDLE_SIMULATION_TIMEOUT"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderBlobAwarePackingFlag = &cli.BoolFlag{
+		Name:     "builder.blob_aware_packing",
+		Usage:    "Choose the blob transactions and bundles that maximise block value within the blob limit, instead of packing them by gas price",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BLOB_AWARE_PACKING"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderTopOfBlockSlotsFlag = &cli.IntFlag{
+		Name: "builder.top_of_block_slots",
+		Usage: "Number of bundles flagged with topOfBlock placed at the top of the block, before any other order, by a sealed-bid " +
+			"auction on their coinbase payments. Losing bids are dropped, bids worth less than the most valuable other bundle " +
+			"or paying less than --builder.top_of_block_min_bid compete like other bundles. With 0, flagged bundles compete like other bundles",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_TOP_OF_BLOCK_SLOTS"},
+		Value:    ethconfig.Defaults.Miner.TopOfBlockSlots,
+		Category: flags.BuilderCategory,
+	}
+	BuilderTopOfBlockMinBidFlag = &flags.BigFlag{
+		Name:     "builder.top_of_block_min_bid",
+		Usage:    "Minimum coinbase payment in wei of the winning bids of the top of block auction",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_TOP_OF_BLOCK_MIN_BID"},
+		Category: flags.BuilderCategory,
+	}
 
 	BuilderEnableCancellations = &cli.BoolFlag{
 		Name:     "builder.cancellations",
@@ -804,6 +942,39 @@ var (
 		Category: flags.BuilderCategory,
 	}
 
+	BuilderSpeculativeHeads = &cli.IntFlag{
+		Name: "builder.speculative_heads",
+		Usage: "Maximum number of candidate parent heads built on concurrently within a slot. When payload attributes on another head arrive, " +
+			"the job on the head requested least recently is cancelled only once this limit is reached. Has no effect with --builder.ignore_late_payload_attributes",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SPECULATIVE_HEADS"},
+		Value:    builder.SpeculativeHeadsDefault,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderGasLimitPolicy = &cli.StringFlag{
+		Name: "builder.gas_limit_policy",
+		Usage: "Gas limit policy of validators without one in --builder.gas_limit_policy_file: follow the registered gas limit (follow), " +
+			"target at most the given limit (clamp:<limit>) or refuse to build for validators registered above the given limit (refuse:<limit>). " +
+			"Also applied by the block validation API to the registered gas limit of submissions",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_GAS_LIMIT_POLICY"},
+		Value:    builder.DefaultConfig.GasLimitPolicy,
+		Category: flags.BuilderCategory,
+	}
+	BuilderGasLimitPolicyFile = &cli.StringFlag{
+		Name:     "builder.gas_limit_policy_file",
+		Usage:    "Path to a JSON file mapping validator public keys to their gas limit policy, e.g. {\"0xa1b2...\": \"clamp:30000000\"}",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_GAS_LIMIT_POLICY_FILE"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderFallbackBlock = &cli.StringFlag{
+		Name: "builder.fallback_block",
+		Usage: "Block submitted when the node is not synced or building a block fails, so that the proposer still receives a bid: " +
+			"none, empty (no transactions), payout (only the proposer payment) or mempool (mempool transactions without bundles)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_FALLBACK_BLOCK"},
+		Value:    builder.DefaultConfig.FallbackBlock,
+		Category: flags.BuilderCategory,
+	}
+
 	BuilderBlockProcessorURL = &cli.StringFlag{
 		Name:     "builder.block_processor_url",
 		Usage:    "RPC URL for the block processor",
@@ -941,6 +1112,27 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCLimitsFlag = &cli.StringFlag{
+		Name:     "rpc.limits",
+		Usage:    "JSON file with the API keys accepted over HTTP and WebSocket, and their call limits per method",
+		Category: flags.APICategory,
+	}
+	RPCRecordFileFlag = &cli.StringFlag{
+		Name:     "rpc.record.file",
+		Usage:    "File the calls served over HTTP and WebSocket are recorded to, as lines of JSON",
+		Category: flags.APICategory,
+	}
+	RPCRecordMaxSizeFlag = &cli.IntFlag{
+		Name:     "rpc.record.maxsize",
+		Usage:    "Maximum size in MBs of a recorded calls file before it is rotated",
+		Value:    100,
+		Category: flags.APICategory,
+	}
+	RPCRecordMethodsFlag = &cli.StringFlag{
+		Name:     "rpc.record.methods",
+		Usage:    "Comma separated list of the methods whose calls are recorded (default = all)",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -1394,6 +1586,26 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCLimitsFlag.Name) {
+		blob, err := os.ReadFile(ctx.String(RPCLimitsFlag.Name))
+		if err != nil {
+			Fatalf("Failed to read RPC limits: %v", err)
+		}
+		cfg.RPCLimits = new(rpc.LimitsConfig)
+		if err := json.Unmarshal(blob, cfg.RPCLimits); err != nil {
+			Fatalf("Failed to parse RPC limits: %v", err)
+		}
+	}
+
+	if ctx.IsSet(RPCRecordFileFlag.Name) {
+		cfg.RPCRecordFile = ctx.String(RPCRecordFileFlag.Name)
+		cfg.RPCRecordMaxSize = ctx.Int(RPCRecordMaxSizeFlag.Name)
+	}
+
+	if ctx.IsSet(RPCRecordMethodsFlag.Name) {
+		cfg.RPCRecordMethods = SplitAndTrim(ctx.String(RPCRecordMethodsFlag.Name))
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1609,10 +1821,20 @@ func SetBuilderConfig(ctx *cli.Context, cfg *builder.Config) {
 	cfg.SlotsInEpoch = ctx.Uint64(BuilderSlotsInEpoch.Name)
 	cfg.SecondsInSlot = ctx.Uint64(BuilderSecondsInSlot.Name)
 	cfg.DisableBundleFetcher = ctx.IsSet(BuilderDisableBundleFetcher.Name)
+	cfg.OrderSources = ctx.StringSlice(BuilderOrderSources.Name)
+	cfg.BundleNotifyChannel = ctx.String(BuilderBundleNotifyChannel.Name)
 	cfg.DryRun = ctx.IsSet(BuilderDryRun.Name)
 	cfg.IgnoreLatePayloadAttributes = ctx.IsSet(BuilderIgnoreLatePayloadAttributes.Name)
 	cfg.BuilderSecretKey = ctx.String(BuilderSecretKey.Name)
 	cfg.RelaySecretKey = ctx.String(BuilderRelaySecretKey.Name)
+	cfg.BuilderKeystore = ctx.String(BuilderKeystore.Name)
+	cfg.BuilderKeystorePasswordFile = ctx.String(BuilderKeystorePasswordFile.Name)
+	cfg.BuilderRemoteSigner = ctx.String(BuilderRemoteSigner.Name)
+	cfg.BuilderRemoteSignerPubkey = ctx.String(BuilderRemoteSignerPubkey.Name)
+	cfg.RelayKeystore = ctx.String(BuilderRelayKeystore.Name)
+	cfg.RelayKeystorePasswordFile = ctx.String(BuilderRelayKeystorePasswordFile.Name)
+	cfg.RelayRemoteSigner = ctx.String(BuilderRelayRemoteSigner.Name)
+	cfg.RelayRemoteSignerPubkey = ctx.String(BuilderRelayRemoteSignerPubkey.Name)
 	cfg.ListenAddr = ctx.String(BuilderListenAddr.Name)
 	cfg.GenesisForkVersion = ctx.String(BuilderGenesisForkVersion.Name)
 	cfg.BellatrixForkVersion = ctx.String(BuilderBellatrixForkVersion.Name)
@@ -1636,6 +1858,10 @@ func SetBuilderConfig(ctx *cli.Context, cfg *builder.Config) {
 	cfg.BuilderSubmissionOffset = ctx.Duration(BuilderSubmissionOffset.Name)
 	cfg.DiscardRevertibleTxOnErr = ctx.Bool(BuilderDiscardRevertibleTxOnErr.Name)
 	cfg.EnableCancellations = ctx.IsSet(BuilderEnableCancellations.Name)
+	cfg.SpeculativeHeads = ctx.Int(BuilderSpeculativeHeads.Name)
+	cfg.GasLimitPolicy = ctx.String(BuilderGasLimitPolicy.Name)
+	cfg.GasLimitPolicyFile = ctx.String(BuilderGasLimitPolicyFile.Name)
+	cfg.FallbackBlock = ctx.String(BuilderFallbackBlock.Name)
 	cfg.BuilderRateLimitResubmitInterval = ctx.String(BuilderBlockResubmitInterval.Name)
 
 	cfg.BlockProcessorURL = ctx.String(BuilderBlockProcessorURL.Name)
@@ -1856,6 +2082,17 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 
 	cfg.DiscardRevertibleTxOnErr = ctx.Bool(BuilderDiscardRevertibleTxOnErr.Name)
 	cfg.PriceCutoffPercent = ctx.Int(BuilderPriceCutoffPercentFlag.Name)
+	if ctx.IsSet(BuilderRecordDirFlag.Name) {
+		cfg.RecordDir = ctx.String(BuilderRecordDirFlag.Name)
+	}
+	cfg.SearcherReputation = ctx.Bool(BuilderSearcherReputationFlag.Name)
+	cfg.SearcherRateLimit = ctx.Float64(BuilderSearcherRateLimitFlag.Name)
+	cfg.BundleSimulationTimeout = ctx.Duration(BuilderBundleSimulationTimeoutFlag.Name)
+	cfg.BlobAwarePacking = ctx.Bool(BuilderBlobAwarePackingFlag.Name)
+	cfg.TopOfBlockSlots = ctx.Int(BuilderTopOfBlockSlotsFlag.Name)
+	if ctx.IsSet(BuilderTopOfBlockMinBidFlag.Name) {
+		cfg.TopOfBlockMinBid = flags.GlobalBig(ctx, BuilderTopOfBlockMinBidFlag.Name)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1998,6 +2235,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
+	if ctx.IsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.Bool(LogIndexFlag.Name)
+	}
 	// Parse transaction history flag, if user is still using legacy config
 	// file with 'TxLookupLimit' configured, copy the value to 'TransactionHistory'.
 	if cfg.TransactionHistory == ethconfig.Defaults.TransactionHistory && cfg.TxLookupLimit != ethconfig.Defaults.TxLookupLimit {
@@ -2063,6 +2303,14 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCTraceExportDirFlag.Name) {
+		cfg.TraceExportDir = ctx.String(RPCTraceExportDirFlag.Name)
+	} else if cfg.TraceExportDir == "" {
+		cfg.TraceExportDir = stack.ResolvePath("traces")
+	}
+	if ctx.IsSet(RPCFullBundlesFlag.Name) {
+		cfg.FilterFullBundles = ctx.Bool(RPCFullBundlesFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -2216,7 +2464,7 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config, bpCfg *builder.
 		}
 	}
 
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, cfg.TraceExportDir))
 	return backend.APIBackend, backend
 }
 
@@ -2239,6 +2487,7 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
 		LogCacheSize: ethcfg.FilterLogCacheSize,
+		FullBundles:  ethcfg.FilterFullBundles,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
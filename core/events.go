@@ -17,6 +17,8 @@
 package core
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -27,6 +29,19 @@ type NewTxsEvent struct{ Txs []*types.Transaction }
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
+// NewBundlesEvent is posted when bundles enter the bundle pools.
+type NewBundlesEvent struct {
+	MevBundles []types.MevBundle
+	SBundles   []*types.SBundle
+}
+
+// NewPendingBlockEvent is posted when the miner seals a candidate block, with the
+// value of the block for its builder.
+type NewPendingBlockEvent struct {
+	Block  *types.Block
+	Profit *big.Int
+}
+
 // RemovedLogsEvent is posted when a reorg happens
 type RemovedLogsEvent struct{ Logs []*types.Log }
 
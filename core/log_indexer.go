@@ -0,0 +1,256 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const (
+	// logIndexThrottling is the time to wait between processing two consecutive
+	// index sections. It's useful during chain upgrades to prevent disk overload.
+	logIndexThrottling = 100 * time.Millisecond
+)
+
+// LogIndexer implements a core.ChainIndexer, building up an inverted index from the
+// log addresses and topics to the blocks containing them, permitting the logs of an
+// address or topic to be looked up without scanning the blocks of a range.
+//
+// The index is split in sections: for each address and each (position, topic) pair
+// of the logs of a section, the offsets of the blocks containing them are stored
+// delta encoded as uvarints.
+type LogIndexer struct {
+	size    uint64              // section size to generate the index for
+	db      ethdb.Database      // database instance to write index data and metadata into
+	section uint64              // Section is the section number being processed currently
+	head    common.Hash         // Head is the hash of the last header processed
+	blocks  map[string][]uint64 // Block offsets of the entries of the section
+}
+
+// NewLogIndexer returns a chain indexer that generates the log index for the
+// canonical chain.
+func NewLogIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
+	backend := &LogIndexer{
+		db:   db,
+		size: size,
+	}
+	table := rawdb.NewTable(db, string(rawdb.LogIndexIndexPrefix))
+
+	return NewChainIndexer(db, table, backend, size, confirms, logIndexThrottling, "logindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section. The
+// entries previously written for the section, before a reorg, are removed.
+func (b *LogIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	rawdb.DeleteLogIndexSection(b.db, section)
+	b.section, b.head, b.blocks = section, common.Hash{}, make(map[string][]uint64)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the addresses and topics of
+// the logs of a new header into the index.
+func (b *LogIndexer) Process(ctx context.Context, header *types.Header) error {
+	var (
+		hash   = header.Hash()
+		number = header.Number.Uint64()
+		logs   = rawdb.ReadLogs(b.db, hash, number)
+	)
+	if logs == nil && header.Bloom != (types.Bloom{}) {
+		return fmt.Errorf("missing receipts of block #%d [%x]", number, hash)
+	}
+	offset := number - b.section*b.size
+	for _, txLogs := range logs {
+		for _, log := range txLogs {
+			b.add(logIndexAddressEntry(log.Address), offset)
+			for i, topic := range log.Topics {
+				b.add(logIndexTopicEntry(i, topic), offset)
+			}
+		}
+	}
+	b.head = hash
+	return nil
+}
+
+// add records that the block at the given offset contains the entry.
+func (b *LogIndexer) add(entry []byte, offset uint64) {
+	blocks := b.blocks[string(entry)]
+	if n := len(blocks); n > 0 && blocks[n-1] == offset {
+		return
+	}
+	b.blocks[string(entry)] = append(blocks, offset)
+}
+
+// Commit implements core.ChainIndexerBackend, finalizing the log index section and
+// writing it out into the database.
+func (b *LogIndexer) Commit() error {
+	batch := b.db.NewBatch()
+	for entry, blocks := range b.blocks {
+		var (
+			data = make([]byte, 0, len(blocks))
+			last uint64
+		)
+		for _, offset := range blocks {
+			data = binary.AppendUvarint(data, offset-last)
+			last = offset
+		}
+		rawdb.WriteLogIndex(batch, b.section, b.head, []byte(entry), data)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	rawdb.WriteLogIndexSection(batch, b.section, b.head)
+	return batch.Write()
+}
+
+// Prune returns an empty error since we don't support pruning here.
+func (b *LogIndexer) Prune(threshold uint64) error {
+	return nil
+}
+
+// LogIndexMatches returns the numbers of the blocks of a log index section that
+// contain logs of the given addresses and topics, in ascending order. As for log
+// filters, the addresses and each position of the topics are alternatives, an empty
+// list matching any value. At least one address or topic is required.
+func LogIndexMatches(db ethdb.Reader, size, section uint64, addresses []common.Address, topics [][]common.Hash) ([]uint64, error) {
+	head := rawdb.ReadCanonicalHash(db, (section+1)*size-1)
+	if !rawdb.HasLogIndexSection(db, section, head) {
+		return nil, fmt.Errorf("log index section %d not available", section)
+	}
+	var groups [][][]byte
+	if len(addresses) > 0 {
+		group := make([][]byte, len(addresses))
+		for i, address := range addresses {
+			group[i] = logIndexAddressEntry(address)
+		}
+		groups = append(groups, group)
+	}
+	for i, sub := range topics {
+		if len(sub) == 0 {
+			continue // empty rule set == wildcard
+		}
+		group := make([][]byte, len(sub))
+		for j, topic := range sub {
+			group[j] = logIndexTopicEntry(i, topic)
+		}
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("no address or topic to look up")
+	}
+	var matches []uint64
+	for i, group := range groups {
+		var blocks []uint64
+		for _, entry := range group {
+			offsets, err := decodeLogIndexBlocks(rawdb.ReadLogIndex(db, section, head, entry))
+			if err != nil {
+				return nil, fmt.Errorf("log index section %d: %w", section, err)
+			}
+			blocks = mergeBlocks(blocks, offsets)
+		}
+		if i == 0 {
+			matches = blocks
+		} else {
+			matches = intersectBlocks(matches, blocks)
+		}
+		if len(matches) == 0 {
+			return nil, nil
+		}
+	}
+	for i := range matches {
+		matches[i] += section * size
+	}
+	return matches, nil
+}
+
+// logIndexAddressEntry returns the index entry of a log address.
+func logIndexAddressEntry(address common.Address) []byte {
+	return address.Bytes()
+}
+
+// logIndexTopicEntry returns the index entry of a log topic at the given position.
+func logIndexTopicEntry(position int, topic common.Hash) []byte {
+	return append([]byte{byte(position)}, topic.Bytes()...)
+}
+
+// decodeLogIndexBlocks decodes the delta encoded block offsets of an index entry.
+func decodeLogIndexBlocks(data []byte) ([]uint64, error) {
+	var (
+		blocks []uint64
+		last   uint64
+	)
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("invalid block offsets")
+		}
+		last += delta
+		blocks = append(blocks, last)
+		data = data[n:]
+	}
+	return blocks, nil
+}
+
+// mergeBlocks returns the union of two ascending lists of block offsets.
+func mergeBlocks(a, b []uint64) []uint64 {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make([]uint64, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// intersectBlocks returns the intersection of two ascending lists of block offsets.
+func intersectBlocks(a, b []uint64) []uint64 {
+	var shared []uint64
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			shared, a, b = append(shared, a[0]), a[1:], b[1:]
+		}
+	}
+	return shared
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// writeLogIndexTestBlock writes a canonical block with a receipt holding the logs.
+func writeLogIndexTestBlock(db ethdb.Database, number uint64, extra byte, logs ...*types.Log) *types.Header {
+	receipts := types.Receipts{{Status: types.ReceiptStatusSuccessful, Logs: logs}}
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Extra:  []byte{extra},
+		Bloom:  types.CreateBloom(receipts),
+	}
+	if len(logs) == 0 {
+		receipts = nil
+	}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteCanonicalHash(db, header.Hash(), number)
+	rawdb.WriteReceipts(db, header.Hash(), number, receipts)
+	return header
+}
+
+func processLogIndexSection(t *testing.T, indexer *LogIndexer, section uint64, headers []*types.Header) {
+	t.Helper()
+	if err := indexer.Reset(context.Background(), section, common.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range headers {
+		if err := indexer.Process(context.Background(), header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogIndexer(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		indexer = &LogIndexer{db: db, size: 4}
+		addrA   = common.HexToAddress("0xa")
+		addrB   = common.HexToAddress("0xb")
+		topic1  = common.HexToHash("0x1")
+		topic2  = common.HexToHash("0x2")
+		logs    = map[uint64][]*types.Log{
+			1: {{Address: addrA, Topics: []common.Hash{topic1}}},
+			2: {{Address: addrB, Topics: []common.Hash{topic1, topic2}}, {Address: addrB}},
+			5: {{Address: addrA, Topics: []common.Hash{topic2, topic1}}},
+			6: {{Address: addrA, Topics: []common.Hash{topic1}}},
+		}
+		headers []*types.Header
+	)
+	for number := uint64(0); number < 8; number++ {
+		headers = append(headers, writeLogIndexTestBlock(db, number, 0, logs[number]...))
+	}
+	processLogIndexSection(t, indexer, 0, headers[:4])
+	processLogIndexSection(t, indexer, 1, headers[4:])
+
+	tests := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+		want      [2][]uint64
+	}{
+		{addresses: []common.Address{addrA}, want: [2][]uint64{{1}, {5, 6}}},
+		{addresses: []common.Address{addrA, addrB}, want: [2][]uint64{{1, 2}, {5, 6}}},
+		{topics: [][]common.Hash{{topic1}}, want: [2][]uint64{{1, 2}, {6}}},
+		{topics: [][]common.Hash{nil, {topic1}}, want: [2][]uint64{nil, {5}}},
+		{topics: [][]common.Hash{{topic1, topic2}, {topic1, topic2}}, want: [2][]uint64{{2}, {5}}},
+		{addresses: []common.Address{addrA}, topics: [][]common.Hash{{topic1}, {topic2}}, want: [2][]uint64{nil, nil}},
+		{addresses: []common.Address{common.HexToAddress("0xc")}, want: [2][]uint64{nil, nil}},
+	}
+	for i, tt := range tests {
+		for section := uint64(0); section < 2; section++ {
+			have, err := LogIndexMatches(db, 4, section, tt.addresses, tt.topics)
+			if err != nil {
+				t.Fatalf("test %d section %d: %v", i, section, err)
+			}
+			if !reflect.DeepEqual(have, tt.want[section]) {
+				t.Errorf("test %d section %d: have %v, want %v", i, section, have, tt.want[section])
+			}
+		}
+	}
+	if _, err := LogIndexMatches(db, 4, 0, nil, [][]common.Hash{nil}); err == nil {
+		t.Error("expected error without addresses and topics")
+	}
+
+	// Reorg the second section, moving the logs of block 6 to block 7.
+	oldHead := headers[7].Hash()
+	headers[6] = writeLogIndexTestBlock(db, 6, 1)
+	headers[7] = writeLogIndexTestBlock(db, 7, 1, logs[6]...)
+	if _, err := LogIndexMatches(db, 4, 1, []common.Address{addrA}, nil); err == nil {
+		t.Fatal("expected error for the reorged section")
+	}
+	processLogIndexSection(t, indexer, 1, headers[4:])
+	have, err := LogIndexMatches(db, 4, 1, []common.Address{addrA}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{5, 7}; !reflect.DeepEqual(have, want) {
+		t.Errorf("reorged section: have %v, want %v", have, want)
+	}
+	if rawdb.HasLogIndexSection(db, 1, oldHead) || rawdb.ReadLogIndex(db, 1, oldHead, addrA.Bytes()) != nil {
+		t.Error("entries of the reorged section not removed")
+	}
+
+	// Blocks with logs but without receipts can't be indexed.
+	rawdb.DeleteReceipts(db, headers[5].Hash(), 5)
+	if err := indexer.Reset(context.Background(), 1, common.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.Process(context.Background(), headers[5]); err == nil {
+		t.Error("expected error for missing receipts")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// validatorPubkeyLength is the length of the BLS public keys of validators.
+const validatorPubkeyLength = 48
+
+// ReadValidatorRegistration retrieves the ssz encoded signed registration of the
+// validator with the given public key, nil if the validator is not registered.
+func ReadValidatorRegistration(db ethdb.KeyValueReader, pubkey []byte) []byte {
+	data, _ := db.Get(validatorRegistrationKey(pubkey))
+	return data
+}
+
+// WriteValidatorRegistration stores the ssz encoded signed registration of the
+// validator with the given public key.
+func WriteValidatorRegistration(db ethdb.KeyValueWriter, pubkey []byte, registration []byte) {
+	if err := db.Put(validatorRegistrationKey(pubkey), registration); err != nil {
+		log.Crit("Failed to store validator registration", "err", err)
+	}
+}
+
+// IterateValidatorRegistrations returns an iterator over the ssz encoded signed
+// registrations of all validators.
+func IterateValidatorRegistrations(db ethdb.Iteratee) ethdb.Iterator {
+	return NewKeyLengthIterator(db.NewIterator(validatorRegistrationPrefix, nil), len(validatorRegistrationPrefix)+validatorPubkeyLength)
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestValidatorRegistrations(t *testing.T) {
+	db := NewMemoryDatabase()
+	pubkey1 := bytes.Repeat([]byte{0x01}, validatorPubkeyLength)
+	pubkey2 := bytes.Repeat([]byte{0x02}, validatorPubkeyLength)
+
+	if data := ReadValidatorRegistration(db, pubkey1); data != nil {
+		t.Fatalf("unexpected registration %x", data)
+	}
+	WriteValidatorRegistration(db, pubkey1, []byte{0xaa})
+	WriteValidatorRegistration(db, pubkey2, []byte{0xbb})
+	// Keys sharing the prefix but not of registrations are not iterated.
+	db.Put(append(validatorRegistrationKey(pubkey1), 0x00), []byte{0xcc})
+
+	if data := ReadValidatorRegistration(db, pubkey1); !bytes.Equal(data, []byte{0xaa}) {
+		t.Fatalf("registration mismatch: have %x, want aa", data)
+	}
+	it := IterateValidatorRegistrations(db)
+	defer it.Release()
+	var values [][]byte
+	for it.Next() {
+		values = append(values, common.CopyBytes(it.Value()))
+	}
+	if len(values) != 2 || !bytes.Equal(values[0], []byte{0xaa}) || !bytes.Equal(values[1], []byte{0xbb}) {
+		t.Fatalf("iterated registrations mismatch: %x", values)
+	}
+}
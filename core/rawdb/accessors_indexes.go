@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -179,3 +180,79 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// ReadLogIndex retrieves the encoded block offsets of the given log index entry
+// in the section, nil if the entry has no logs in the section.
+func ReadLogIndex(db ethdb.KeyValueReader, section uint64, head common.Hash, entry []byte) []byte {
+	data, _ := db.Get(logIndexKey(section, head, entry))
+	return data
+}
+
+// WriteLogIndex stores the encoded block offsets of the given log index entry in
+// the section.
+func WriteLogIndex(db ethdb.KeyValueWriter, section uint64, head common.Hash, entry []byte, blocks []byte) {
+	if err := db.Put(logIndexKey(section, head, entry), blocks); err != nil {
+		log.Crit("Failed to store log index", "err", err)
+	}
+}
+
+// HasLogIndexSection reports whether the log index section ending with the given
+// head has been written.
+func HasLogIndexSection(db ethdb.KeyValueReader, section uint64, head common.Hash) bool {
+	ok, _ := db.Has(logIndexKey(section, head, nil))
+	return ok
+}
+
+// WriteLogIndexSection marks the log index section ending with the given head as
+// written, after all of its entries.
+func WriteLogIndexSection(db ethdb.KeyValueWriter, section uint64, head common.Hash) {
+	if err := db.Put(logIndexKey(section, head, nil), []byte{}); err != nil {
+		log.Crit("Failed to store log index section", "err", err)
+	}
+}
+
+// DeleteLogIndexSection removes the log index entries of the given section,
+// whatever the head of the section they were written for.
+func DeleteLogIndexSection(db ethdb.Database, section uint64) {
+	prefix := make([]byte, len(logIndexPrefix)+8)
+	copy(prefix, logIndexPrefix)
+	binary.BigEndian.PutUint64(prefix[len(logIndexPrefix):], section)
+
+	deleteLogIndexRange(db, prefix, len(prefix)+common.HashLength)
+}
+
+// DeleteLogIndex removes the whole log index along with the progress of the chain
+// indexer maintaining it.
+func DeleteLogIndex(db ethdb.Database) {
+	deleteLogIndexRange(db, logIndexPrefix, len(logIndexPrefix)+8+common.HashLength)
+	deleteLogIndexRange(db, LogIndexIndexPrefix, 0)
+}
+
+// deleteLogIndexRange removes the keys with the given prefix that are at least
+// minLength long.
+func deleteLogIndexRange(db ethdb.Database, prefix []byte, minLength int) {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		if len(it.Key()) < minLength {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			log.Crit("Failed to delete log index", "err", err)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete log index", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if it.Error() != nil {
+		log.Crit("Failed to delete log index", "err", it.Error())
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete log index", "err", err)
+	}
+}
@@ -107,6 +107,7 @@ var (
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	logIndexPrefix        = []byte("E") // logIndexPrefix + section (uint64 big endian) + hash + entry -> block offsets
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
@@ -124,6 +125,9 @@ var (
 	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
 
+	// LogIndexIndexPrefix is the data table of a chain indexer to track its progress
+	LogIndexIndexPrefix = []byte("iL")
+
 	ChtPrefix           = []byte("chtRootV2-") // ChtPrefix + chtNum (uint64 big endian) -> trie root hash
 	ChtTablePrefix      = []byte("cht-")
 	ChtIndexTablePrefix = []byte("chtIndexV2-")
@@ -134,6 +138,8 @@ var (
 
 	CliqueSnapshotPrefix = []byte("clique-")
 
+	validatorRegistrationPrefix = []byte("builder-validator-registration-") // validatorRegistrationPrefix + pubkey -> ssz encoded signed validator registration
+
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
 	FixedCommitteeRootKey = []byte("fixedRoot-") // bigEndian64(syncPeriod) -> committee root hash
 	SyncCommitteeKey      = []byte("committee-") // bigEndian64(syncPeriod) -> serialized committee
@@ -226,6 +232,21 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexKey = logIndexPrefix + section (uint64 big endian) + hash + entry
+func logIndexKey(section uint64, hash common.Hash, entry []byte) []byte {
+	key := make([]byte, len(logIndexPrefix)+8+common.HashLength+len(entry))
+	copy(key, logIndexPrefix)
+	binary.BigEndian.PutUint64(key[len(logIndexPrefix):], section)
+	copy(key[len(logIndexPrefix)+8:], hash.Bytes())
+	copy(key[len(logIndexPrefix)+8+common.HashLength:], entry)
+	return key
+}
+
+// validatorRegistrationKey = validatorRegistrationPrefix + pubkey
+func validatorRegistrationKey(pubkey []byte) []byte {
+	return append(append([]byte{}, validatorRegistrationPrefix...), pubkey...)
+}
+
 // skeletonHeaderKey = skeletonHeaderPrefix + num (uint64 big endian)
 func skeletonHeaderKey(number uint64) []byte {
 	return append(skeletonHeaderPrefix, encodeBlockNumber(number)...)
@@ -45,6 +45,10 @@ const (
 	TxStatusIncluded
 )
 
+// bundleChanSize is the number of bundle events queued for the bundle subscribers,
+// newer events are dropped while the queue is full.
+const bundleChanSize = 256
+
 var (
 	// reservationsGaugeName is the prefix of a per-subpool address reservation
 	// metric.
@@ -92,6 +96,8 @@ type TxPool struct {
 	mevBundles    []types.MevBundle
 	bundleFetcher IFetcher
 	sbundles      *SBundlePool
+	bundleFeed    event.Feed                // Event feed to send out the bundles entering the pools
+	bundleCh      chan core.NewBundlesEvent // Bundle events queued for the feed, off the insertion path
 }
 
 // New creates a new transaction pool to gather, sort and filter inbound
@@ -109,6 +115,7 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 		term:         make(chan struct{}),
 		sync:         make(chan chan error),
 		sbundles:     NewSBundlePool(chain.Config()),
+		bundleCh:     make(chan core.NewBundlesEvent, bundleChanSize),
 	}
 	for i, subpool := range subpools {
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
@@ -122,6 +129,7 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 	pool.sbundles.ResetPoolData(head)
 
 	go pool.loop(head, chain)
+	go pool.bundleLoop()
 	return pool, nil
 }
 
@@ -515,7 +523,7 @@ func (p *TxPool) IsPrivateTxHash(hash common.Hash) bool {
 // MevBundle methods
 
 // AddMevBundle enqueues a bundle of transactions into the pool if they are valid.
-func (p *TxPool) AddMevBundle(txs []*types.Transaction, blockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+func (p *TxPool) AddMevBundle(txs []*types.Transaction, blockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash, topOfBlock bool) error {
 	bundleHasher := sha3.NewLegacyKeccak256()
 	for _, tx := range txs {
 		_, err := bundleHasher.Write(tx.Hash().Bytes())
@@ -525,10 +533,7 @@ func (p *TxPool) AddMevBundle(txs []*types.Transaction, blockNumber *big.Int, re
 	}
 	bundleHash := common.BytesToHash(bundleHasher.Sum(nil))
 
-	p.bundleLock.Lock()
-	defer p.bundleLock.Unlock()
-
-	p.mevBundles = append(p.mevBundles, types.MevBundle{
+	p.AddMevBundles([]types.MevBundle{{
 		Txs:               txs,
 		BlockNumber:       blockNumber,
 		Uuid:              replacementUuid,
@@ -537,15 +542,17 @@ func (p *TxPool) AddMevBundle(txs []*types.Transaction, blockNumber *big.Int, re
 		MaxTimestamp:      maxTimestamp,
 		RevertingTxHashes: revertingTxHashes,
 		Hash:              bundleHash,
-	})
+		TopOfBlock:        topOfBlock,
+	}})
 	return nil
 }
 
 func (p *TxPool) AddMevBundles(bundles []types.MevBundle) {
 	p.bundleLock.Lock()
-	defer p.bundleLock.Unlock()
-
 	p.mevBundles = append(p.mevBundles, bundles...)
+	p.bundleLock.Unlock()
+
+	p.sendBundles(core.NewBundlesEvent{MevBundles: bundles})
 }
 
 // MevBundles returns a list of bundles valid for the given blockNumber/blockTimestamp
@@ -605,7 +612,39 @@ func (p *TxPool) MevBundles(blockNumber *big.Int, blockTimestamp uint64) ([]type
 }
 
 func (p *TxPool) AddSBundle(bundle *types.SBundle) error {
-	return p.sbundles.Add(bundle)
+	if err := p.sbundles.Add(bundle); err != nil {
+		return err
+	}
+	p.sendBundles(core.NewBundlesEvent{SBundles: []*types.SBundle{bundle}})
+	return nil
+}
+
+// sendBundles queues the bundles for the subscribers. The bundles are dropped if the
+// subscribers fall too far behind, rather than stalling the insertion of bundles.
+func (p *TxPool) sendBundles(ev core.NewBundlesEvent) {
+	select {
+	case p.bundleCh <- ev:
+	default:
+		log.Debug("Dropped bundles event", "bundles", len(ev.MevBundles), "sbundles", len(ev.SBundles))
+	}
+}
+
+// bundleLoop is a standalone goroutine to deliver the bundles entering the pools to
+// the subscribers, off the insertion path.
+func (p *TxPool) bundleLoop() {
+	for {
+		select {
+		case ev := <-p.bundleCh:
+			p.bundleFeed.Send(ev)
+		case <-p.term:
+			return
+		}
+	}
+}
+
+// SubscribeNewBundles registers a subscription for the bundles entering the pools.
+func (p *TxPool) SubscribeNewBundles(ch chan<- core.NewBundlesEvent) event.Subscription {
+	return p.subs.Track(p.bundleFeed.Subscribe(ch))
 }
 
 func (p *TxPool) CancelSBundles(hashes []common.Hash) {
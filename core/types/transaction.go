@@ -614,6 +614,11 @@ type MevBundle struct {
 	MaxTimestamp      uint64
 	RevertingTxHashes []common.Hash
 	Hash              common.Hash
+	// TopOfBlock bundles bid for the first positions of the block with their coinbase payment
+	TopOfBlock bool
+	// SignerAuthenticated is set if SigningAddress was authenticated before the bundle
+	// entered the pool, rather than merely claimed by the sender of the bundle
+	SignerAuthenticated bool
 }
 
 func (b *MevBundle) UniquePayload() []byte {
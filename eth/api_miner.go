@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/miner"
 )
 
 // MinerAPI provides an API to control the miner.
@@ -84,3 +85,18 @@ func (api *MinerAPI) SetEtherbase(etherbase common.Address) bool {
 func (api *MinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
 }
+
+// SearcherReputation returns the reputation of the searcher signing bundles with the
+// given address, nil if the searcher is not tracked.
+func (api *MinerAPI) SearcherReputation(signer common.Address) (*miner.SearcherScore, error) {
+	score, ok, err := api.e.Miner().SearcherScore(signer)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &score, nil
+}
+
+// SearcherReputations returns the reputation of all tracked searchers, best first.
+func (api *MinerAPI) SearcherReputations() ([]miner.SearcherScore, error) {
+	return api.e.Miner().SearcherScores()
+}
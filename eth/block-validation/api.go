@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"strconv"
 
 	builderApiBellatrix "github.com/attestantio/go-builder-client/api/bellatrix"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
@@ -95,6 +96,14 @@ type BlockValidationConfig struct {
 	UseBalanceDiffProfit bool
 	// If set to true, withdrawals to the fee recipient are excluded from the balance difference.
 	ExcludeWithdrawals bool
+	// Number of submissions of a batch validated concurrently, defaults to the number of CPUs.
+	BatchWorkers int
+	// Number of validation outcomes cached by bid, 0 disables the cache.
+	CacheSize int
+	// Gas limit policy of the proposers without one in GasLimitPolicyFile, follow if empty.
+	GasLimitPolicy string
+	// Path of a JSON file mapping proposer public keys to their gas limit policy.
+	GasLimitPolicyFile string
 }
 
 // Register adds catalyst APIs to the full node.
@@ -108,10 +117,22 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg BlockValidationConfig
 		}
 	}
 
+	gasLimitPolicies, err := NewGasLimitPolicies(cfg.GasLimitPolicy, cfg.GasLimitPolicyFile)
+	if err != nil {
+		return err
+	}
+
+	api := NewBlockValidationAPI(backend, accessVerifier, cfg.UseBalanceDiffProfit, cfg.ExcludeWithdrawals)
+	api.gasLimitPolicies = gasLimitPolicies
+	if cfg.BatchWorkers > 0 {
+		api.batchWorkers = cfg.BatchWorkers
+	}
+	api.SetCacheSize(cfg.CacheSize)
+
 	stack.RegisterAPIs([]rpc.API{
 		{
 			Namespace: "flashbots",
-			Service:   NewBlockValidationAPI(backend, accessVerifier, cfg.UseBalanceDiffProfit, cfg.ExcludeWithdrawals),
+			Service:   api,
 		},
 	})
 	return nil
@@ -124,24 +145,97 @@ type BlockValidationAPI struct {
 	useBalanceDiffProfit bool
 	// If set to true, withdrawals to the fee recipient are excluded from the balance delta.
 	excludeWithdrawals bool
+	// Number of submissions of a batch validated concurrently.
+	batchWorkers int
+	// Outcomes of previous validations, nil if caching is disabled.
+	cache *validationCache
+	// Gas limit policies applied to the registered gas limits, nil to follow them.
+	gasLimitPolicies *GasLimitPolicies
 }
 
 // NewConsensusAPI creates a new consensus api for the given backend.
 // The underlying blockchain needs to have a valid terminal total difficulty set.
+// The validation outcomes are not cached, see SetCacheSize.
 func NewBlockValidationAPI(eth *eth.Ethereum, accessVerifier *AccessVerifier, useBalanceDiffProfit, excludeWithdrawals bool) *BlockValidationAPI {
 	return &BlockValidationAPI{
 		eth:                  eth,
 		accessVerifier:       accessVerifier,
 		useBalanceDiffProfit: useBalanceDiffProfit,
 		excludeWithdrawals:   excludeWithdrawals,
+		batchWorkers:         defaultBatchWorkers,
 	}
 }
 
+// SetCacheSize makes the validation cache the given number of outcomes by bid, 0
+// disables the cache.
+func (api *BlockValidationAPI) SetCacheSize(size int) {
+	if size > 0 {
+		api.cache = newValidationCache(size, api.isCanonical)
+	} else {
+		api.cache = nil
+	}
+}
+
+// SetGasLimitPolicies makes the validation check the gas limit of blocks against the
+// registered gas limit of their proposer as adjusted by the given policies.
+func (api *BlockValidationAPI) SetGasLimitPolicies(policies *GasLimitPolicies) {
+	api.gasLimitPolicies = policies
+}
+
+func (api *BlockValidationAPI) isCanonical(hash common.Hash, number uint64) bool {
+	return api.eth.BlockChain().GetCanonicalHash(number) == hash
+}
+
 type BuilderBlockValidationRequest struct {
 	builderApiBellatrix.SubmitBlockRequest
 	RegisteredGasLimit uint64 `json:"registered_gas_limit,string"`
 }
 
+func (r *BuilderBlockValidationRequest) UnmarshalJSON(data []byte) error {
+	params := &struct {
+		RegisteredGasLimit uint64 `json:"registered_gas_limit,string"`
+	}{}
+	err := json.Unmarshal(data, params)
+	if err != nil {
+		return err
+	}
+	r.RegisteredGasLimit = params.RegisteredGasLimit
+
+	blockRequest := new(builderApiBellatrix.SubmitBlockRequest)
+	err = json.Unmarshal(data, &blockRequest)
+	if err != nil {
+		return err
+	}
+	r.SubmitBlockRequest = *blockRequest
+	return nil
+}
+
+func (r *BuilderBlockValidationRequest) MarshalJSON() ([]byte, error) {
+	return marshalValidationRequest(&r.SubmitBlockRequest, map[string]interface{}{
+		"registered_gas_limit": strconv.FormatUint(r.RegisteredGasLimit, 10),
+	})
+}
+
+// marshalValidationRequest encodes a validation request as the builder block request
+// it embeds, with the fields of the validation request added. The block requests
+// implement json.Marshaler, which would otherwise drop these fields.
+func marshalValidationRequest(blockRequest json.Marshaler, fields map[string]interface{}) ([]byte, error) {
+	data, err := blockRequest.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var enc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if enc[name], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(enc)
+}
+
 func (api *BlockValidationAPI) ValidateBuilderSubmissionV1(params *BuilderBlockValidationRequest) error {
 	// no longer supported endpoint
 	if params.ExecutionPayload == nil {
@@ -180,6 +274,12 @@ func (r *BuilderBlockValidationRequestV2) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (r *BuilderBlockValidationRequestV2) MarshalJSON() ([]byte, error) {
+	return marshalValidationRequest(&r.SubmitBlockRequest, map[string]interface{}{
+		"registered_gas_limit": strconv.FormatUint(r.RegisteredGasLimit, 10),
+	})
+}
+
 func (api *BlockValidationAPI) ValidateBuilderSubmissionV2(params *BuilderBlockValidationRequestV2) error {
 	// TODO: fuzztest, make sure the validation is sound
 	// TODO: handle context!
@@ -222,6 +322,13 @@ func (r *BuilderBlockValidationRequestV3) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (r *BuilderBlockValidationRequestV3) MarshalJSON() ([]byte, error) {
+	return marshalValidationRequest(&r.SubmitBlockRequest, map[string]interface{}{
+		"parent_beacon_block_root": r.ParentBeaconBlockRoot,
+		"registered_gas_limit":     strconv.FormatUint(r.RegisteredGasLimit, 10),
+	})
+}
+
 func (api *BlockValidationAPI) ValidateBuilderSubmissionV3(params *BuilderBlockValidationRequestV3) error {
 	// TODO: fuzztest, make sure the validation is sound
 	payload := params.ExecutionPayload
@@ -262,21 +369,57 @@ func (api *BlockValidationAPI) validateBlock(block *types.Block, msg *builderApi
 		return fmt.Errorf("incorrect GasUsed %d, expected %d", msg.GasUsed, block.GasUsed())
 	}
 
+	registeredGasLimit, err := api.gasLimitPolicies.For(msg.ProposerPubkey.String()).Target(registeredGasLimit)
+	if err != nil {
+		return err
+	}
+
+	// The blacklist is checked before the cache, the cached outcomes only hold the
+	// checks done while executing the block.
 	feeRecipient := common.BytesToAddress(msg.ProposerFeeRecipient[:])
-	expectedProfit := msg.Value.ToBig()
+	if err := api.checkBlacklist(block, feeRecipient); err != nil {
+		return err
+	}
+	if api.cache == nil || msg.Value == nil {
+		return api.executeBlock(block, feeRecipient, msg.Value.ToBig(), registeredGasLimit)
+	}
 
+	key := validationCacheKey{
+		blockHash:          block.Hash(),
+		feeRecipient:       feeRecipient,
+		value:              *msg.Value,
+		registeredGasLimit: registeredGasLimit,
+	}
+	if err, ok := api.cache.get(key); ok {
+		log.Info("validated block from cache", "hash", block.Hash(), "number", block.NumberU64(), "parentHash", block.ParentHash(), "err", err)
+		return err
+	}
+	err = api.executeBlock(block, feeRecipient, msg.Value.ToBig(), registeredGasLimit)
+	api.cache.add(key, block.ParentHash(), block.NumberU64()-1, err)
+	return err
+}
+
+// checkBlacklist checks the coinbase, the fee recipient and the senders and recipients
+// of the transactions of the block against the blacklist.
+func (api *BlockValidationAPI) checkBlacklist(block *types.Block, feeRecipient common.Address) error {
+	if api.accessVerifier == nil {
+		return nil
+	}
+	if err := api.accessVerifier.isBlacklisted(block.Coinbase()); err != nil {
+		return err
+	}
+	if err := api.accessVerifier.isBlacklisted(feeRecipient); err != nil {
+		return err
+	}
+	return api.accessVerifier.verifyTransactions(types.LatestSigner(api.eth.BlockChain().Config()), block.Transactions())
+}
+
+// executeBlock executes the block on top of its parent, checking the accounts accessed
+// by its transactions against the blacklist.
+func (api *BlockValidationAPI) executeBlock(block *types.Block, feeRecipient common.Address, expectedProfit *big.Int, registeredGasLimit uint64) error {
 	var vmconfig vm.Config
 	var tracer *logger.AccessListTracer = nil
 	if api.accessVerifier != nil {
-		if err := api.accessVerifier.isBlacklisted(block.Coinbase()); err != nil {
-			return err
-		}
-		if err := api.accessVerifier.isBlacklisted(feeRecipient); err != nil {
-			return err
-		}
-		if err := api.accessVerifier.verifyTransactions(types.LatestSigner(api.eth.BlockChain().Config()), block.Transactions()); err != nil {
-			return err
-		}
 		isPostMerge := true // the call is PoS-native
 		precompiles := vm.ActivePrecompiles(api.eth.APIBackend.ChainConfig().Rules(new(big.Int).SetUint64(block.NumberU64()), isPostMerge, block.Time()))
 		tracer = logger.NewAccessListTracer(nil, common.Address{}, common.Address{}, precompiles)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -635,6 +636,129 @@ func TestBlacklistLoad(t *testing.T) {
 	require.NoError(t, av.verifyTraces(tracer))
 }
 
+func TestBuilderBlockValidationRequestJSON(t *testing.T) {
+	bid := &builderApiV1.BidTrace{
+		Slot:      5,
+		BlockHash: phase0.Hash32{0x01},
+		GasLimit:  30_000_000,
+		Value:     uint256.NewInt(1000),
+	}
+
+	v1 := &BuilderBlockValidationRequest{
+		SubmitBlockRequest: builderApiBellatrix.SubmitBlockRequest{
+			Message: bid,
+			ExecutionPayload: &bellatrix.ExecutionPayload{
+				BlockNumber:  5,
+				GasLimit:     30_000_000,
+				ExtraData:    []byte{},
+				Transactions: []bellatrix.Transaction{},
+			},
+		},
+		RegisteredGasLimit: 36_000_000,
+	}
+	data, err := json.Marshal(v1)
+	require.NoError(t, err)
+	var decV1 BuilderBlockValidationRequest
+	require.NoError(t, json.Unmarshal(data, &decV1))
+	require.Equal(t, v1, &decV1)
+
+	v2 := &BuilderBlockValidationRequestV2{
+		SubmitBlockRequest: builderApiCapella.SubmitBlockRequest{
+			Message: bid,
+			ExecutionPayload: &capella.ExecutionPayload{
+				BlockNumber:  5,
+				GasLimit:     30_000_000,
+				ExtraData:    []byte{},
+				Transactions: []bellatrix.Transaction{},
+				Withdrawals:  []*capella.Withdrawal{},
+			},
+		},
+		RegisteredGasLimit: 36_000_000,
+	}
+	data, err = json.Marshal(v2)
+	require.NoError(t, err)
+	var decV2 BuilderBlockValidationRequestV2
+	require.NoError(t, json.Unmarshal(data, &decV2))
+	require.Equal(t, v2, &decV2)
+
+	v3 := &BuilderBlockValidationRequestV3{
+		SubmitBlockRequest: builderApiDeneb.SubmitBlockRequest{
+			Message: bid,
+			ExecutionPayload: &deneb.ExecutionPayload{
+				BlockNumber:   5,
+				GasLimit:      30_000_000,
+				ExtraData:     []byte{},
+				BaseFeePerGas: uint256.NewInt(7),
+				Transactions:  []bellatrix.Transaction{},
+				Withdrawals:   []*capella.Withdrawal{},
+			},
+			BlobsBundle: &builderApiDeneb.BlobsBundle{
+				Commitments: []deneb.KZGCommitment{},
+				Proofs:      []deneb.KZGProof{},
+				Blobs:       []deneb.Blob{},
+			},
+		},
+		ParentBeaconBlockRoot: common.Hash{0x02},
+		RegisteredGasLimit:    36_000_000,
+	}
+	data, err = json.Marshal(v3)
+	require.NoError(t, err)
+	var decV3 BuilderBlockValidationRequestV3
+	require.NoError(t, json.Unmarshal(data, &decV3))
+	require.Equal(t, v3, &decV3)
+}
+
+// TestBuilderBlockValidationRequestV1Decoding checks the decoding of a V1 request as
+// sent by the relays, the registered gas limit being a decimal string next to the fields
+// of the bellatrix block request.
+func TestBuilderBlockValidationRequestV1Decoding(t *testing.T) {
+	input := `{
+		"message": {
+			"slot": "5",
+			"parent_hash": "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"block_hash": "0x0100000000000000000000000000000000000000000000000000000000000000",
+			"builder_pubkey": "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			"proposer_pubkey": "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			"proposer_fee_recipient": "0x0200000000000000000000000000000000000000",
+			"gas_limit": "30000000",
+			"gas_used": "21000",
+			"value": "1000"
+		},
+		"execution_payload": {
+			"parent_hash": "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"fee_recipient": "0x0000000000000000000000000000000000000000",
+			"state_root": "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"receipts_root": "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"logs_bloom": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			"prev_randao": "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"block_number": "5",
+			"gas_limit": "30000000",
+			"gas_used": "21000",
+			"timestamp": "1700000000",
+			"extra_data": "0x",
+			"base_fee_per_gas": "7",
+			"block_hash": "0x0100000000000000000000000000000000000000000000000000000000000000",
+			"transactions": ["0x0102"]
+		},
+		"signature": "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"registered_gas_limit": "36000000"
+	}`
+
+	var req BuilderBlockValidationRequest
+	require.NoError(t, json.Unmarshal([]byte(input), &req))
+	require.Equal(t, uint64(36_000_000), req.RegisteredGasLimit)
+	require.Equal(t, uint64(5), req.Message.Slot)
+	require.Equal(t, phase0.Hash32{0x01}, req.Message.BlockHash)
+	require.Equal(t, bellatrix.ExecutionAddress{0x02}, req.Message.ProposerFeeRecipient)
+	require.Equal(t, uint256.NewInt(1000), req.Message.Value)
+	require.Equal(t, uint64(5), req.ExecutionPayload.BlockNumber)
+	require.Equal(t, uint64(21000), req.ExecutionPayload.GasUsed)
+	require.Equal(t, []bellatrix.Transaction{{0x01, 0x02}}, req.ExecutionPayload.Transactions)
+
+	// The registered gas limit is a decimal string, not a number.
+	require.Error(t, json.Unmarshal([]byte(strings.Replace(input, `"36000000"`, `36000000`, 1)), new(BuilderBlockValidationRequest)))
+}
+
 func ExecutableDataToExecutionPayload(data *engine.ExecutableData) (*bellatrix.ExecutionPayload, error) {
 	transactionData := make([]bellatrix.Transaction, len(data.Transactions))
 	for i, tx := range data.Transactions {
@@ -1011,6 +1135,13 @@ func TestValidateBuilderSubmissionV2_Blocklist(t *testing.T) {
 
 			require.NoError(t, apiNoBlock.ValidateBuilderSubmissionV2(req))
 			require.ErrorContains(t, apiWithBlock.ValidateBuilderSubmissionV2(req), "blacklisted")
+
+			// The cached outcome of a bid doesn't skip the blacklist.
+			apiCached := NewBlockValidationAPI(ethservice, nil, true, true)
+			apiCached.SetCacheSize(16)
+			require.NoError(t, apiCached.ValidateBuilderSubmissionV2(req))
+			apiCached.accessVerifier = accessVerifier
+			require.ErrorContains(t, apiCached.ValidateBuilderSubmissionV2(req), "blacklisted")
 		})
 	}
 }
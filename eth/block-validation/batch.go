@@ -0,0 +1,328 @@
+package blockvalidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxBatchSize is the maximum number of submissions accepted in a single batch call.
+const maxBatchSize = 256
+
+// defaultBatchWorkers is the number of submissions of a batch validated concurrently.
+var defaultBatchWorkers = runtime.NumCPU()
+
+var (
+	errEmptyBatch          = errors.New("empty batch")
+	errBatchTooLarge       = fmt.Errorf("batch too large, at most %d submissions allowed", maxBatchSize)
+	errBatchParentMismatch = errors.New("parent hash differs from the batch parent")
+	errValidationDeadline  = errors.New("validation deadline exceeded")
+)
+
+// BuilderBlockValidationBatchRequestV2 is a list of capella submissions built on the same parent.
+type BuilderBlockValidationBatchRequestV2 struct {
+	Requests []*BuilderBlockValidationRequestV2 `json:"requests"`
+	// TimeoutMs bounds the validation of every submission in the batch. Zero means no deadline.
+	TimeoutMs uint64 `json:"timeout_ms,string,omitempty"`
+}
+
+// BuilderBlockValidationBatchRequestV3 is a list of deneb submissions built on the same parent.
+type BuilderBlockValidationBatchRequestV3 struct {
+	Requests []*BuilderBlockValidationRequestV3 `json:"requests"`
+	// TimeoutMs bounds the validation of every submission in the batch. Zero means no deadline.
+	TimeoutMs uint64 `json:"timeout_ms,string,omitempty"`
+}
+
+// BuilderBlockValidationBatchResult is the outcome of validating one submission of a batch.
+// Results are returned in the order of the submitted requests.
+type BuilderBlockValidationBatchResult struct {
+	BlockHash common.Hash `json:"block_hash"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// batchJob is a single submission of a batch, already converted to a block.
+type batchJob struct {
+	block *types.Block
+	bid   validationCacheKey // identifies the duplicates of the submission
+	err   error
+
+	// validate runs the full validation of the submission.
+	validate func() error
+}
+
+// ValidateBuilderSubmissionBatchV2 validates a list of capella submissions for the same parent concurrently.
+func (api *BlockValidationAPI) ValidateBuilderSubmissionBatchV2(ctx context.Context, params *BuilderBlockValidationBatchRequestV2) ([]*BuilderBlockValidationBatchResult, error) {
+	if params == nil || len(params.Requests) == 0 {
+		return nil, errEmptyBatch
+	}
+	if len(params.Requests) > maxBatchSize {
+		return nil, errBatchTooLarge
+	}
+	jobs := make([]*batchJob, len(params.Requests))
+	for i, req := range params.Requests {
+		jobs[i] = newBatchJobV2(api, req)
+	}
+	return api.validateBatch(ctx, jobs, time.Duration(params.TimeoutMs)*time.Millisecond)
+}
+
+// ValidateBuilderSubmissionBatchV3 validates a list of deneb submissions for the same parent concurrently.
+func (api *BlockValidationAPI) ValidateBuilderSubmissionBatchV3(ctx context.Context, params *BuilderBlockValidationBatchRequestV3) ([]*BuilderBlockValidationBatchResult, error) {
+	if params == nil || len(params.Requests) == 0 {
+		return nil, errEmptyBatch
+	}
+	if len(params.Requests) > maxBatchSize {
+		return nil, errBatchTooLarge
+	}
+	jobs := make([]*batchJob, len(params.Requests))
+	for i, req := range params.Requests {
+		jobs[i] = newBatchJobV3(api, req)
+	}
+	return api.validateBatch(ctx, jobs, time.Duration(params.TimeoutMs)*time.Millisecond)
+}
+
+func newBatchJobV2(api *BlockValidationAPI, params *BuilderBlockValidationRequestV2) *batchJob {
+	if params == nil || params.ExecutionPayload == nil {
+		return &batchJob{err: errors.New("nil execution payload")}
+	}
+	if params.Message == nil {
+		return &batchJob{err: errors.New("nil bid trace")}
+	}
+	block, err := engine.ExecutionPayloadV2ToBlock(params.ExecutionPayload)
+	if err != nil {
+		return &batchJob{err: err}
+	}
+	return &batchJob{
+		block: block,
+		bid:   newBidKey(block, params.Message, params.RegisteredGasLimit),
+		validate: func() error {
+			return api.validateBlock(block, params.Message, params.RegisteredGasLimit)
+		},
+	}
+}
+
+func newBatchJobV3(api *BlockValidationAPI, params *BuilderBlockValidationRequestV3) *batchJob {
+	if params == nil || params.ExecutionPayload == nil {
+		return &batchJob{err: errors.New("nil execution payload")}
+	}
+	if params.Message == nil {
+		return &batchJob{err: errors.New("nil bid trace")}
+	}
+	if params.BlobsBundle == nil {
+		return &batchJob{err: errors.New("nil blobs bundle")}
+	}
+	block, err := engine.ExecutionPayloadV3ToBlock(params.ExecutionPayload, params.BlobsBundle, params.ParentBeaconBlockRoot)
+	if err != nil {
+		return &batchJob{err: err}
+	}
+	return &batchJob{
+		block: block,
+		bid:   newBidKey(block, params.Message, params.RegisteredGasLimit),
+		validate: func() error {
+			if err := api.validateBlock(block, params.Message, params.RegisteredGasLimit); err != nil {
+				return err
+			}
+			return validateBlobsBundle(block.Transactions(), params.BlobsBundle)
+		},
+	}
+}
+
+// newBidKey returns the key of a bid: submissions of the same block with a different
+// value, fee recipient or registered gas limit are validated separately.
+func newBidKey(block *types.Block, msg *builderApiV1.BidTrace, registeredGasLimit uint64) validationCacheKey {
+	key := validationCacheKey{
+		blockHash:          block.Hash(),
+		feeRecipient:       common.BytesToAddress(msg.ProposerFeeRecipient[:]),
+		registeredGasLimit: registeredGasLimit,
+	}
+	if msg.Value != nil {
+		key.value = *msg.Value
+	}
+	return key
+}
+
+// validateBatch deduplicates the jobs by bid, warms the parent state shared by all of
+// them and then validates the unique blocks concurrently, each within the given timeout.
+func (api *BlockValidationAPI) validateBatch(ctx context.Context, jobs []*batchJob, timeout time.Duration) ([]*BuilderBlockValidationBatchResult, error) {
+	var (
+		results    = make([]*BuilderBlockValidationBatchResult, len(jobs))
+		unique     = make(map[validationCacheKey]*batchJob)
+		blocks     = make(map[common.Hash]*types.Block)
+		parentHash common.Hash
+	)
+	for i, job := range jobs {
+		if job.err != nil {
+			results[i] = &BuilderBlockValidationBatchResult{Error: job.err.Error()}
+			continue
+		}
+		results[i] = &BuilderBlockValidationBatchResult{BlockHash: job.block.Hash()}
+		if parentHash == (common.Hash{}) {
+			parentHash = job.block.ParentHash()
+		}
+		if job.block.ParentHash() != parentHash {
+			job.err = errBatchParentMismatch
+			continue
+		}
+		if _, ok := unique[job.bid]; !ok {
+			unique[job.bid] = job
+			blocks[job.block.Hash()] = job.block
+		}
+	}
+	if len(blocks) > 0 {
+		prefetch := make([]*types.Block, 0, len(blocks))
+		for _, block := range blocks {
+			prefetch = append(prefetch, block)
+		}
+		api.prefetchParentState(prefetch)
+	}
+
+	var (
+		workers = api.batchWorkers
+		sem     = make(chan struct{}, workers)
+		errs    = make(map[validationCacheKey]error, len(unique))
+		lock    sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for bid, job := range unique {
+		wg.Add(1)
+		go func(bid validationCacheKey, job *batchJob) {
+			defer wg.Done()
+			err := runWithDeadline(ctx, timeout, sem, job.validate)
+			if err != nil {
+				log.Info("batch validation failed", "hash", bid.blockHash, "err", err)
+			}
+			lock.Lock()
+			errs[bid] = err
+			lock.Unlock()
+		}(bid, job)
+	}
+	wg.Wait()
+
+	for i, job := range jobs {
+		if job.err != nil {
+			results[i].Error = job.err.Error()
+			continue
+		}
+		if err := errs[job.bid]; err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+	log.Info("validated block batch", "parentHash", parentHash, "submissions", len(jobs), "unique", len(unique))
+	return results, nil
+}
+
+// runWithDeadline runs fn once a worker slot is available, giving up when the context
+// is cancelled or the timeout elapses. An abandoned fn keeps its slot until it returns.
+func runWithDeadline(ctx context.Context, timeout time.Duration, sem chan struct{}, fn func() error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return errValidationDeadline
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-sem }()
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errValidationDeadline
+	}
+}
+
+// prefetchParentState recovers the transaction senders of the given blocks and loads every
+// account they touch from the parent state. The accounts are read once for the whole batch,
+// so the validations running afterwards hit the shared snapshot and trie caches.
+func (api *BlockValidationAPI) prefetchParentState(blocks []*types.Block) {
+	var (
+		start  = time.Now()
+		chain  = api.eth.BlockChain()
+		first  = blocks[0]
+		parent = chain.GetHeader(first.ParentHash(), first.NumberU64()-1)
+	)
+	if parent == nil {
+		// Leave it to the validation to report the missing parent.
+		return
+	}
+	signer := types.MakeSigner(chain.Config(), first.Number(), first.Time())
+	core.SenderCacher.RecoverFromBlocks(signer, blocks)
+
+	accounts := make(map[common.Address]struct{})
+	slots := make(map[common.Address]map[common.Hash]struct{})
+	touch := func(addr common.Address) {
+		accounts[addr] = struct{}{}
+	}
+	for _, block := range blocks {
+		touch(block.Coinbase())
+		for _, w := range block.Withdrawals() {
+			touch(w.Address)
+		}
+		for _, tx := range block.Transactions() {
+			if from, err := types.Sender(signer, tx); err == nil {
+				touch(from)
+			}
+			if to := tx.To(); to != nil {
+				touch(*to)
+			}
+			for _, tuple := range tx.AccessList() {
+				touch(tuple.Address)
+				if slots[tuple.Address] == nil {
+					slots[tuple.Address] = make(map[common.Hash]struct{})
+				}
+				for _, key := range tuple.StorageKeys {
+					slots[tuple.Address][key] = struct{}{}
+				}
+			}
+		}
+	}
+	addrs := make([]common.Address, 0, len(accounts))
+	for addr := range accounts {
+		addrs = append(addrs, addr)
+	}
+
+	// Every worker reads its share of the accounts through its own state, the
+	// trie nodes and snapshot entries are cached by the shared state database.
+	var (
+		workers = api.batchWorkers
+		wg      sync.WaitGroup
+	)
+	if workers > len(addrs) {
+		workers = len(addrs)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			statedb, err := chain.StateAt(parent.Root)
+			if err != nil {
+				return
+			}
+			for i := w; i < len(addrs); i += workers {
+				addr := addrs[i]
+				statedb.GetBalance(addr)
+				statedb.GetCode(addr)
+				for key := range slots[addr] {
+					statedb.GetState(addr, key)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	log.Debug("prefetched batch parent state", "parentHash", parent.Hash(), "accounts", len(addrs), "elapsed", time.Since(start))
+}
@@ -0,0 +1,115 @@
+package blockvalidation
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBuilderSubmissionBatchV2(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	lastBlock := preMergeBlocks[len(preMergeBlocks)-1]
+	time := lastBlock.Time() + 5
+	genesis.Config.ShanghaiTime = &time
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	api := NewBlockValidationAPI(ethservice, nil, true, true)
+
+	baseFee := eip1559.CalcBaseFee(ethservice.BlockChain().Config(), lastBlock.Header())
+	statedb, _ := ethservice.BlockChain().StateAt(lastBlock.Root())
+	nonce := statedb.GetNonce(testAddr)
+	signer := types.LatestSigner(ethservice.BlockChain().Config())
+
+	// the payment of 7 wei to the proposer fee recipient is the only profit on top of the base fee
+	tx1, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0x16}, big.NewInt(10), 21000, baseFee, nil), signer, testKey)
+	tx2, _ := types.SignTx(types.NewTransaction(nonce+1, testValidatorAddr, big.NewInt(7), 21000, baseFee, nil), signer, testKey)
+	txs := types.Transactions{tx1, tx2}
+	withdrawalsRoot := types.DeriveSha(types.Withdrawals(nil), trie.NewStackTrie(nil))
+
+	newRequest := func(extraData []byte, value int64) *BuilderBlockValidationRequestV2 {
+		execData, err := buildBlock(buildBlockArgs{
+			parentHash:    lastBlock.Hash(),
+			parentRoot:    lastBlock.Root(),
+			feeRecipient:  testValidatorAddr,
+			txs:           txs,
+			number:        lastBlock.NumberU64() + 1,
+			gasLimit:      lastBlock.GasLimit(),
+			timestamp:     lastBlock.Time() + 5,
+			extraData:     extraData,
+			baseFeePerGas: baseFee,
+		}, ethservice.BlockChain())
+		require.NoError(t, err)
+		req, err := executableDataToBlockValidationRequest(execData, testValidatorAddr, big.NewInt(value), withdrawalsRoot)
+		require.NoError(t, err)
+		return req
+	}
+
+	validA := newRequest([]byte{0x01}, 7)
+	validB := newRequest([]byte{0x02}, 7)
+	overpaid := newRequest([]byte{0x03}, 8)
+
+	otherParent := newRequest([]byte{0x04}, 7)
+	otherParent.Message.ParentHash[0] ^= 0xff
+	otherParent.ExecutionPayload.ParentHash[0] ^= 0xff
+	updatePayloadHashV2(t, otherParent)
+
+	// a bid for the same block as validA with a higher value
+	sameBlockOverpaid := *validA
+	bid := *validA.Message
+	bid.Value = uint256.NewInt(8)
+	sameBlockOverpaid.Message = &bid
+
+	batch := &BuilderBlockValidationBatchRequestV2{
+		Requests: []*BuilderBlockValidationRequestV2{validA, overpaid, validB, validA, otherParent, {}, &sameBlockOverpaid},
+	}
+	results, err := api.ValidateBuilderSubmissionBatchV2(context.Background(), batch)
+	require.NoError(t, err)
+	require.Len(t, results, len(batch.Requests))
+
+	require.Equal(t, common.Hash(validA.Message.BlockHash), results[0].BlockHash)
+	require.Empty(t, results[0].Error)
+	require.Contains(t, results[1].Error, "payment")
+	require.Empty(t, results[2].Error)
+	// the duplicate gets the result of the first submission of the same bid
+	require.Equal(t, results[0], results[3])
+	require.Equal(t, errBatchParentMismatch.Error(), results[4].Error)
+	require.Contains(t, results[5].Error, "nil execution payload")
+	// bids for the same block are validated separately
+	require.Equal(t, results[0].BlockHash, results[6].BlockHash)
+	require.Contains(t, results[6].Error, "payment")
+
+	_, err = api.ValidateBuilderSubmissionBatchV2(context.Background(), &BuilderBlockValidationBatchRequestV2{})
+	require.ErrorIs(t, err, errEmptyBatch)
+}
+
+func TestRunWithDeadline(t *testing.T) {
+	sem := make(chan struct{}, 1)
+	release := make(chan struct{})
+	slow := func() error {
+		<-release
+		return nil
+	}
+
+	err := runWithDeadline(context.Background(), 10*time.Millisecond, sem, slow)
+	require.ErrorIs(t, err, errValidationDeadline)
+
+	// the abandoned validation still holds the only worker slot
+	err = runWithDeadline(context.Background(), 10*time.Millisecond, sem, func() error { return nil })
+	require.ErrorIs(t, err, errValidationDeadline)
+
+	close(release)
+	failure := errors.New("invalid block")
+	err = runWithDeadline(context.Background(), time.Second, sem, func() error { return failure })
+	require.ErrorIs(t, err, failure)
+}
@@ -0,0 +1,95 @@
+package blockvalidation
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/holiman/uint256"
+)
+
+var (
+	cacheHitMeter     = metrics.NewRegisteredMeter("flashbots/validation/cache/hit", nil)
+	cacheMissMeter    = metrics.NewRegisteredMeter("flashbots/validation/cache/miss", nil)
+	cacheReorgedMeter = metrics.NewRegisteredMeter("flashbots/validation/cache/reorged", nil)
+)
+
+// validationCacheKey identifies a bid. The same block can be valid for one bid and
+// invalid for another one, so everything the validation depends on is part of the key.
+type validationCacheKey struct {
+	blockHash          common.Hash
+	feeRecipient       common.Address
+	value              uint256.Int
+	registeredGasLimit uint64
+}
+
+// validationCacheEntry is the outcome of a validation, together with the parent it was
+// executed on so that it can be dropped once the parent is reorged out.
+type validationCacheEntry struct {
+	parentHash   common.Hash
+	parentNumber uint64
+	err          error
+}
+
+// validationCache is a bounded LRU of validation outcomes.
+type validationCache struct {
+	lock    sync.Mutex
+	entries lru.BasicLRU[validationCacheKey, *validationCacheEntry]
+
+	// isCanonical reports whether the given hash is the canonical block at the given height.
+	isCanonical func(hash common.Hash, number uint64) bool
+}
+
+func newValidationCache(size int, isCanonical func(common.Hash, uint64) bool) *validationCache {
+	return &validationCache{
+		entries:     lru.NewBasicLRU[validationCacheKey, *validationCacheEntry](size),
+		isCanonical: isCanonical,
+	}
+}
+
+// get returns the cached outcome of the validation. Outcomes computed on a parent that is
+// no longer canonical are evicted and reported as a miss.
+func (c *validationCache) get(key validationCacheKey) (error, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		cacheMissMeter.Mark(1)
+		return nil, false
+	}
+	if !c.isCanonical(entry.parentHash, entry.parentNumber) {
+		c.evictParent(entry.parentHash)
+		cacheReorgedMeter.Mark(1)
+		cacheMissMeter.Mark(1)
+		return nil, false
+	}
+	cacheHitMeter.Mark(1)
+	return entry.err, true
+}
+
+// add stores the outcome of a validation executed on the given parent. Outcomes are only
+// kept for canonical parents, anything else may not be reproducible.
+func (c *validationCache) add(key validationCacheKey, parentHash common.Hash, parentNumber uint64, err error) {
+	if !c.isCanonical(parentHash, parentNumber) {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries.Add(key, &validationCacheEntry{
+		parentHash:   parentHash,
+		parentNumber: parentNumber,
+		err:          err,
+	})
+}
+
+// evictParent drops every outcome computed on the given parent.
+func (c *validationCache) evictParent(parentHash common.Hash) {
+	for _, key := range c.entries.Keys() {
+		if entry, ok := c.entries.Peek(key); ok && entry.parentHash == parentHash {
+			c.entries.Remove(key)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package blockvalidation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationCache(t *testing.T) {
+	canonical := map[common.Hash]bool{{0x01}: true, {0x02}: true}
+	cache := newValidationCache(2, func(hash common.Hash, number uint64) bool {
+		return canonical[hash]
+	})
+
+	key := validationCacheKey{blockHash: common.Hash{0xaa}, feeRecipient: common.Address{0x01}, value: *uint256.NewInt(7), registeredGasLimit: 30_000_000}
+	_, ok := cache.get(key)
+	require.False(t, ok)
+
+	cache.add(key, common.Hash{0x01}, 1, nil)
+	err, ok := cache.get(key)
+	require.True(t, ok)
+	require.NoError(t, err)
+
+	// a different bid for the same block is a separate entry
+	overpaid := key
+	overpaid.value = *uint256.NewInt(8)
+	_, ok = cache.get(overpaid)
+	require.False(t, ok)
+
+	failure := errors.New("inaccurate payment")
+	cache.add(overpaid, common.Hash{0x01}, 1, failure)
+	err, ok = cache.get(overpaid)
+	require.True(t, ok)
+	require.Equal(t, failure, err)
+
+	// outcomes on non-canonical parents are not cached
+	sidechain := validationCacheKey{blockHash: common.Hash{0xbb}}
+	cache.add(sidechain, common.Hash{0x03}, 1, nil)
+	_, ok = cache.get(sidechain)
+	require.False(t, ok)
+
+	// reorging out the parent invalidates every outcome computed on it
+	other := validationCacheKey{blockHash: common.Hash{0xcc}}
+	cache.add(other, common.Hash{0x02}, 1, nil)
+	delete(canonical, common.Hash{0x01})
+	_, ok = cache.get(overpaid)
+	require.False(t, ok)
+	require.Equal(t, 1, cache.entries.Len())
+	_, ok = cache.get(other)
+	require.True(t, ok)
+}
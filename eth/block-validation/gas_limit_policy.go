@@ -0,0 +1,140 @@
+package blockvalidation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type GasLimitPolicyMode string
+
+const (
+	// GasLimitFollow targets the gas limit registered by the validator.
+	GasLimitFollow GasLimitPolicyMode = "follow"
+	// GasLimitClamp targets the registered gas limit, but never more than the policy limit.
+	GasLimitClamp GasLimitPolicyMode = "clamp"
+	// GasLimitRefuse targets the registered gas limit, and refuses blocks for validators
+	// registered with more than the policy limit.
+	GasLimitRefuse GasLimitPolicyMode = "refuse"
+)
+
+var ErrGasLimitRefused = errors.New("registered gas limit refused by policy")
+
+// GasLimitPolicy decides the gas limit targeted by blocks built for a validator from the gas
+// limit it registered with. It is written as "follow", "clamp:<limit>" or "refuse:<limit>".
+type GasLimitPolicy struct {
+	Mode  GasLimitPolicyMode
+	Limit uint64
+}
+
+// ParseGasLimitPolicy parses a policy written as "follow", "clamp:<limit>" or "refuse:<limit>".
+func ParseGasLimitPolicy(s string) (GasLimitPolicy, error) {
+	mode, limit, hasLimit := strings.Cut(strings.TrimSpace(s), ":")
+	policy := GasLimitPolicy{Mode: GasLimitPolicyMode(mode)}
+	switch policy.Mode {
+	case GasLimitFollow:
+		if hasLimit {
+			return GasLimitPolicy{}, fmt.Errorf("gas limit policy %q takes no limit", s)
+		}
+		return policy, nil
+	case GasLimitClamp, GasLimitRefuse:
+		if !hasLimit {
+			return GasLimitPolicy{}, fmt.Errorf("gas limit policy %q requires a limit", s)
+		}
+		var err error
+		policy.Limit, err = strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return GasLimitPolicy{}, fmt.Errorf("invalid gas limit in policy %q: %w", s, err)
+		}
+		return policy, nil
+	default:
+		return GasLimitPolicy{}, fmt.Errorf("unknown gas limit policy %q, expected follow, clamp:<limit> or refuse:<limit>", s)
+	}
+}
+
+func (p GasLimitPolicy) String() string {
+	if p.Mode == "" || p.Mode == GasLimitFollow {
+		return string(GasLimitFollow)
+	}
+	return fmt.Sprintf("%s:%d", p.Mode, p.Limit)
+}
+
+func (p GasLimitPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *GasLimitPolicy) UnmarshalText(text []byte) error {
+	policy, err := ParseGasLimitPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// Target returns the gas limit to target for a validator registered with the given gas
+// limit, or ErrGasLimitRefused if blocks must not be built for it.
+func (p GasLimitPolicy) Target(registeredGasLimit uint64) (uint64, error) {
+	switch p.Mode {
+	case GasLimitClamp:
+		if registeredGasLimit > p.Limit {
+			return p.Limit, nil
+		}
+	case GasLimitRefuse:
+		if registeredGasLimit > p.Limit {
+			return 0, fmt.Errorf("%w: %d above %d", ErrGasLimitRefused, registeredGasLimit, p.Limit)
+		}
+	}
+	return registeredGasLimit, nil
+}
+
+// GasLimitPolicies holds the gas limit policy of each validator registration, by proposer
+// public key, and the policy of the validators without one.
+type GasLimitPolicies struct {
+	Default    GasLimitPolicy            `json:"default"`
+	Validators map[string]GasLimitPolicy `json:"validators,omitempty"`
+}
+
+// NewGasLimitPolicies creates the policies from the default policy and the path of an
+// optional JSON file mapping proposer public keys to their policies.
+func NewGasLimitPolicies(defaultPolicy, path string) (*GasLimitPolicies, error) {
+	policies := &GasLimitPolicies{Default: GasLimitPolicy{Mode: GasLimitFollow}}
+	if defaultPolicy != "" {
+		var err error
+		if policies.Default, err = ParseGasLimitPolicy(defaultPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if path == "" {
+		return policies, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read gas limit policy file: %w", err)
+	}
+	var validators map[string]GasLimitPolicy
+	if err := json.Unmarshal(data, &validators); err != nil {
+		return nil, fmt.Errorf("could not parse gas limit policy file: %w", err)
+	}
+	policies.Validators = make(map[string]GasLimitPolicy, len(validators))
+	for pubkey, policy := range validators {
+		policies.Validators[strings.ToLower(pubkey)] = policy
+	}
+	return policies, nil
+}
+
+// For returns the policy of the validator with the given public key. Nil policies follow
+// the registered gas limit of every validator.
+func (p *GasLimitPolicies) For(pubkey string) GasLimitPolicy {
+	if p == nil {
+		return GasLimitPolicy{Mode: GasLimitFollow}
+	}
+	if policy, ok := p.Validators[strings.ToLower(pubkey)]; ok {
+		return policy
+	}
+	return p.Default
+}
@@ -0,0 +1,69 @@
+package blockvalidation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasLimitPolicy(t *testing.T) {
+	tests := []struct {
+		policy     string
+		registered uint64
+		target     uint64
+		refused    bool
+	}{
+		{policy: "follow", registered: 36_000_000, target: 36_000_000},
+		{policy: "clamp:30000000", registered: 36_000_000, target: 30_000_000},
+		{policy: "clamp:30000000", registered: 20_000_000, target: 20_000_000},
+		{policy: "refuse:30000000", registered: 30_000_000, target: 30_000_000},
+		{policy: "refuse:30000000", registered: 36_000_000, refused: true},
+	}
+	for _, tt := range tests {
+		policy, err := ParseGasLimitPolicy(tt.policy)
+		require.NoError(t, err)
+		require.Equal(t, tt.policy, policy.String())
+
+		target, err := policy.Target(tt.registered)
+		if tt.refused {
+			require.ErrorIs(t, err, ErrGasLimitRefused, tt.policy)
+			continue
+		}
+		require.NoError(t, err, tt.policy)
+		require.Equal(t, tt.target, target, tt.policy)
+	}
+
+	for _, invalid := range []string{"", "follow:1", "clamp", "refuse:many", "raise:1"} {
+		_, err := ParseGasLimitPolicy(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestGasLimitPolicies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"0xAB01": "refuse:30000000", "0xab02": "follow"}`), 0o600))
+
+	policies, err := NewGasLimitPolicies("clamp:30000000", path)
+	require.NoError(t, err)
+	require.Equal(t, GasLimitPolicy{Mode: GasLimitRefuse, Limit: 30_000_000}, policies.For("0xab01"))
+	require.Equal(t, GasLimitPolicy{Mode: GasLimitFollow}, policies.For("0xAB02"))
+	require.Equal(t, GasLimitPolicy{Mode: GasLimitClamp, Limit: 30_000_000}, policies.For("0xab03"))
+
+	enc, err := json.Marshal(policies)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"default":"clamp:30000000","validators":{"0xab01":"refuse:30000000","0xab02":"follow"}}`, string(enc))
+
+	// Without policies, registered gas limits are followed.
+	var none *GasLimitPolicies
+	require.Equal(t, GasLimitPolicy{Mode: GasLimitFollow}, none.For("0xab01"))
+	policies, err = NewGasLimitPolicies("", "")
+	require.NoError(t, err)
+	require.Equal(t, GasLimitPolicy{Mode: GasLimitFollow}, policies.For("0xab01"))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"0xab01": "lower:1"}`), 0o600))
+	_, err = NewGasLimitPolicies("", path)
+	require.Error(t, err)
+}
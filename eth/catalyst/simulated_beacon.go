@@ -19,6 +19,7 @@ package catalyst
 import (
 	"crypto/rand"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -173,8 +174,12 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 	if err != nil {
 		return err
 	}
-	payload := envelope.ExecutionPayload
+	return c.InsertPayload(envelope.ExecutionPayload)
+}
 
+// InsertPayload imports the given payload and marks it as the head of the chain, the
+// way a proposer does with a payload built by someone else, e.g. received from a relay.
+func (c *SimulatedBeacon) InsertPayload(payload *engine.ExecutableData) error {
 	var finalizedHash common.Hash
 	if payload.Number%devEpochLength == 0 {
 		finalizedHash = payload.BlockHash
@@ -187,9 +192,16 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 	}
 
 	// Mark the payload as canon
-	if _, err = c.engineAPI.NewPayloadV2(*payload); err != nil {
+	status, err := c.engineAPI.NewPayloadV2(*payload)
+	if err != nil {
 		return err
 	}
+	if status.Status != engine.VALID {
+		if status.ValidationError != nil {
+			return fmt.Errorf("payload %s rejected: %s", payload.BlockHash, *status.ValidationError)
+		}
+		return fmt.Errorf("payload %s rejected: %s", payload.BlockHash, status.Status)
+	}
 	c.setCurrentState(payload.BlockHash, finalizedHash)
 
 	// Mark the block containing the payload as canonical
@@ -94,6 +94,7 @@ type Config struct {
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	LogIndex           bool   `toml:",omitempty"` // Whether to maintain the index of the log addresses and topics.
 
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
@@ -128,6 +129,10 @@ type Config struct {
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
+	// Whether the full bundles are sent to the newBundles subscribers, rather than
+	// their hints.
+	FilterFullBundles bool
+
 	// Mining options
 	Miner miner.Config
 
@@ -154,6 +159,10 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// TraceExportDir is the directory the traces exported over RPC are confined to.
+	// The export is disabled if empty.
+	TraceExportDir string
+
 	// OverrideCancun (TODO: remove after the fork)
 	OverrideCancun *uint64 `toml:",omitempty"`
 
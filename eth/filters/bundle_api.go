@@ -0,0 +1,223 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+// RPCTxHint is the hint of a bundle transaction: its hash, recipient and the selector
+// of the called function.
+type RPCTxHint struct {
+	Hash             common.Hash     `json:"hash"`
+	To               *common.Address `json:"to,omitempty"`
+	FunctionSelector hexutil.Bytes   `json:"functionSelector,omitempty"`
+}
+
+// RPCBundle is a bundle sent to the newBundles subscribers. Only the hints of the
+// bundles are sent, unless the filter system is configured to send the full bundles:
+// the full mev bundles then have the fields of the eth_sendBundle arguments and the
+// full sbundles are in the format of the mev_sendBundle arguments.
+type RPCBundle struct {
+	Hash           common.Hash    `json:"hash"`
+	BlockNumber    hexutil.Uint64 `json:"blockNumber"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber,omitempty"`
+	Hints          []RPCTxHint    `json:"hints"`
+
+	// Fields of the full mev bundles
+	Txs               []hexutil.Bytes `json:"txs,omitempty"`
+	ReplacementUuid   *uuid.UUID      `json:"replacementUuid,omitempty"`
+	SigningAddress    *common.Address `json:"signingAddress,omitempty"`
+	MinTimestamp      *uint64         `json:"minTimestamp,omitempty"`
+	MaxTimestamp      *uint64         `json:"maxTimestamp,omitempty"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes,omitempty"`
+	TopOfBlock        bool            `json:"topOfBlock,omitempty"`
+
+	// Full sbundle
+	SBundle *ethapi.SendMevBundleArgs `json:"sbundle,omitempty"`
+}
+
+func newTxHint(tx *types.Transaction) RPCTxHint {
+	hint := RPCTxHint{Hash: tx.Hash(), To: tx.To()}
+	if data := tx.Data(); len(data) >= 4 {
+		hint.FunctionSelector = data[:4]
+	}
+	return hint
+}
+
+// newRPCMevBundle returns the notification of a mev bundle.
+func newRPCMevBundle(bundle *types.MevBundle, full bool) (*RPCBundle, error) {
+	res := &RPCBundle{
+		Hash:        bundle.Hash,
+		BlockNumber: hexutil.Uint64(bundle.BlockNumber.Uint64()),
+		Hints:       make([]RPCTxHint, len(bundle.Txs)),
+	}
+	for i, tx := range bundle.Txs {
+		res.Hints[i] = newTxHint(tx)
+	}
+	if !full {
+		return res, nil
+	}
+	res.Txs = make([]hexutil.Bytes, len(bundle.Txs))
+	for i, tx := range bundle.Txs {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res.Txs[i] = data
+	}
+	if bundle.Uuid != types.EmptyUUID {
+		res.ReplacementUuid = &bundle.Uuid
+	}
+	if bundle.SigningAddress != (common.Address{}) {
+		res.SigningAddress = &bundle.SigningAddress
+	}
+	if bundle.MinTimestamp != 0 {
+		res.MinTimestamp = &bundle.MinTimestamp
+	}
+	if bundle.MaxTimestamp != 0 {
+		res.MaxTimestamp = &bundle.MaxTimestamp
+	}
+	res.RevertingTxHashes = bundle.RevertingTxHashes
+	res.TopOfBlock = bundle.TopOfBlock
+	return res, nil
+}
+
+// newRPCSBundle returns the notification of a sbundle, the hints holding the
+// transactions of the sbundle and of its inner bundles.
+func newRPCSBundle(bundle *types.SBundle, full bool) (*RPCBundle, error) {
+	res := &RPCBundle{
+		Hash:        bundle.Hash(),
+		BlockNumber: hexutil.Uint64(bundle.Inclusion.BlockNumber),
+		Hints:       appendSBundleHints(nil, bundle),
+	}
+	if bundle.Inclusion.MaxBlockNumber != bundle.Inclusion.BlockNumber {
+		res.MaxBlockNumber = hexutil.Uint64(bundle.Inclusion.MaxBlockNumber)
+	}
+	if !full {
+		return res, nil
+	}
+	args, err := ethapi.ConvertSBundleToArgs(bundle)
+	if err != nil {
+		return nil, err
+	}
+	res.SBundle = &args
+	return res, nil
+}
+
+func appendSBundleHints(hints []RPCTxHint, bundle *types.SBundle) []RPCTxHint {
+	for _, el := range bundle.Body {
+		if el.Tx != nil {
+			hints = append(hints, newTxHint(el.Tx))
+		}
+		if el.Bundle != nil {
+			hints = appendSBundleHints(hints, el.Bundle)
+		}
+	}
+	return hints
+}
+
+// NewBundles creates a subscription that is triggered each time a bundle or a sbundle
+// enters the bundle pools. Only the hints of the bundles are sent, unless the node is
+// configured to share the full bundles.
+func (api *FilterAPI) NewBundles(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		bundles := make(chan core.NewBundlesEvent, 128)
+		bundlesSub := api.events.SubscribeNewBundles(bundles)
+		defer bundlesSub.Unsubscribe()
+
+		full := api.sys.cfg.FullBundles
+		for {
+			select {
+			case ev := <-bundles:
+				for i := range ev.MevBundles {
+					res, err := newRPCMevBundle(&ev.MevBundles[i], full)
+					if err != nil {
+						log.Warn("Failed to encode bundle notification", "hash", ev.MevBundles[i].Hash, "err", err)
+						continue
+					}
+					notifier.Notify(rpcSub.ID, res)
+				}
+				for _, sbundle := range ev.SBundles {
+					res, err := newRPCSBundle(sbundle, full)
+					if err != nil {
+						log.Warn("Failed to encode sbundle notification", "hash", sbundle.Hash(), "err", err)
+						continue
+					}
+					notifier.Notify(rpcSub.ID, res)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// PendingBlock creates a subscription that is triggered each time the miner seals a
+// candidate block. The block is sent with the hashes of its transactions and its value
+// for the builder.
+func (api *FilterAPI) PendingBlock(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		blocks := make(chan core.NewPendingBlockEvent, pendingBlockChanSize)
+		blocksSub := api.events.SubscribePendingBlocks(blocks)
+		defer blocksSub.Unsubscribe()
+
+		chainConfig := api.sys.backend.ChainConfig()
+
+		for {
+			select {
+			case ev := <-blocks:
+				res := ethapi.RPCMarshalBlock(ev.Block, true, false, chainConfig)
+				res["value"] = (*hexutil.Big)(ev.Profit)
+				notifier.Notify(rpcSub.ID, res)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+// subscribeTest subscribes to the filter API and returns the first notification,
+// sending the event until the subscription is installed.
+func subscribeTest(t *testing.T, sys *FilterSystem, name string, feed *event.Feed, ev interface{}) json.RawMessage {
+	t.Helper()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewFilterAPI(sys, false)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch := make(chan json.RawMessage, 1)
+	sub, err := client.EthSubscribe(ctx, ch, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			feed.Send(ev)
+		case res := <-ch:
+			return res
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("missing notification")
+		}
+	}
+}
+
+func TestNewBundlesSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		to     = common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268")
+		tx     = types.NewTransaction(0, to, new(big.Int), 50000, new(big.Int), common.FromHex("0xa9059cbb0001"))
+		id     = uuid.New()
+		bundle = types.MevBundle{
+			Txs:            types.Transactions{tx},
+			BlockNumber:    big.NewInt(12),
+			Uuid:           id,
+			SigningAddress: common.HexToAddress("0x01"),
+			Hash:           common.HexToHash("0x02"),
+		}
+		sbundle = &types.SBundle{
+			Inclusion: types.BundleInclusion{BlockNumber: 12, MaxBlockNumber: 14},
+			Body:      []types.BundleBody{{Bundle: &types.SBundle{Body: []types.BundleBody{{Tx: tx}}}}},
+		}
+		hints = []RPCTxHint{{Hash: tx.Hash(), To: &to, FunctionSelector: common.FromHex("0xa9059cbb")}}
+	)
+
+	// Only the hints of the bundles are sent by default.
+	backend, sys := newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+	var res RPCBundle
+	if err := json.Unmarshal(subscribeTest(t, sys, "newBundles", &backend.bundlesFeed, core.NewBundlesEvent{MevBundles: []types.MevBundle{bundle}}), &res); err != nil {
+		t.Fatal(err)
+	}
+	want := RPCBundle{Hash: bundle.Hash, BlockNumber: 12, Hints: hints}
+	if !jsonEqual(t, res, want) {
+		t.Fatalf("wrong bundle notification:\nhave %+v\nwant %+v", res, want)
+	}
+
+	// The full bundles are sent when configured.
+	backend, sys = newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{FullBundles: true})
+	res = RPCBundle{}
+	if err := json.Unmarshal(subscribeTest(t, sys, "newBundles", &backend.bundlesFeed, core.NewBundlesEvent{MevBundles: []types.MevBundle{bundle}}), &res); err != nil {
+		t.Fatal(err)
+	}
+	txData, _ := tx.MarshalBinary()
+	want = RPCBundle{
+		Hash:            bundle.Hash,
+		BlockNumber:     12,
+		Hints:           hints,
+		Txs:             []hexutil.Bytes{txData},
+		ReplacementUuid: &id,
+		SigningAddress:  &bundle.SigningAddress,
+	}
+	if !jsonEqual(t, res, want) {
+		t.Fatalf("wrong full bundle notification:\nhave %+v\nwant %+v", res, want)
+	}
+
+	// The hints of sbundles hold the transactions of the inner bundles.
+	res = RPCBundle{}
+	if err := json.Unmarshal(subscribeTest(t, sys, "newBundles", &backend.bundlesFeed, core.NewBundlesEvent{SBundles: []*types.SBundle{sbundle}}), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Hash != sbundle.Hash() || res.BlockNumber != 12 || res.MaxBlockNumber != 14 || !jsonEqual(t, res.Hints, hints) {
+		t.Fatalf("wrong sbundle notification %+v", res)
+	}
+	if res.SBundle == nil || len(res.SBundle.Body) != 1 || res.SBundle.Body[0].Bundle == nil {
+		t.Fatalf("wrong full sbundle %+v", res.SBundle)
+	}
+}
+
+func TestPendingBlockSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, sys = newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+		tx           = types.NewTransaction(0, common.HexToAddress("0x01"), new(big.Int), 50000, new(big.Int), nil)
+		block        = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)}).WithBody([]*types.Transaction{tx}, nil)
+		ev           = core.NewPendingBlockEvent{Block: block, Profit: big.NewInt(1000)}
+	)
+	var res struct {
+		Number       hexutil.Uint64 `json:"number"`
+		Hash         common.Hash    `json:"hash"`
+		Transactions []common.Hash  `json:"transactions"`
+		Value        *hexutil.Big   `json:"value"`
+	}
+	if err := json.Unmarshal(subscribeTest(t, sys, "pendingBlock", &backend.pendingBlkFeed, ev), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Number != 5 || res.Hash != block.Hash() || res.Value.ToInt().Cmp(ev.Profit) != 0 {
+		t.Fatalf("wrong pending block notification %+v", res)
+	}
+	if len(res.Transactions) != 1 || res.Transactions[0] != tx.Hash() {
+		t.Fatalf("wrong pending block transactions %v", res.Transactions)
+	}
+}
+
+func TestPendingBlockSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, sys = newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+		es           = NewEventSystem(sys, false)
+		slow         = es.SubscribePendingBlocks(make(chan core.NewPendingBlockEvent))
+		blocks       = make(chan core.NewPendingBlockEvent, 100)
+		fast         = es.SubscribePendingBlocks(blocks)
+		n            = 3 * pendingBlockChanSize
+	)
+	defer slow.Unsubscribe()
+	defer fast.Unsubscribe()
+
+	// The subscriber never reading its blocks doesn't stall the others.
+	go func() {
+		for i := 0; i < n; i++ {
+			block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))})
+			backend.pendingBlkFeed.Send(core.NewPendingBlockEvent{Block: block, Profit: new(big.Int)})
+		}
+	}()
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-blocks:
+			if ev.Block.NumberU64() != uint64(i) {
+				t.Fatalf("wrong block %d, want %d", ev.Block.NumberU64(), i)
+			}
+		case <-timeout:
+			t.Fatalf("received %d blocks, want %d", i, n)
+		}
+	}
+}
+
+func TestBundlesSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, sys = newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+		es           = NewEventSystem(sys, false)
+		slow         = es.SubscribeNewBundles(make(chan core.NewBundlesEvent))
+		n            = 100
+		bundles      = make(chan core.NewBundlesEvent, n)
+		fast         = es.SubscribeNewBundles(bundles)
+	)
+	defer slow.Unsubscribe()
+	defer fast.Unsubscribe()
+
+	// The subscriber never reading its bundles doesn't stall the others.
+	go func() {
+		for i := 0; i < n; i++ {
+			backend.bundlesFeed.Send(core.NewBundlesEvent{MevBundles: []types.MevBundle{{BlockNumber: big.NewInt(int64(i))}}})
+		}
+	}()
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-bundles:
+			if have := ev.MevBundles[0].BlockNumber.Int64(); have != int64(i) {
+				t.Fatalf("wrong bundle %d, want %d", have, i)
+			}
+		case <-timeout:
+			t.Fatalf("received %d bundles, want %d", i, n)
+		}
+	}
+}
+
+func jsonEqual(t *testing.T, a, b interface{}) bool {
+	t.Helper()
+	ja, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(ja) == string(jb)
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -192,12 +193,24 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			close(logChan)
 		}()
 
-		// Gather all indexed logs, and finish with non indexed ones
+		// Gather the logs of the blocks covered by the log index, then the bloom
+		// indexed logs, and finish with non indexed ones
 		var (
 			end            = uint64(f.end)
-			size, sections = f.sys.backend.BloomStatus()
+			size, sections = f.sys.backend.LogIndexStatus()
 			err            error
 		)
+		if indexed := sections * size; indexed > uint64(f.begin) && f.hasIndexCriteria() {
+			if indexed > end {
+				indexed = end + 1
+			}
+			if err = f.logIndexedLogs(ctx, size, indexed-1, logChan); err != nil {
+				errChan <- err
+				return
+			}
+		}
+
+		size, sections = f.sys.backend.BloomStatus()
 		if indexed := sections * size; indexed > uint64(f.begin) {
 			if indexed > end {
 				indexed = end + 1
@@ -219,6 +232,63 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 	return logChan, errChan
 }
 
+// hasIndexCriteria reports whether the filter has any address or topic the log
+// index can look up, the index being of no use for wildcard filters.
+func (f *Filter) hasIndexCriteria() bool {
+	if len(f.addresses) > 0 {
+		return true
+	}
+	for _, sub := range f.topics {
+		if len(sub) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// logIndexedLogs returns the logs matching the filter criteria based on the log
+// index, only retrieving the blocks the index has logs of the addresses and topics
+// for.
+func (f *Filter) logIndexedLogs(ctx context.Context, size, end uint64, logChan chan *types.Log) error {
+	db := f.sys.backend.ChainDb()
+	for section := uint64(f.begin) / size; section <= end/size; section++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		matches, err := core.LogIndexMatches(db, size, section, f.addresses, f.topics)
+		if err != nil {
+			return err
+		}
+		for _, number := range matches {
+			if number < uint64(f.begin) {
+				continue
+			}
+			if number > end {
+				break
+			}
+			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if header == nil || err != nil {
+				return err
+			}
+			found, err := f.checkMatches(ctx, header)
+			if err != nil {
+				return err
+			}
+			for _, log := range found {
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			f.begin = int64(number) + 1
+		}
+		f.begin = int64((section + 1) * size)
+	}
+	f.begin = int64(end) + 1
+	return nil
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
 func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
@@ -43,6 +43,7 @@ import (
 type Config struct {
 	LogCacheSize int           // maximum number of cached blocks (default: 32)
 	Timeout      time.Duration // how long filters stay active (default: 5min)
+	FullBundles  bool          // whether the full bundles are sent to the newBundles subscribers
 }
 
 func (cfg Config) withDefaults() Config {
@@ -71,8 +72,11 @@ type Backend interface {
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeNewBundlesEvent(ch chan<- core.NewBundlesEvent) event.Subscription
+	SubscribePendingBlockEvent(ch chan<- core.NewPendingBlockEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
+	LogIndexStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
@@ -161,6 +165,10 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// BundlesSubscription queries for bundles entering the bundle pools
+	BundlesSubscription
+	// PendingBlocksSubscription queries for candidate blocks sealed by the miner
+	PendingBlocksSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -175,6 +183,10 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// bundlesChanSize is the size of channel listening to NewBundlesEvent.
+	bundlesChanSize = 256
+	// pendingBlockChanSize is the size of channel listening to NewPendingBlockEvent.
+	pendingBlockChanSize = 10
 )
 
 type subscription struct {
@@ -185,6 +197,8 @@ type subscription struct {
 	logs      chan []*types.Log
 	txs       chan []*types.Transaction
 	headers   chan *types.Header
+	bundles   chan core.NewBundlesEvent
+	blocks    chan core.NewPendingBlockEvent
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -203,15 +217,19 @@ type EventSystem struct {
 	rmLogsSub      event.Subscription // Subscription for removed log event
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
+	bundlesSub     event.Subscription // Subscription for new bundles event
+	pendingBlkSub  event.Subscription // Subscription for new pending block event
 
 	// Channels
-	install       chan *subscription         // install filter for event notification
-	uninstall     chan *subscription         // remove filter for event notification
-	txsCh         chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh        chan []*types.Log          // Channel to receive new log event
-	pendingLogsCh chan []*types.Log          // Channel to receive new log event
-	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh       chan core.ChainEvent       // Channel to receive new chain event
+	install       chan *subscription             // install filter for event notification
+	uninstall     chan *subscription             // remove filter for event notification
+	txsCh         chan core.NewTxsEvent          // Channel to receive new transactions event
+	logsCh        chan []*types.Log              // Channel to receive new log event
+	pendingLogsCh chan []*types.Log              // Channel to receive new log event
+	rmLogsCh      chan core.RemovedLogsEvent     // Channel to receive removed log event
+	chainCh       chan core.ChainEvent           // Channel to receive new chain event
+	bundlesCh     chan core.NewBundlesEvent      // Channel to receive new bundles event
+	pendingBlkCh  chan core.NewPendingBlockEvent // Channel to receive new pending block event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -232,6 +250,8 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 		rmLogsCh:      make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
+		bundlesCh:     make(chan core.NewBundlesEvent, bundlesChanSize),
+		pendingBlkCh:  make(chan core.NewPendingBlockEvent, pendingBlockChanSize),
 	}
 
 	// Subscribe events
@@ -240,9 +260,12 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
+	m.bundlesSub = m.backend.SubscribeNewBundlesEvent(m.bundlesCh)
+	m.pendingBlkSub = m.backend.SubscribePendingBlockEvent(m.pendingBlkCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil ||
+		m.bundlesSub == nil || m.pendingBlkSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -278,6 +301,8 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.logs:
 			case <-sub.f.txs:
 			case <-sub.f.headers:
+			case <-sub.f.bundles:
+			case <-sub.f.blocks:
 			}
 		}
 
@@ -348,6 +373,8 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit ethereum.FilterQuery, logs
 		logs:      logs,
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
+		bundles:   make(chan core.NewBundlesEvent),
+		blocks:    make(chan core.NewPendingBlockEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -365,6 +392,8 @@ func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		logs:      logs,
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
+		bundles:   make(chan core.NewBundlesEvent),
+		blocks:    make(chan core.NewPendingBlockEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -382,6 +411,8 @@ func (es *EventSystem) subscribePendingLogs(crit ethereum.FilterQuery, logs chan
 		logs:      logs,
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
+		bundles:   make(chan core.NewBundlesEvent),
+		blocks:    make(chan core.NewPendingBlockEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -398,6 +429,8 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		logs:      make(chan []*types.Log),
 		txs:       make(chan []*types.Transaction),
 		headers:   headers,
+		bundles:   make(chan core.NewBundlesEvent),
+		blocks:    make(chan core.NewPendingBlockEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -414,6 +447,46 @@ func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subsc
 		logs:      make(chan []*types.Log),
 		txs:       txs,
 		headers:   make(chan *types.Header),
+		bundles:   make(chan core.NewBundlesEvent),
+		blocks:    make(chan core.NewPendingBlockEvent),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeNewBundles creates a subscription that writes the bundles entering the
+// bundle pools. Bundles are dropped while the channel is full, so the channel should
+// be buffered.
+func (es *EventSystem) SubscribeNewBundles(bundles chan core.NewBundlesEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       BundlesSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		headers:   make(chan *types.Header),
+		bundles:   bundles,
+		blocks:    make(chan core.NewPendingBlockEvent),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribePendingBlocks creates a subscription that writes the candidate blocks
+// sealed by the miner. Blocks are dropped while the channel is full, so the channel
+// should be buffered.
+func (es *EventSystem) SubscribePendingBlocks(blocks chan core.NewPendingBlockEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       PendingBlocksSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		headers:   make(chan *types.Header),
+		bundles:   make(chan core.NewBundlesEvent),
+		blocks:    blocks,
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -452,6 +525,30 @@ func (es *EventSystem) handleTxsEvent(filters filterIndex, ev core.NewTxsEvent)
 	}
 }
 
+// handleBundlesEvent delivers the bundles to the subscribers ready to receive them,
+// a slow subscriber misses the bundles rather than stalling the event loop.
+func (es *EventSystem) handleBundlesEvent(filters filterIndex, ev core.NewBundlesEvent) {
+	for _, f := range filters[BundlesSubscription] {
+		select {
+		case f.bundles <- ev:
+		default:
+			log.Debug("Dropped bundles notification", "id", f.id, "bundles", len(ev.MevBundles), "sbundles", len(ev.SBundles))
+		}
+	}
+}
+
+// handlePendingBlockEvent delivers the block to the subscribers ready to receive it,
+// a slow subscriber misses the block rather than stalling the event loop.
+func (es *EventSystem) handlePendingBlockEvent(filters filterIndex, ev core.NewPendingBlockEvent) {
+	for _, f := range filters[PendingBlocksSubscription] {
+		select {
+		case f.blocks <- ev:
+		default:
+			log.Debug("Dropped pending block notification", "id", f.id, "hash", ev.Block.Hash())
+		}
+	}
+}
+
 func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent) {
 	for _, f := range filters[BlocksSubscription] {
 		f.headers <- ev.Block.Header()
@@ -552,6 +649,8 @@ func (es *EventSystem) eventLoop() {
 		es.rmLogsSub.Unsubscribe()
 		es.pendingLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.bundlesSub.Unsubscribe()
+		es.pendingBlkSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -571,6 +670,10 @@ func (es *EventSystem) eventLoop() {
 			es.handlePendingLogs(index, ev)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.bundlesCh:
+			es.handleBundlesEvent(index, ev)
+		case ev := <-es.pendingBlkCh:
+			es.handlePendingBlockEvent(index, ev)
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
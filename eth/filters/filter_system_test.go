@@ -43,15 +43,19 @@ import (
 )
 
 type testBackend struct {
-	db              ethdb.Database
-	sections        uint64
-	txFeed          event.Feed
-	logsFeed        event.Feed
-	rmLogsFeed      event.Feed
-	pendingLogsFeed event.Feed
-	chainFeed       event.Feed
-	pendingBlock    *types.Block
-	pendingReceipts types.Receipts
+	db               ethdb.Database
+	sections         uint64
+	logIndexSize     uint64
+	logIndexSections uint64
+	txFeed           event.Feed
+	logsFeed         event.Feed
+	rmLogsFeed       event.Feed
+	pendingLogsFeed  event.Feed
+	chainFeed        event.Feed
+	bundlesFeed      event.Feed
+	pendingBlkFeed   event.Feed
+	pendingBlock     *types.Block
+	pendingReceipts  types.Receipts
 }
 
 func (b *testBackend) ChainConfig() *params.ChainConfig {
@@ -145,6 +149,14 @@ func (b *testBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Su
 	return b.pendingLogsFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeNewBundlesEvent(ch chan<- core.NewBundlesEvent) event.Subscription {
+	return b.bundlesFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribePendingBlockEvent(ch chan<- core.NewPendingBlockEvent) event.Subscription {
+	return b.pendingBlkFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.chainFeed.Subscribe(ch)
 }
@@ -153,6 +165,10 @@ func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
 
+func (b *testBackend) LogIndexStatus() (uint64, uint64) {
+	return b.logIndexSize, b.logIndexSections
+}
+
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	requests := make(chan chan *bloombits.Retrieval)
 
@@ -388,3 +388,102 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+func TestFiltersLogIndex(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		key, _       = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr         = crypto.PubkeyToAddress(key.PublicKey)
+		signer       = types.NewLondonSigner(big.NewInt(1))
+		// Contracts logging the first word of the calldata as the topic of a LOG1
+		contract1 = common.Address{0xfe}
+		contract2 = common.Address{0xff}
+		bytecode  = common.FromHex("60003560006000a100")
+		topics    = []common.Hash{common.BytesToHash([]byte("topic1")), common.BytesToHash([]byte("topic2")), common.BytesToHash([]byte("topic3"))}
+		gspec     = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr:      {Balance: big.NewInt(0).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+				contract1: {Balance: big.NewInt(0), Code: bytecode},
+				contract2: {Balance: big.NewInt(0), Code: bytecode},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	if _, err := gspec.Commit(db, triedb.NewDatabase(db, nil)); err != nil {
+		t.Fatal(err)
+	}
+	var nonce uint64
+	chain, _ := core.GenerateChain(gspec.Config, gspec.ToBlock(), ethash.NewFaker(), db, 22, func(i int, gen *core.BlockGen) {
+		for j, contract := range []common.Address{contract1, contract2} {
+			if (i+j)%3 == 0 {
+				continue
+			}
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    nonce,
+				GasPrice: gen.BaseFee(),
+				Gas:      30000,
+				To:       &contract,
+				Data:     topics[(i+j)%len(topics)].Bytes(),
+			}), signer, key)
+			gen.AddTx(tx)
+			nonce++
+		}
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	// Index the first five sections of four blocks.
+	indexer := core.NewLogIndexer(db, 4, 0)
+	defer indexer.Close()
+	indexer.Start(bc)
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if sections, _, _ := indexer.Sections(); sections == 5 {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("log index not generated")
+		}
+	}
+
+	// The logs found with the index must be the ones found without it, the index
+	// covering all the blocks or only part of them.
+	for i, tc := range []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+	}{
+		{0, 22, []common.Address{contract1}, nil},
+		{0, 22, []common.Address{contract1, contract2}, nil},
+		{3, 17, nil, [][]common.Hash{{topics[0]}}},
+		{5, 21, []common.Address{contract2}, [][]common.Hash{{topics[1], topics[2]}}},
+		{2, 13, []common.Address{contract1}, [][]common.Hash{nil}},
+		{0, 22, nil, [][]common.Hash{nil, {topics[0]}}},
+		{0, 22, []common.Address{common.BytesToAddress([]byte("failmenow"))}, nil},
+	} {
+		backend.logIndexSize, backend.logIndexSections = 0, 0
+		want, err := sys.NewRangeFilter(tc.begin, tc.end, tc.addresses, tc.topics).Logs(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		for _, sections := range []uint64{2, 5} {
+			backend.logIndexSize, backend.logIndexSections = 4, sections
+			have, err := sys.NewRangeFilter(tc.begin, tc.end, tc.addresses, tc.topics).Logs(context.Background())
+			if err != nil {
+				t.Fatalf("test %d, %d sections: %v", i, sections, err)
+			}
+			haveJSON, _ := json.Marshal(have)
+			wantJSON, _ := json.Marshal(want)
+			if string(haveJSON) != string(wantJSON) {
+				t.Fatalf("test %d, %d sections: have\n%s\nwant\n%s", i, sections, haveJSON, wantJSON)
+			}
+		}
+	}
+}
@@ -91,7 +91,8 @@ type Backend interface {
 
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
 type API struct {
-	backend Backend
+	backend   Backend
+	exportDir string // directory the exports are confined to, empty if disabled
 }
 
 // NewAPI creates a new API definition for the tracing methods of the Ethereum service.
@@ -869,30 +870,57 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 // the trace will be conducted on the state after executing the specified transaction
 // within the specified block.
 func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		// We don't have access to the miner here. For tracing 'future' transactions,
+		// it can be done with block- and state-overrides instead, which offers
+		// more flexibility and stability than trying to trace on 'pending', since
+		// the contents of 'pending' is unstable and probably not a true representation
+		// of what the next actual block is likely to contain.
+		return nil, errors.New("tracing on top of pending is not supported")
+	}
+	statedb, vmctx, release, err := api.callState(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Execute the trace
+	msg, err := args.ToMessage(api.backend.RPCGasCap(), vmctx.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+
+	var traceConfig *TraceConfig
+	if config != nil {
+		traceConfig = &config.TraceConfig
+	}
+	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, traceConfig)
+}
+
+// callState returns the state and the block context to trace calls with, after the
+// given block or one of its transactions, with the overrides of the config applied.
+// On 'pending', calls are traced in the context of the next block on top of the head.
+func (api *API) callState(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (*state.StateDB, vm.BlockContext, StateReleaseFunc, error) {
 	// Try to retrieve the specified block
 	var (
 		err     error
 		block   *types.Block
 		statedb *state.StateDB
 		release StateReleaseFunc
+		pending bool
 	)
 	if hash, ok := blockNrOrHash.Hash(); ok {
 		block, err = api.blockByHash(ctx, hash)
 	} else if number, ok := blockNrOrHash.Number(); ok {
 		if number == rpc.PendingBlockNumber {
-			// We don't have access to the miner here. For tracing 'future' transactions,
-			// it can be done with block- and state-overrides instead, which offers
-			// more flexibility and stability than trying to trace on 'pending', since
-			// the contents of 'pending' is unstable and probably not a true representation
-			// of what the next actual block is likely to contain.
-			return nil, errors.New("tracing on top of pending is not supported")
+			number, pending = rpc.LatestBlockNumber, true
 		}
 		block, err = api.blockByNumber(ctx, number)
 	} else {
-		return nil, errors.New("invalid arguments; neither block nor hash specified")
+		return nil, vm.BlockContext{}, nil, errors.New("invalid arguments; neither block nor hash specified")
 	}
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, nil, err
 	}
 	// try to recompute the state
 	reexec := defaultTraceReexec
@@ -901,34 +929,31 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	}
 
 	if config != nil && config.TxIndex != nil {
+		if pending {
+			return nil, vm.BlockContext{}, nil, errors.New("transaction index is not supported on pending")
+		}
 		_, _, statedb, release, err = api.backend.StateAtTransaction(ctx, block, int(*config.TxIndex), reexec)
 	} else {
 		statedb, release, err = api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
 	}
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, nil, err
 	}
-	defer release()
 
-	vmctx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	header := block.Header()
+	if pending {
+		header = api.nextHeader(header)
+	}
+	vmctx := core.NewEVMBlockContext(header, api.chainContext(ctx), nil)
 	// Apply the customization rules if required.
 	if config != nil {
 		if err := config.StateOverrides.Apply(statedb); err != nil {
-			return nil, err
+			release()
+			return nil, vm.BlockContext{}, nil, err
 		}
 		config.BlockOverrides.Apply(&vmctx)
 	}
-	// Execute the trace
-	msg, err := args.ToMessage(api.backend.RPCGasCap(), vmctx.BaseFee)
-	if err != nil {
-		return nil, err
-	}
-
-	var traceConfig *TraceConfig
-	if config != nil {
-		traceConfig = &config.TraceConfig
-	}
-	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, traceConfig)
+	return statedb, vmctx, release, nil
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
@@ -979,13 +1004,18 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 	return tracer.GetResult()
 }
 
-// APIs return the collection of RPC services the tracer package offers.
-func APIs(backend Backend) []rpc.API {
+// APIs return the collection of RPC services the tracer package offers. The traces
+// exported with debug_exportTraces are confined to exportDir, the export being
+// disabled if empty.
+func APIs(backend Backend, exportDir string) []rpc.API {
+	api := NewAPI(backend)
+	api.exportDir = exportDir
+
 	// Append all the local APIs and return
 	return []rpc.API{
 		{
 			Namespace: "debug",
-			Service:   NewAPI(backend),
+			Service:   api,
 		},
 	}
 }
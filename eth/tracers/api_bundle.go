@@ -0,0 +1,137 @@
+package tracers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// blockTime is the time between the head and the next block traced on 'pending'.
+const blockTime = 12
+
+// TraceCallMany lets you trace a sequence of eth_calls, executed one after the other on
+// the same state. Like TraceCall, the calls are traced on top of the provided block, and
+// the state and block overrides are applied before the first call. On 'pending', the
+// calls are traced in the context of the next block on top of the head.
+func (api *API) TraceCallMany(ctx context.Context, args []ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) ([]*txTraceResult, error) {
+	statedb, vmctx, release, err := api.callState(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	msgs := make([]*core.Message, len(args))
+	for i := range args {
+		if msgs[i], err = args[i].ToMessage(api.backend.RPCGasCap(), vmctx.BaseFee); err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+	}
+	return api.traceSequence(ctx, msgs, make([]common.Hash, len(msgs)), vmctx, statedb, traceConfig(config))
+}
+
+// TraceBundle lets you trace the signed transactions of a bundle, executed one after the
+// other on the same state like the builder does when simulating bundles. The state and
+// block are selected like in TraceCallMany. A transaction failing to apply, for example
+// because of its nonce, is reported in its result and leaves the state unchanged for
+// the next transactions.
+func (api *API) TraceBundle(ctx context.Context, txs []hexutil.Bytes, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) ([]*txTraceResult, error) {
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("bundle missing txs")
+	}
+	statedb, vmctx, release, err := api.callState(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		signer = types.MakeSigner(api.backend.ChainConfig(), vmctx.BlockNumber, vmctx.Time)
+		msgs   = make([]*core.Message, len(txs))
+		hashes = make([]common.Hash, len(txs))
+	)
+	for i, encodedTx := range txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encodedTx); err != nil {
+			return nil, fmt.Errorf("could not decode tx %d: %w", i, err)
+		}
+		if msgs[i], err = core.TransactionToMessage(tx, signer, vmctx.BaseFee); err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i, err)
+		}
+		hashes[i] = tx.Hash()
+	}
+	return api.traceSequence(ctx, msgs, hashes, vmctx, statedb, traceConfig(config))
+}
+
+// traceSequence traces the messages one after the other on the same state. Messages
+// failing to apply are reported in their result.
+func (api *API) traceSequence(ctx context.Context, msgs []*core.Message, hashes []common.Hash, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) ([]*txTraceResult, error) {
+	var (
+		results            = make([]*txTraceResult, len(msgs))
+		deleteEmptyObjects = api.backend.ChainConfig().IsEIP158(vmctx.BlockNumber)
+	)
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		txctx := &Context{
+			BlockNumber: vmctx.BlockNumber,
+			TxIndex:     i,
+			TxHash:      hashes[i],
+		}
+		snapshot := statedb.Snapshot()
+		res, err := api.traceTx(ctx, msg, txctx, vmctx, statedb, config)
+		if err != nil {
+			// Drop the gas bought by messages failing the intrinsic checks
+			statedb.RevertToSnapshot(snapshot)
+			results[i] = &txTraceResult{TxHash: hashes[i], Error: err.Error()}
+			continue
+		}
+		// Finalize the state so any modifications are written to the trie
+		statedb.Finalise(deleteEmptyObjects)
+		results[i] = &txTraceResult{TxHash: hashes[i], Result: res}
+	}
+	return results, nil
+}
+
+// nextHeader returns the header of the block following the given one, as built by a
+// proposer or builder.
+func (api *API) nextHeader(parent *types.Header) *types.Header {
+	config := api.backend.ChainConfig()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + blockTime,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Coinbase:   parent.Coinbase,
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if config.IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if parent.ExcessBlobGas != nil && parent.BlobGasUsed != nil {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		}
+		header.ExcessBlobGas = &excessBlobGas
+	}
+	return header
+}
+
+func traceConfig(config *TraceCallConfig) *TraceConfig {
+	if config == nil {
+		return nil
+	}
+	return &config.TraceConfig
+}
@@ -0,0 +1,100 @@
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceCallManyAndBundle(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		// returns the block number
+		numberer = common.HexToAddress("0x4e")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				numberer:         {Code: common.FromHex("0x4360005260206000f3")},
+			},
+		}
+		genBlocks = 2
+	)
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {})
+	defer backend.teardown()
+	api := NewAPI(backend)
+
+	result := func(t *testing.T, res *txTraceResult) *logger.ExecutionResult {
+		require.Empty(t, res.Error)
+		var execRes logger.ExecutionResult
+		require.NoError(t, json.Unmarshal(res.Result.(json.RawMessage), &execRes))
+		return &execRes
+	}
+
+	// Calls are traced on the state left by the previous calls.
+	value := (*hexutil.Big)(big.NewInt(1000))
+	results, err := api.TraceCallMany(context.Background(), []ethapi.TransactionArgs{
+		{From: &accounts[0].addr, To: &accounts[1].addr, Value: value},
+		{From: &accounts[1].addr, To: &accounts[0].addr, Value: value},
+		{From: &accounts[1].addr, To: &accounts[0].addr, Value: value},
+		{From: &accounts[0].addr, To: &numberer},
+	}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	require.False(t, result(t, results[0]).Failed)
+	require.False(t, result(t, results[1]).Failed)
+	require.Contains(t, results[2].Error, "insufficient funds")
+	require.Equal(t, common.BigToHash(big.NewInt(int64(genBlocks))).Hex()[2:], result(t, results[3]).ReturnValue)
+
+	// On pending, calls are traced in the context of the next block.
+	results, err = api.TraceCallMany(context.Background(), []ethapi.TransactionArgs{
+		{From: &accounts[0].addr, To: &numberer},
+	}, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber), nil)
+	require.NoError(t, err)
+	require.Equal(t, common.BigToHash(big.NewInt(int64(genBlocks+1))).Hex()[2:], result(t, results[0]).ReturnValue)
+
+	// Bundle transactions failing to apply leave the state unchanged.
+	head := backend.chain.CurrentBlock()
+	signer := types.LatestSigner(genesis.Config)
+	tx := func(nonce uint64) hexutil.Bytes {
+		signed, err := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &accounts[1].addr,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: new(big.Int).Mul(head.BaseFee, common.Big2),
+		}), signer, accounts[0].key)
+		require.NoError(t, err)
+		data, err := signed.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+	bundle := []hexutil.Bytes{tx(0), tx(0), tx(1)}
+	results, err = api.TraceBundle(context.Background(), bundle, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.False(t, result(t, results[0]).Failed)
+	require.Contains(t, results[1].Error, "nonce too low")
+	require.False(t, result(t, results[2]).Failed)
+	for i, encoded := range bundle {
+		var decoded types.Transaction
+		require.NoError(t, decoded.UnmarshalBinary(encoded))
+		require.Equal(t, decoded.Hash(), results[i].TxHash)
+	}
+
+	_, err = api.TraceBundle(context.Background(), nil, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil)
+	require.Error(t, err)
+}
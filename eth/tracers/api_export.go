@@ -0,0 +1,348 @@
+package tracers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// defaultExportChunkSize is the number of blocks of an exported trace file.
+	defaultExportChunkSize = uint64(10000)
+
+	// defaultExportTracer is the tracer used to export traces when none is set.
+	defaultExportTracer = "callTracer"
+)
+
+// ExportTracesConfig holds the parameters of the trace export. The traces of the
+// blocks are written to one file per chunk of blocks in Dir, chunks being aligned
+// on multiples of ChunkSize. Dir must be inside the export directory of the node,
+// relative directories being resolved against it.
+type ExportTracesConfig struct {
+	TraceConfig
+	Dir       string
+	ChunkSize *hexutil.Uint64
+}
+
+// TraceRecord is a record of the exported trace files. The callTracer traces are
+// exported with one record per call frame, the frames being ordered depth-first and
+// positioned in the call tree of the transaction by their path. The traces of the
+// other tracers are exported with one record per transaction holding the result.
+// Transactions failing to be traced have a single record with the error.
+type TraceRecord struct {
+	Block   uint64
+	TxIndex uint64
+	TxHash  common.Hash
+	Path    []uint64 // indices of the frame in the calls of its parents, empty for the top call
+	Type    string
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+	Gas     uint64
+	GasUsed uint64
+	Input   []byte
+	Output  []byte
+	Error   string
+	Result  []byte // json-encoded result of the tracers other than the callTracer
+}
+
+// exportCallFrame is the callTracer result decoded for the export.
+type exportCallFrame struct {
+	Type    string            `json:"type"`
+	From    common.Address    `json:"from"`
+	To      common.Address    `json:"to"`
+	Value   *hexutil.Big      `json:"value"`
+	Gas     hexutil.Uint64    `json:"gas"`
+	GasUsed hexutil.Uint64    `json:"gasUsed"`
+	Input   hexutil.Bytes     `json:"input"`
+	Output  hexutil.Bytes     `json:"output"`
+	Error   string            `json:"error"`
+	Calls   []exportCallFrame `json:"calls"`
+}
+
+// exportTracesResult is the notification sent for each chunk of the export.
+type exportTracesResult struct {
+	From    hexutil.Uint64 `json:"from"`
+	To      hexutil.Uint64 `json:"to"`
+	File    string         `json:"file"`
+	Records hexutil.Uint64 `json:"records"`
+	Skipped bool           `json:"skipped,omitempty"` // chunk exported by a previous run
+	Error   string         `json:"error,omitempty"`
+}
+
+// ExportTraces traces the blocks between start and end, both included, and writes
+// the traces to files of length-prefixed RLP TraceRecords, one file per chunk of
+// blocks. A notification is sent for each chunk. The chunks exported by a previous
+// run are skipped, so an interrupted export can be resumed by running it again. The
+// export stops at the first chunk failing to be exported.
+func (api *API) ExportTraces(ctx context.Context, start, end rpc.BlockNumber, config *ExportTracesConfig) (*rpc.Subscription, error) {
+	from, err := api.blockByNumber(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.blockByNumber(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	if from.NumberU64() > to.NumberU64() {
+		return nil, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", end, start)
+	}
+	if to.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	if config == nil {
+		config = new(ExportTracesConfig)
+	}
+	dir, err := api.exportDirectory(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	config.Dir = dir
+	chunkSize := defaultExportChunkSize
+	if config.ChunkSize != nil {
+		chunkSize = uint64(*config.ChunkSize)
+	}
+	if chunkSize == 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+	if config.Tracer == nil {
+		tracer := defaultExportTracer
+		config.Tracer = &tracer
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+	// Exporting a chain is a **long** operation, only do with subscriptions
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	go func() {
+		// The genesis has no transactions to trace
+		first := from.NumberU64()
+		if first == 0 {
+			first = 1
+		}
+		for chunk := first; chunk <= to.NumberU64(); {
+			last := (chunk/chunkSize+1)*chunkSize - 1
+			if last > to.NumberU64() {
+				last = to.NumberU64()
+			}
+			result := api.exportChunk(chunk, last, config, notifier.Closed())
+			notifier.Notify(sub.ID, result)
+			if result.Error != "" {
+				return
+			}
+			select {
+			case <-notifier.Closed():
+				return
+			default:
+			}
+			chunk = last + 1
+		}
+	}()
+	return sub, nil
+}
+
+// exportDirectory resolves the directory of an export. The exports are confined to
+// the export directory of the node, so the callers can't write files elsewhere.
+func (api *API) exportDirectory(dir string) (string, error) {
+	if api.exportDir == "" {
+		return "", errors.New("trace export disabled, no export directory configured")
+	}
+	root, err := filepath.Abs(api.exportDir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	dir = filepath.Clean(dir)
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("export directory %s outside of %s", dir, root)
+	}
+	return dir, nil
+}
+
+// exportChunk exports the traces of the blocks between first and last, both
+// included. The file is written under a temporary name and renamed once complete,
+// so existing files are always complete chunks.
+func (api *API) exportChunk(first, last uint64, config *ExportTracesConfig, closed <-chan interface{}) *exportTracesResult {
+	var (
+		ctx    = context.Background()
+		file   = filepath.Join(config.Dir, fmt.Sprintf("traces-%09d-%09d.rlp", first, last))
+		result = &exportTracesResult{From: hexutil.Uint64(first), To: hexutil.Uint64(last), File: file}
+	)
+	if _, err := os.Stat(file); err == nil {
+		result.Skipped = true
+		return result
+	}
+	records, err := api.writeChunk(ctx, first, last, file, config, closed)
+	if err != nil {
+		log.Warn("Trace export failed", "from", first, "to", last, "err", err)
+		result.Error = err.Error()
+		return result
+	}
+	log.Info("Exported traces", "from", first, "to", last, "records", records, "file", file)
+	result.Records = hexutil.Uint64(records)
+	return result
+}
+
+func (api *API) writeChunk(ctx context.Context, first, last uint64, file string, config *ExportTracesConfig, closed <-chan interface{}) (uint64, error) {
+	start, err := api.blockByNumber(ctx, rpc.BlockNumber(first-1))
+	if err != nil {
+		return 0, err
+	}
+	end, err := api.blockByNumber(ctx, rpc.BlockNumber(last))
+	if err != nil {
+		return 0, err
+	}
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	// Stop the tracing when the subscription is closed or the export fails, the
+	// results still being drained to let the tracing routines exit.
+	var (
+		stop     = make(chan interface{})
+		stopOnce sync.Once
+		abort    = func() { stopOnce.Do(func() { close(stop) }) }
+	)
+	go func() {
+		select {
+		case <-closed:
+			abort()
+		case <-stop:
+		}
+	}()
+	resCh := api.traceChain(start, end, &config.TraceConfig, stop)
+	defer func() {
+		for range resCh {
+		}
+	}()
+	defer abort()
+
+	var (
+		w         = bufio.NewWriter(f)
+		records   uint64
+		callFrame = config.Tracer != nil && *config.Tracer == defaultExportTracer
+		written   = first - 1
+	)
+	for res := range resCh {
+		for i, trace := range res.Traces {
+			if trace == nil {
+				// Not traced after a failing transaction of the block
+				continue
+			}
+			n, err := writeTraceRecords(w, uint64(res.Block), uint64(i), trace, callFrame)
+			if err != nil {
+				return 0, err
+			}
+			records += n
+		}
+		written = uint64(res.Block)
+	}
+	if written != last {
+		return 0, fmt.Errorf("tracing aborted at block %d", written+1)
+	}
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return records, os.Rename(tmp, file)
+}
+
+// writeTraceRecords writes the records of a transaction trace, returning the number
+// of records written.
+func writeTraceRecords(w io.Writer, block, index uint64, trace *txTraceResult, callFrame bool) (uint64, error) {
+	record := TraceRecord{Block: block, TxIndex: index, TxHash: trace.TxHash}
+	if trace.Error != "" || !callFrame {
+		record.Error = trace.Error
+		if res, ok := trace.Result.(json.RawMessage); ok {
+			record.Result = res
+		}
+		return 1, rlp.Encode(w, &record)
+	}
+	res, ok := trace.Result.(json.RawMessage)
+	if !ok {
+		return 0, fmt.Errorf("unexpected callTracer result %T", trace.Result)
+	}
+	var frame exportCallFrame
+	if err := json.Unmarshal(res, &frame); err != nil {
+		return 0, err
+	}
+	return writeFrameRecords(w, record, &frame, nil)
+}
+
+func writeFrameRecords(w io.Writer, record TraceRecord, frame *exportCallFrame, path []uint64) (uint64, error) {
+	record.Path = path
+	record.Type = frame.Type
+	record.From = frame.From
+	record.To = frame.To
+	record.Value = new(big.Int)
+	if frame.Value != nil {
+		record.Value = frame.Value.ToInt()
+	}
+	record.Gas = uint64(frame.Gas)
+	record.GasUsed = uint64(frame.GasUsed)
+	record.Input = frame.Input
+	record.Output = frame.Output
+	record.Error = frame.Error
+	if err := rlp.Encode(w, &record); err != nil {
+		return 0, err
+	}
+	records := uint64(1)
+	for i := range frame.Calls {
+		// The path of the children must not share the array of the parent path
+		child := append(append(make([]uint64, 0, len(path)+1), path...), uint64(i))
+		n, err := writeFrameRecords(w, record, &frame.Calls[i], child)
+		if err != nil {
+			return 0, err
+		}
+		records += n
+	}
+	return records, nil
+}
+
+// ReadTraceRecords reads the records of an exported trace file, calling fn for each
+// record in order.
+func ReadTraceRecords(r io.Reader, fn func(*TraceRecord) error) error {
+	stream := rlp.NewStream(bufio.NewReader(r), 0)
+	for {
+		var record TraceRecord
+		if err := stream.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+}
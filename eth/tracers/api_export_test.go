@@ -0,0 +1,137 @@
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTraces(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	signer := types.HomesteadSigner{}
+	var nonce uint64
+	backend := newTestBackend(t, 10, genesis, func(i int, b *core.BlockGen) {
+		// One transfer in the even blocks
+		if i%2 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+			nonce++
+		}
+	})
+	defer backend.teardown()
+	api := NewAPI(backend)
+	api.exportDir = t.TempDir()
+
+	dir, err := api.exportDirectory("")
+	require.NoError(t, err)
+	config := &ExportTracesConfig{Dir: dir}
+	result := api.exportChunk(1, 8, config, nil)
+	require.Empty(t, result.Error)
+	require.False(t, result.Skipped)
+	require.Equal(t, uint64(4), uint64(result.Records))
+
+	f, err := os.Open(result.File)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []*TraceRecord
+	require.NoError(t, ReadTraceRecords(f, func(record *TraceRecord) error {
+		records = append(records, record)
+		return nil
+	}))
+	require.Len(t, records, 4)
+	for i, record := range records {
+		block := backend.chain.GetBlockByNumber(uint64(2*i + 1))
+		require.Equal(t, block.NumberU64(), record.Block)
+		require.Equal(t, block.Transactions()[0].Hash(), record.TxHash)
+		require.Zero(t, record.TxIndex)
+		require.Contains(t, string(record.Result), `"structLogs"`)
+	}
+
+	// Exported chunks are skipped when the export is resumed.
+	result = api.exportChunk(1, 8, config, nil)
+	require.True(t, result.Skipped)
+	require.Zero(t, result.Records)
+}
+
+func TestExportDirectory(t *testing.T) {
+	t.Parallel()
+
+	api := NewAPI(nil)
+	_, err := api.exportDirectory("traces")
+	require.Error(t, err, "export without export directory")
+
+	root := t.TempDir()
+	api.exportDir = root
+	for _, dir := range []string{"", ".", "traces", "traces/../chain", filepath.Join(root, "traces")} {
+		resolved, err := api.exportDirectory(dir)
+		require.NoError(t, err, dir)
+		rel, err := filepath.Rel(root, resolved)
+		require.NoError(t, err, dir)
+		require.False(t, strings.HasPrefix(rel, ".."), dir)
+	}
+	for _, dir := range []string{"..", "../traces", "traces/../../chain", filepath.Dir(root), "/tmp"} {
+		_, err := api.exportDirectory(dir)
+		require.Error(t, err, dir)
+	}
+}
+
+func TestWriteTraceRecords(t *testing.T) {
+	t.Parallel()
+
+	res := json.RawMessage(`{"type":"CALL","from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002","value":"0x10","gas":"0x100","gasUsed":"0x50","input":"0x01","calls":[{"type":"STATICCALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000003","gas":"0x20","gasUsed":"0x10","input":"0x"},{"type":"CALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000004","gas":"0x20","gasUsed":"0x20","input":"0x","error":"out of gas","calls":[{"type":"CALL","from":"0x0000000000000000000000000000000000000004","to":"0x0000000000000000000000000000000000000005","gas":"0x10","gasUsed":"0x10","input":"0x"}]}]}`)
+	hash := common.HexToHash("0x01")
+
+	var buf bytes.Buffer
+	n, err := writeTraceRecords(&buf, 5, 2, &txTraceResult{TxHash: hash, Result: res}, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), n)
+
+	var records []*TraceRecord
+	require.NoError(t, ReadTraceRecords(&buf, func(record *TraceRecord) error {
+		records = append(records, record)
+		return nil
+	}))
+	require.Len(t, records, 4)
+	for _, record := range records {
+		require.Equal(t, uint64(5), record.Block)
+		require.Equal(t, uint64(2), record.TxIndex)
+		require.Equal(t, hash, record.TxHash)
+	}
+	require.Empty(t, records[0].Path)
+	require.Equal(t, big.NewInt(0x10), records[0].Value)
+	require.Equal(t, []byte{0x01}, records[0].Input)
+	require.Equal(t, []uint64{0}, records[1].Path)
+	require.Equal(t, "STATICCALL", records[1].Type)
+	require.Equal(t, []uint64{1}, records[2].Path)
+	require.Equal(t, "out of gas", records[2].Error)
+	require.Equal(t, []uint64{1, 0}, records[3].Path)
+	require.Equal(t, common.HexToAddress("0x05"), records[3].To)
+
+	// Failed traces are exported with their error.
+	buf.Reset()
+	n, err = writeTraceRecords(&buf, 5, 3, &txTraceResult{TxHash: hash, Error: "execution timeout"}, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), n)
+	require.NoError(t, ReadTraceRecords(&buf, func(record *TraceRecord) error {
+		require.Equal(t, "execution timeout", record.Error)
+		return nil
+	}))
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// TestStateDiffTracer tests the stateDiffTracer on a tx to A, which stores a slot, logs
+// an ERC-20 transfer and calls B with value, B storing a slot and logging an ERC-721
+// transfer, then calls C, which stores a slot and reverts.
+func TestStateDiffTracer(t *testing.T) {
+	var (
+		origin = common.HexToAddress("0x000000000000000000000000000000000000feed")
+		a      = common.HexToAddress("0x000000000000000000000000000000000000000a")
+		b      = common.HexToAddress("0x000000000000000000000000000000000000000b")
+		c      = common.HexToAddress("0x000000000000000000000000000000000000000c")
+		topic  = crypto.Keccak256([]byte("Transfer(address,address,uint256)"))
+		call   = func(to common.Address, value byte) []byte {
+			return []byte{
+				byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), // in and outs zero
+				byte(vm.PUSH1), value, byte(vm.PUSH1), to[19], byte(vm.GAS),
+				byte(vm.CALL), byte(vm.POP),
+			}
+		}
+		codeA = append(append(append(append(append([]byte{
+			byte(vm.PUSH1), 0x1, byte(vm.PUSH1), 0x0, byte(vm.SSTORE),
+			byte(vm.PUSH1), 0x64, byte(vm.PUSH1), 0x0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 0xbb, byte(vm.PUSH1), 0xaa, byte(vm.PUSH32)},
+			topic...),
+			byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x0, byte(vm.LOG3)),
+			call(b, 1)...),
+			call(c, 0)...),
+			byte(vm.STOP))
+		codeB = append(append([]byte{
+			byte(vm.PUSH1), 0x2, byte(vm.PUSH1), 0x1, byte(vm.SSTORE),
+			byte(vm.PUSH1), 0x7, byte(vm.PUSH1), 0xbb, byte(vm.PUSH1), 0xaa, byte(vm.PUSH32)},
+			topic...),
+			byte(vm.PUSH1), 0x0, byte(vm.PUSH1), 0x0, byte(vm.LOG4), byte(vm.STOP))
+		codeC = []byte{
+			byte(vm.PUSH1), 0x5, byte(vm.PUSH1), 0x0, byte(vm.SSTORE),
+			byte(vm.PUSH1), 0x0, byte(vm.PUSH1), 0x0, byte(vm.REVERT),
+		}
+		context = vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			BlockNumber: new(big.Int).SetUint64(8000000),
+			Time:        5,
+			Difficulty:  big.NewInt(0x30000),
+			GasLimit:    uint64(6000000),
+		}
+	)
+	state := tests.MakePreState(rawdb.NewMemoryDatabase(),
+		types.GenesisAlloc{
+			a:      types.Account{Code: codeA},
+			b:      types.Account{Code: codeB},
+			c:      types.Account{Code: codeC},
+			origin: types.Account{Balance: big.NewInt(100)},
+		}, false, rawdb.HashScheme)
+	defer state.Close()
+
+	tracer, err := tracers.DefaultDirectory.New("stateDiffTracer", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create state diff tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, vm.TxContext{Origin: origin, GasPrice: big.NewInt(0)}, state.StateDB, params.MainnetChainConfig, vm.Config{Tracer: tracer})
+	msg := &core.Message{
+		To:        &a,
+		From:      origin,
+		Value:     big.NewInt(10),
+		GasLimit:  200000,
+		GasPrice:  big.NewInt(0),
+		GasFeeCap: big.NewInt(0),
+		GasTipCap: big.NewInt(0),
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(msg.GasLimit))
+	if _, err := st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	want := `{"type":"CALL","from":"0x000000000000000000000000000000000000feed","to":"0x000000000000000000000000000000000000000a","pre":{"0x000000000000000000000000000000000000000a":{"balance":"0x0","storage":{"0x0000000000000000000000000000000000000000000000000000000000000000":"0x0000000000000000000000000000000000000000000000000000000000000000"}},"0x000000000000000000000000000000000000feed":{"balance":"0x64","nonce":"0x0"}},"post":{"0x000000000000000000000000000000000000000a":{"balance":"0xa","storage":{"0x0000000000000000000000000000000000000000000000000000000000000000":"0x0000000000000000000000000000000000000000000000000000000000000001"}},"0x000000000000000000000000000000000000feed":{"balance":"0x5a","nonce":"0x1"}},"transfers":[{"token":"0x000000000000000000000000000000000000000a","from":"0x00000000000000000000000000000000000000aa","to":"0x00000000000000000000000000000000000000bb","value":"0x64"}],"calls":[{"type":"CALL","from":"0x000000000000000000000000000000000000000a","to":"0x000000000000000000000000000000000000000b","pre":{"0x000000000000000000000000000000000000000a":{"balance":"0xa"},"0x000000000000000000000000000000000000000b":{"balance":"0x0","storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000000"}}},"post":{"0x000000000000000000000000000000000000000a":{"balance":"0x9"},"0x000000000000000000000000000000000000000b":{"balance":"0x1","storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000002"}}},"transfers":[{"token":"0x000000000000000000000000000000000000000b","from":"0x00000000000000000000000000000000000000aa","to":"0x00000000000000000000000000000000000000bb","tokenId":"0x7"}]},{"type":"CALL","from":"0x000000000000000000000000000000000000000a","to":"0x000000000000000000000000000000000000000c","error":"execution reverted"}]}`
+	if string(res) != want {
+		t.Errorf("trace mismatch\n have: %v\n want: %v\n", string(res), want)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// tokenTransferTopic is the topic of the ERC-20 and ERC-721 Transfer events.
+var tokenTransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// StateDiffAccount holds the fields of an account changed by a call frame.
+type StateDiffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// TokenTransfer is a decoded Transfer event. ERC-20 transfers have a value, ERC-721
+// transfers have a token id.
+type TokenTransfer struct {
+	Token   common.Address `json:"token"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value,omitempty"`
+	TokenID *hexutil.Big   `json:"tokenId,omitempty"`
+}
+
+// StateDiffFrame is a call frame with the state changes it made itself, the changes
+// of its sub-calls being in their own frames. The changes of reverted frames are
+// dropped.
+type StateDiffFrame struct {
+	Type      string                               `json:"type"`
+	From      common.Address                       `json:"from"`
+	To        common.Address                       `json:"to"`
+	Pre       map[common.Address]*StateDiffAccount `json:"pre,omitempty"`
+	Post      map[common.Address]*StateDiffAccount `json:"post,omitempty"`
+	Transfers []TokenTransfer                      `json:"transfers,omitempty"`
+	Error     string                               `json:"error,omitempty"`
+	Calls     []*StateDiffFrame                    `json:"calls,omitempty"`
+}
+
+func newStateDiffFrame(typ vm.OpCode, from, to common.Address) *StateDiffFrame {
+	return &StateDiffFrame{
+		Type: typ.String(),
+		From: from,
+		To:   to,
+		Pre:  make(map[common.Address]*StateDiffAccount),
+		Post: make(map[common.Address]*StateDiffAccount),
+	}
+}
+
+// accounts returns the pre and post states of the account in the frame.
+func (f *StateDiffFrame) accounts(addr common.Address) (*StateDiffAccount, *StateDiffAccount) {
+	pre, ok := f.Pre[addr]
+	if !ok {
+		pre = &StateDiffAccount{}
+		f.Pre[addr] = pre
+		f.Post[addr] = &StateDiffAccount{}
+	}
+	return pre, f.Post[addr]
+}
+
+// setBalance records a balance change, keeping the balance of the first change as
+// the pre state.
+func (f *StateDiffFrame) setBalance(addr common.Address, before, after *big.Int) {
+	if before.Cmp(after) == 0 {
+		return
+	}
+	pre, post := f.accounts(addr)
+	if pre.Balance == nil {
+		pre.Balance = (*hexutil.Big)(before)
+	}
+	post.Balance = (*hexutil.Big)(after)
+}
+
+func (f *StateDiffFrame) setNonce(addr common.Address, before, after uint64) {
+	pre, post := f.accounts(addr)
+	if pre.Nonce == nil {
+		pre.Nonce = (*hexutil.Uint64)(&before)
+	}
+	post.Nonce = (*hexutil.Uint64)(&after)
+}
+
+func (f *StateDiffFrame) setStorage(addr common.Address, slot common.Hash, before func() common.Hash, after common.Hash) {
+	pre, post := f.accounts(addr)
+	if pre.Storage == nil {
+		pre.Storage = make(map[common.Hash]common.Hash)
+		post.Storage = make(map[common.Hash]common.Hash)
+	}
+	if _, ok := pre.Storage[slot]; !ok {
+		pre.Storage[slot] = before()
+	}
+	post.Storage[slot] = after
+}
+
+// revert drops the changes of the frame and its sub-calls.
+func (f *StateDiffFrame) revert() {
+	f.Pre = make(map[common.Address]*StateDiffAccount)
+	f.Post = make(map[common.Address]*StateDiffAccount)
+	f.Transfers = nil
+	for _, call := range f.Calls {
+		call.revert()
+	}
+}
+
+// StateDiffTracer collects the state changes of transactions, attributed to the call
+// frames making them, and decodes the ERC-20 and ERC-721 Transfer events. The gas
+// payments of the transactions are not part of the changes.
+type StateDiffTracer struct {
+	env *vm.EVM
+	// txs has the root frame of each traced transaction
+	txs       []*StateDiffFrame
+	callstack []*StateDiffFrame
+	interrupt atomic.Bool
+	reason    error
+}
+
+// NewStateDiffTracer creates a new StateDiffTracer. It can trace several transactions,
+// one root frame being collected per transaction.
+func NewStateDiffTracer() *StateDiffTracer {
+	return &StateDiffTracer{}
+}
+
+// Frames returns the root frames of the traced transactions.
+func (t *StateDiffTracer) Frames() []*StateDiffFrame {
+	return t.txs
+}
+
+// GetResult returns the json-encoded root frame of the last traced transaction.
+func (t *StateDiffTracer) GetResult() (json.RawMessage, error) {
+	var root *StateDiffFrame
+	if len(t.txs) > 0 {
+		root = t.txs[len(t.txs)-1]
+	}
+	res, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *StateDiffTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+func (t *StateDiffTracer) CaptureTxStart(uint64) {}
+
+func (t *StateDiffTracer) CaptureTxEnd(uint64) {}
+
+func (t *StateDiffTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, _ []byte, _ uint64, value *big.Int) {
+	t.env = env
+	t.callstack = t.callstack[:0]
+	typ := vm.CALL
+	if create {
+		typ = vm.CREATE
+	}
+	frame := newStateDiffFrame(typ, from, to)
+	t.setSenderNonce(frame)
+	t.enter(frame, typ, value)
+	t.txs = append(t.txs, frame)
+}
+
+func (t *StateDiffTracer) CaptureEnd(_ []byte, _ uint64, err error) {
+	t.exit(err)
+	if err != nil && len(t.txs) > 0 {
+		// The nonce increase of the sender is not reverted
+		t.setSenderNonce(t.txs[len(t.txs)-1])
+	}
+}
+
+// setSenderNonce records the nonce increase of the sender of the transaction, made
+// before the call starts.
+func (t *StateDiffTracer) setSenderNonce(frame *StateDiffFrame) {
+	if nonce := t.env.StateDB.GetNonce(frame.From); nonce > 0 {
+		frame.setNonce(frame.From, nonce-1, nonce)
+	}
+}
+
+func (t *StateDiffTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, _ []byte, _ uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	frame := newStateDiffFrame(typ, from, to)
+	parent := t.callstack[len(t.callstack)-1]
+	if typ == vm.CREATE || typ == vm.CREATE2 {
+		// The nonce of the creator is increased by the parent frame, and isn't
+		// reverted with the creation
+		nonce := t.env.StateDB.GetNonce(from)
+		parent.setNonce(from, nonce-1, nonce)
+	}
+	parent.Calls = append(parent.Calls, frame)
+	t.enter(frame, typ, value)
+}
+
+func (t *StateDiffTracer) CaptureExit(_ []byte, _ uint64, err error) {
+	if t.interrupt.Load() {
+		return
+	}
+	t.exit(err)
+}
+
+// enter pushes the frame, recording the value transfer and the contract creation
+// already applied to the state when the frame starts.
+func (t *StateDiffTracer) enter(frame *StateDiffFrame, typ vm.OpCode, value *big.Int) {
+	t.callstack = append(t.callstack, frame)
+	switch typ {
+	case vm.CREATE, vm.CREATE2:
+		frame.setNonce(frame.To, 0, t.env.StateDB.GetNonce(frame.To))
+	case vm.CALL, vm.SELFDESTRUCT:
+	default:
+		return
+	}
+	if value == nil || value.Sign() == 0 || frame.From == frame.To {
+		return
+	}
+	fromBalance := t.env.StateDB.GetBalance(frame.From).ToBig()
+	frame.setBalance(frame.From, new(big.Int).Add(fromBalance, value), fromBalance)
+	toBalance := t.env.StateDB.GetBalance(frame.To).ToBig()
+	frame.setBalance(frame.To, new(big.Int).Sub(toBalance, value), toBalance)
+}
+
+func (t *StateDiffTracer) exit(err error) {
+	if len(t.callstack) == 0 {
+		return
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	if err != nil {
+		frame.Error = err.Error()
+		frame.revert()
+		return
+	}
+	if frame.Type == vm.CREATE.String() || frame.Type == vm.CREATE2.String() {
+		if code := t.env.StateDB.GetCode(frame.To); len(code) > 0 {
+			_, post := frame.accounts(frame.To)
+			post.Code = code
+		}
+	}
+}
+
+func (t *StateDiffTracer) CaptureState(_ uint64, op vm.OpCode, _, _ uint64, scope *vm.ScopeContext, _ []byte, _ int, err error) {
+	if err != nil || t.interrupt.Load() || len(t.callstack) == 0 {
+		return
+	}
+	var (
+		frame    = t.callstack[len(t.callstack)-1]
+		stack    = scope.Stack
+		stackLen = len(stack.Data())
+		contract = scope.Contract.Address()
+	)
+	switch {
+	case op == vm.SSTORE && stackLen >= 2:
+		slot := common.Hash(stack.Back(0).Bytes32())
+		frame.setStorage(contract, slot, func() common.Hash {
+			return t.env.StateDB.GetState(contract, slot)
+		}, stack.Back(1).Bytes32())
+	case (op == vm.LOG3 || op == vm.LOG4) && stackLen >= int(op-vm.LOG0)+2:
+		if common.Hash(stack.Back(2).Bytes32()) != tokenTransferTopic {
+			return
+		}
+		transfer := TokenTransfer{
+			Token: contract,
+			From:  common.Address(stack.Back(3).Bytes20()),
+			To:    common.Address(stack.Back(4).Bytes20()),
+		}
+		if op == vm.LOG4 {
+			transfer.TokenID = (*hexutil.Big)(stack.Back(5).ToBig())
+		} else {
+			// Tracing happens before the memory expansion of the log, the expanded
+			// memory is zero
+			offset, length := stack.Back(0), stack.Back(1)
+			if length.Uint64() != common.HashLength {
+				return
+			}
+			data := make([]byte, common.HashLength)
+			if offset.IsUint64() && offset.Uint64() < uint64(scope.Memory.Len()) {
+				copy(data, scope.Memory.Data()[offset.Uint64():])
+			}
+			transfer.Value = (*hexutil.Big)(new(big.Int).SetBytes(data))
+		}
+		frame.Transfers = append(frame.Transfers, transfer)
+	}
+}
+
+func (t *StateDiffTracer) CaptureFault(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, int, error) {
+}
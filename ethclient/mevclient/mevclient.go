@@ -0,0 +1,230 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package mevclient provides an RPC client for the bundle and block builder APIs.
+package mevclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The arguments and results of the calls are the types of the server.
+type (
+	SendBundleArgs       = ethapi.SendBundleArgs
+	CallBundleArgs       = ethapi.CallBundleArgs
+	SendMevBundleArgs    = ethapi.SendMevBundleArgs
+	MevBundleInclusion   = ethapi.MevBundleInclusion
+	MevBundleBody        = ethapi.MevBundleBody
+	SimMevBundleAuxArgs  = ethapi.SimMevBundleAuxArgs
+	SimMevBundleResponse = ethapi.SimMevBundleResponse
+	SimMevBundlesResult  = ethapi.SimMevBundlesResult
+
+	BuilderBlockValidationRequest   = blockvalidation.BuilderBlockValidationRequest
+	BuilderBlockValidationRequestV2 = blockvalidation.BuilderBlockValidationRequestV2
+	BuilderBlockValidationRequestV3 = blockvalidation.BuilderBlockValidationRequestV3
+)
+
+// Client is a wrapper around rpc.Client that implements the bundle and block builder
+// APIs of the node.
+type Client struct {
+	c *rpc.Client
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// SendBundle adds a bundle of signed transactions to the bundle pool of the node.
+func (ec *Client) SendBundle(ctx context.Context, args SendBundleArgs) error {
+	return ec.c.CallContext(ctx, nil, "eth_sendBundle", args)
+}
+
+// CallBundleTxResult is the result of a transaction of a bundle simulated with
+// CallBundle.
+type CallBundleTxResult struct {
+	TxHash            common.Hash
+	From              common.Address
+	To                *common.Address // nil for contract creations
+	GasUsed           uint64
+	BlobGasUsed       uint64
+	GasPrice          *big.Int // Effective gas price paid to the coinbase
+	GasFees           *big.Int // Priority fees paid to the coinbase
+	CoinbaseDiff      *big.Int // Balance change of the coinbase
+	EthSentToCoinbase *big.Int // Value transferred to the coinbase
+	Value             []byte   // Return data of the transaction, nil if it failed
+	Error             string   // Execution error of the transaction
+	Revert            string   // Revert reason of the failed transaction
+}
+
+type callBundleTxResultJSON struct {
+	TxHash            common.Hash           `json:"txHash"`
+	FromAddress       common.Address        `json:"fromAddress"`
+	ToAddress         string                `json:"toAddress"`
+	GasUsed           uint64                `json:"gasUsed"`
+	BlobGasUsed       uint64                `json:"blobGasUsed"`
+	GasPrice          *math.HexOrDecimal256 `json:"gasPrice"`
+	GasFees           *math.HexOrDecimal256 `json:"gasFees"`
+	CoinbaseDiff      *math.HexOrDecimal256 `json:"coinbaseDiff"`
+	EthSentToCoinbase *math.HexOrDecimal256 `json:"ethSentToCoinbase"`
+	Value             hexutil.Bytes         `json:"value"`
+	Error             string                `json:"error"`
+	Revert            string                `json:"revert"`
+}
+
+// UnmarshalJSON decodes a transaction result of eth_callBundle, which encodes the
+// amounts as decimal strings and the recipient of contract creations as "0x".
+func (r *CallBundleTxResult) UnmarshalJSON(input []byte) error {
+	var dec callBundleTxResultJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*r = CallBundleTxResult{
+		TxHash:            dec.TxHash,
+		From:              dec.FromAddress,
+		GasUsed:           dec.GasUsed,
+		BlobGasUsed:       dec.BlobGasUsed,
+		GasPrice:          (*big.Int)(dec.GasPrice),
+		GasFees:           (*big.Int)(dec.GasFees),
+		CoinbaseDiff:      (*big.Int)(dec.CoinbaseDiff),
+		EthSentToCoinbase: (*big.Int)(dec.EthSentToCoinbase),
+		Value:             dec.Value,
+		Error:             dec.Error,
+		Revert:            dec.Revert,
+	}
+	if dec.ToAddress != "0x" && dec.ToAddress != "" {
+		var to common.Address
+		if err := to.UnmarshalText([]byte(dec.ToAddress)); err != nil {
+			return err
+		}
+		r.To = &to
+	}
+	return nil
+}
+
+// CallBundleResult is the result of a bundle simulated with CallBundle.
+type CallBundleResult struct {
+	Results           []CallBundleTxResult
+	BundleHash        common.Hash
+	BundleGasPrice    *big.Int
+	GasFees           *big.Int
+	CoinbaseDiff      *big.Int
+	EthSentToCoinbase *big.Int
+	TotalGasUsed      uint64
+	TotalBlobGasUsed  uint64
+	StateBlockNumber  uint64
+}
+
+type callBundleResultJSON struct {
+	Results           []CallBundleTxResult  `json:"results"`
+	BundleHash        common.Hash           `json:"bundleHash"`
+	BundleGasPrice    *math.HexOrDecimal256 `json:"bundleGasPrice"`
+	GasFees           *math.HexOrDecimal256 `json:"gasFees"`
+	CoinbaseDiff      *math.HexOrDecimal256 `json:"coinbaseDiff"`
+	EthSentToCoinbase *math.HexOrDecimal256 `json:"ethSentToCoinbase"`
+	TotalGasUsed      uint64                `json:"totalGasUsed"`
+	TotalBlobGasUsed  uint64                `json:"totalBlobGasUsed"`
+	StateBlockNumber  uint64                `json:"stateBlockNumber"`
+}
+
+// UnmarshalJSON decodes a result of eth_callBundle, which encodes the amounts as
+// decimal strings.
+func (r *CallBundleResult) UnmarshalJSON(input []byte) error {
+	var dec callBundleResultJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*r = CallBundleResult{
+		Results:           dec.Results,
+		BundleHash:        dec.BundleHash,
+		BundleGasPrice:    (*big.Int)(dec.BundleGasPrice),
+		GasFees:           (*big.Int)(dec.GasFees),
+		CoinbaseDiff:      (*big.Int)(dec.CoinbaseDiff),
+		EthSentToCoinbase: (*big.Int)(dec.EthSentToCoinbase),
+		TotalGasUsed:      dec.TotalGasUsed,
+		TotalBlobGasUsed:  dec.TotalBlobGasUsed,
+		StateBlockNumber:  dec.StateBlockNumber,
+	}
+	return nil
+}
+
+// CallBundle simulates a bundle of signed transactions on top of the state of a block.
+func (ec *Client) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	var result CallBundleResult
+	if err := ec.c.CallContext(ctx, &result, "eth_callBundle", args); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SendMevBundle adds a bundle in the format of the matchmaker to the sbundle pool of
+// the node.
+func (ec *Client) SendMevBundle(ctx context.Context, args SendMevBundleArgs) error {
+	return ec.c.CallContext(ctx, nil, "mev_sendBundle", args)
+}
+
+// SimMevBundle simulates a bundle in the format of the matchmaker.
+func (ec *Client) SimMevBundle(ctx context.Context, args SendMevBundleArgs, aux SimMevBundleAuxArgs) (*SimMevBundleResponse, error) {
+	var result SimMevBundleResponse
+	if err := ec.c.CallContext(ctx, &result, "mev_simBundle", args, aux); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SimMevBundles simulates the bundles in order on the same state, each bundle on top
+// of the previous successful ones.
+func (ec *Client) SimMevBundles(ctx context.Context, args []SendMevBundleArgs, aux SimMevBundleAuxArgs) ([]*SimMevBundlesResult, error) {
+	var result []*SimMevBundlesResult
+	if err := ec.c.CallContext(ctx, &result, "mev_simBundles", args, aux); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelMevBundleByHash removes a bundle sent with SendMevBundle from the sbundle pool,
+// together with the bundles depending on it. The node cancels the bundle
+// asynchronously.
+func (ec *Client) CancelMevBundleByHash(ctx context.Context, hash common.Hash) error {
+	return ec.c.CallContext(ctx, nil, "mev_cancelBundleByHash", hash)
+}
+
+// ValidateBuilderSubmissionV1 validates a bellatrix block submitted by a builder. The
+// error of an invalid submission is returned as an rpc.Error.
+func (ec *Client) ValidateBuilderSubmissionV1(ctx context.Context, req *BuilderBlockValidationRequest) error {
+	return ec.c.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV1", req)
+}
+
+// ValidateBuilderSubmissionV2 validates a capella block submitted by a builder. The
+// error of an invalid submission is returned as an rpc.Error.
+func (ec *Client) ValidateBuilderSubmissionV2(ctx context.Context, req *BuilderBlockValidationRequestV2) error {
+	return ec.c.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV2", req)
+}
+
+// ValidateBuilderSubmissionV3 validates a deneb block submitted by a builder. The
+// error of an invalid submission is returned as an rpc.Error.
+func (ec *Client) ValidateBuilderSubmissionV3(ctx context.Context, req *BuilderBlockValidationRequestV3) error {
+	return ec.c.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV3", req)
+}
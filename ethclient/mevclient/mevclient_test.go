@@ -0,0 +1,344 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mevclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+var (
+	testKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr     = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance  = big.NewInt(1e18)
+	testReturner = common.HexToAddress("0xbeef")
+
+	// returnerCode returns the byte 0xbb.
+	returnerCode = common.FromHex("60bb60005360016000f3")
+)
+
+func newTestBackend(t *testing.T) (*node.Node, *eth.Ethereum) {
+	// Generate test chain.
+	genesis := &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: types.GenesisAlloc{
+			testAddr:     {Balance: testBalance},
+			testReturner: {Code: returnerCode},
+		},
+		Timestamp: 9000,
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 1, func(i int, g *core.BlockGen) {
+		g.OffsetTime(5)
+	})
+	// Create node
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	// Create Ethereum Service with the block validation API
+	ethservice, err := eth.New(n, &ethconfig.Config{Genesis: genesis})
+	if err != nil {
+		t.Fatalf("can't create new ethereum service: %v", err)
+	}
+	if err := blockvalidation.Register(n, ethservice, blockvalidation.BlockValidationConfig{}); err != nil {
+		t.Fatalf("can't register block validation API: %v", err)
+	}
+	// Import the test chain.
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("can't import test blocks: %v", err)
+	}
+	return n, ethservice
+}
+
+// signTx signs a transaction of the test account to the returner contract, the tip
+// telling the transactions of the tests apart.
+func signTx(t *testing.T, backend *eth.Ethereum, tip int64) hexutil.Bytes {
+	t.Helper()
+
+	config := backend.BlockChain().Config()
+	baseFee := eip1559.CalcBaseFee(config, backend.BlockChain().CurrentHeader())
+	tx, err := types.SignNewTx(testKey, types.LatestSigner(config), &types.DynamicFeeTx{
+		ChainID:   config.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: new(big.Int).Add(baseFee, big.NewInt(tip)),
+		Gas:       50000,
+		To:        &testReturner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMevClient(t *testing.T) {
+	backend, ethservice := newTestBackend(t)
+	client := backend.Attach()
+	defer backend.Close()
+	defer client.Close()
+
+	ec := New(client)
+	tests := []struct {
+		name string
+		test func(t *testing.T)
+	}{
+		{
+			"TestSendBundle",
+			func(t *testing.T) { testSendBundle(t, ethservice, ec) },
+		}, {
+			"TestCallBundle",
+			func(t *testing.T) { testCallBundle(t, ethservice, ec) },
+		}, {
+			"TestSendMevBundle",
+			func(t *testing.T) { testSendMevBundle(t, ethservice, ec) },
+		}, {
+			"TestSimMevBundle",
+			func(t *testing.T) { testSimMevBundle(t, ethservice, ec) },
+		}, {
+			"TestValidateBuilderSubmission",
+			func(t *testing.T) { testValidateBuilderSubmission(t, ethservice, ec) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, tt.test)
+	}
+}
+
+// waitForBundles waits for the bundles sent by fn to enter the bundle pools.
+func waitForBundles(t *testing.T, backend *eth.Ethereum, fn func() error) core.NewBundlesEvent {
+	t.Helper()
+
+	ch := make(chan core.NewBundlesEvent, 1)
+	sub := backend.TxPool().SubscribeNewBundles(ch)
+	defer sub.Unsubscribe()
+
+	if err := fn(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("bundle not added to the pool")
+	}
+	return core.NewBundlesEvent{}
+}
+
+func testSendBundle(t *testing.T, backend *eth.Ethereum, ec *Client) {
+	tx := signTx(t, backend, 1)
+	ev := waitForBundles(t, backend, func() error {
+		return ec.SendBundle(context.Background(), SendBundleArgs{
+			Txs:         []hexutil.Bytes{tx},
+			BlockNumber: 2,
+		})
+	})
+	if len(ev.MevBundles) != 1 {
+		t.Fatalf("wrong bundles %v", ev.MevBundles)
+	}
+	bundle := ev.MevBundles[0]
+	if bundle.BlockNumber.Uint64() != 2 || len(bundle.Txs) != 1 || bundle.Txs[0].Hash() != crypto.Keccak256Hash(tx) {
+		t.Fatalf("wrong bundle %+v", bundle)
+	}
+
+	// Invalid bundles are rejected by the node.
+	if err := ec.SendBundle(context.Background(), SendBundleArgs{BlockNumber: 2}); err == nil {
+		t.Fatal("empty bundle accepted")
+	}
+}
+
+func testCallBundle(t *testing.T, backend *eth.Ethereum, ec *Client) {
+	tx := signTx(t, backend, 2)
+	res, err := ec.CallBundle(context.Background(), CallBundleArgs{
+		Txs:                    []hexutil.Bytes{tx},
+		BlockNumber:            2,
+		StateBlockNumberOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StateBlockNumber != 1 || res.TotalGasUsed == 0 || len(res.Results) != 1 {
+		t.Fatalf("wrong bundle result %+v", res)
+	}
+	txRes := res.Results[0]
+	if txRes.TxHash != crypto.Keccak256Hash(tx) || txRes.From != testAddr || txRes.To == nil || *txRes.To != testReturner {
+		t.Fatalf("wrong transaction result %+v", txRes)
+	}
+	if txRes.Error != "" || !bytes.Equal(txRes.Value, []byte{0xbb}) || txRes.GasUsed != res.TotalGasUsed {
+		t.Fatalf("wrong transaction execution %+v", txRes)
+	}
+	// The priority fees are paid to the coinbase.
+	fees := new(big.Int).Mul(big.NewInt(2), new(big.Int).SetUint64(txRes.GasUsed))
+	if txRes.GasFees.Cmp(fees) != 0 || txRes.CoinbaseDiff.Cmp(fees) != 0 || res.CoinbaseDiff.Cmp(fees) != 0 {
+		t.Fatalf("wrong fees: have %v, %v, %v, want %v", txRes.GasFees, txRes.CoinbaseDiff, res.CoinbaseDiff, fees)
+	}
+	if txRes.EthSentToCoinbase.Sign() != 0 || res.BundleGasPrice.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("wrong coinbase payment %+v", res)
+	}
+}
+
+func testSendMevBundle(t *testing.T, backend *eth.Ethereum, ec *Client) {
+	tx := signTx(t, backend, 3)
+	ev := waitForBundles(t, backend, func() error {
+		return ec.SendMevBundle(context.Background(), SendMevBundleArgs{
+			Version:   "v0.1",
+			Inclusion: MevBundleInclusion{BlockNumber: 2, MaxBlock: 3},
+			Body:      []MevBundleBody{{Tx: &tx}},
+		})
+	})
+	if len(ev.SBundles) != 1 {
+		t.Fatalf("wrong sbundles %v", ev.SBundles)
+	}
+	hash := ev.SBundles[0].Hash()
+	if pending := backend.TxPool().GetSBundles(big.NewInt(2)); len(pending) != 1 || pending[0].Hash() != hash {
+		t.Fatalf("sbundle not in the pool: %v", pending)
+	}
+
+	// The node cancels the bundles asynchronously.
+	if err := ec.CancelMevBundleByHash(context.Background(), hash); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(backend.TxPool().GetSBundles(big.NewInt(2))) != 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("sbundle not cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func testSimMevBundle(t *testing.T, backend *eth.Ethereum, ec *Client) {
+	tx := signTx(t, backend, 4)
+	bundle := SendMevBundleArgs{
+		Version:   "v0.1",
+		Inclusion: MevBundleInclusion{BlockNumber: 2},
+		Body:      []MevBundleBody{{Tx: &tx}},
+	}
+	res, err := ec.SimMevBundle(context.Background(), bundle, SimMevBundleAuxArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Success || res.StateBlock != 1 || res.GasUsed == 0 {
+		t.Fatalf("wrong simulation %+v", res)
+	}
+
+	// The second bundle reuses the nonce of the first one and fails.
+	results, err := ec.SimMevBundles(context.Background(), []SendMevBundleArgs{bundle, bundle}, SimMevBundleAuxArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !results[0].Success || results[1].Success {
+		t.Fatalf("wrong simulations %+v", results)
+	}
+	if results[0].GasUsed != res.GasUsed {
+		t.Fatalf("wrong gas used: have %d, want %d", results[0].GasUsed, res.GasUsed)
+	}
+}
+
+func testValidateBuilderSubmission(t *testing.T, backend *eth.Ethereum, ec *Client) {
+	// Create an empty block on top of the head.
+	var (
+		config = backend.BlockChain().Config()
+		parent = backend.BlockChain().CurrentHeader()
+		header = &types.Header{
+			ParentHash:      parent.Hash(),
+			UncleHash:       types.EmptyUncleHash,
+			Coinbase:        testAddr,
+			Root:            parent.Root,
+			TxHash:          types.EmptyTxsHash,
+			ReceiptHash:     types.EmptyReceiptsHash,
+			Difficulty:      common.Big0,
+			Number:          big.NewInt(2),
+			GasLimit:        parent.GasLimit,
+			Time:            parent.Time + 12,
+			BaseFee:         eip1559.CalcBaseFee(config, parent),
+			WithdrawalsHash: &types.EmptyWithdrawalsHash,
+		}
+		block = types.NewBlockWithHeader(header).WithWithdrawals([]*types.Withdrawal{})
+	)
+	// The base fee of the payload is little-endian.
+	var baseFee [32]byte
+	header.BaseFee.FillBytes(baseFee[:])
+	for i := 0; i < 16; i++ {
+		baseFee[i], baseFee[31-i] = baseFee[31-i], baseFee[i]
+	}
+	req := &BuilderBlockValidationRequestV2{
+		SubmitBlockRequest: builderApiCapella.SubmitBlockRequest{
+			Message: &builderApiV1.BidTrace{
+				ParentHash: phase0.Hash32(block.ParentHash()),
+				BlockHash:  phase0.Hash32(block.Hash()),
+				GasLimit:   block.GasLimit(),
+				GasUsed:    1, // Wrong, the block is empty
+				Value:      uint256.NewInt(0),
+			},
+			ExecutionPayload: &capella.ExecutionPayload{
+				ParentHash:    phase0.Hash32(block.ParentHash()),
+				FeeRecipient:  bellatrix.ExecutionAddress(block.Coinbase()),
+				StateRoot:     phase0.Root(block.Root()),
+				ReceiptsRoot:  phase0.Root(block.ReceiptHash()),
+				BlockNumber:   block.NumberU64(),
+				GasLimit:      block.GasLimit(),
+				Timestamp:     block.Time(),
+				ExtraData:     []byte{},
+				BaseFeePerGas: baseFee,
+				BlockHash:     phase0.Hash32(block.Hash()),
+				Transactions:  []bellatrix.Transaction{},
+				Withdrawals:   []*capella.Withdrawal{},
+			},
+		},
+		RegisteredGasLimit: block.GasLimit(),
+	}
+	// The validation error is returned by the node.
+	err := ec.ValidateBuilderSubmissionV2(context.Background(), req)
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "incorrect GasUsed 1, expected 0") {
+		t.Fatalf("wrong validation error: %v", err)
+	}
+}
@@ -3,14 +3,16 @@ package flashbotsextra
 import (
 	"context"
 	"math/big"
+	"strconv"
 	"time"
 
 	apiv1 "github.com/attestantio/go-builder-client/api/v1"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 const (
@@ -18,6 +20,14 @@ const (
 	lowPrioLimitSize  = 100
 )
 
+// priorityLimit is the number of bundles of the given priority fetched per block.
+func priorityLimit(isHighPrio bool) int {
+	if isHighPrio {
+		return highPrioLimitSize
+	}
+	return lowPrioLimitSize
+}
+
 type BlockConsumer interface {
 	ConsumeBuiltBlock(block *types.Block, blockValue *big.Int, OrdersClosedAt time.Time, sealedAt time.Time, commitedBundles []types.SimulatedBundle, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle, bidTrace *builderApiV1.BidTrace) error
 }
@@ -26,6 +36,22 @@ type IDatabaseService interface {
 	GetLatestUuidBundles(ctx context.Context, blockNum int64) ([]types.LatestUuidBundle, error)
 }
 
+// BundleNotification is a notification of a bundle inserted in the database, or of a
+// reconnection after which notifications may have been missed.
+type BundleNotification struct {
+	BundleId    uint64
+	Reconnected bool
+	ReceivedAt  time.Time
+}
+
+// IBundleListener is implemented by databases notifying the ids of inserted bundles.
+type IBundleListener interface {
+	// ListenBundles returns the notifications of the bundle ids sent on the given channel,
+	// closed when the context is cancelled.
+	ListenBundles(ctx context.Context, channel string) (<-chan BundleNotification, error)
+	GetBundlesByIds(ctx context.Context, ids []uint64) ([]DbBundle, error)
+}
+
 type NilDbService struct{}
 
 func (NilDbService) ConsumeBuiltBlock(block *types.Block, blockValue *big.Int, OrdersClosedAt time.Time, sealedAt time.Time, commitedBundles []types.SimulatedBundle, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle, bidTrace *apiv1.BidTrace) error {
@@ -41,11 +67,13 @@ func (NilDbService) GetLatestUuidBundles(ctx context.Context, blockNum int64) ([
 }
 
 type DatabaseService struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	dsn string
 
 	insertMissingBundleStmt       *sqlx.NamedStmt
 	fetchPrioBundlesStmt          *sqlx.NamedStmt
 	fetchGetLatestUuidBundlesStmt *sqlx.NamedStmt
+	fetchBundlesByIdsStmt         *sqlx.NamedStmt
 }
 
 func NewDatabaseService(postgresDSN string) (*DatabaseService, error) {
@@ -59,7 +87,7 @@ func NewDatabaseService(postgresDSN string) (*DatabaseService, error) {
 		return nil, err
 	}
 
-	fetchPrioBundlesStmt, err := db.PrepareNamed("select bundle_hash, param_signed_txs, param_block_number, param_timestamp, received_timestamp, param_reverting_tx_hashes, coinbase_diff, total_gas_used, state_block_number, gas_fees, eth_sent_to_coinbase, bundle_uuid from bundles where is_high_prio = :is_high_prio and coinbase_diff*1e18/total_gas_used > 1000000000 and param_block_number = :param_block_number order by coinbase_diff/total_gas_used DESC limit :limit")
+	fetchPrioBundlesStmt, err := db.PrepareNamed("select bundle_hash, param_signed_txs, param_block_number, param_timestamp, received_timestamp, param_reverting_tx_hashes, signing_address, coinbase_diff, total_gas_used, state_block_number, gas_fees, eth_sent_to_coinbase, bundle_uuid from bundles where is_high_prio = :is_high_prio and coinbase_diff*1e18/total_gas_used > 1000000000 and param_block_number = :param_block_number order by coinbase_diff/total_gas_used DESC limit :limit")
 	if err != nil {
 		return nil, err
 	}
@@ -69,11 +97,18 @@ func NewDatabaseService(postgresDSN string) (*DatabaseService, error) {
 		return nil, err
 	}
 
+	fetchBundlesByIdsStmt, err := db.PrepareNamed("select id, bundle_hash, param_signed_txs, param_block_number, param_timestamp, received_timestamp, param_reverting_tx_hashes, signing_address, is_high_prio, coinbase_diff, total_gas_used, state_block_number, gas_fees, eth_sent_to_coinbase, bundle_uuid from bundles where id = any(:ids) and coinbase_diff*1e18/total_gas_used > 1000000000 order by coinbase_diff/total_gas_used DESC")
+	if err != nil {
+		return nil, err
+	}
+
 	return &DatabaseService{
 		db:                            db,
+		dsn:                           postgresDSN,
 		insertMissingBundleStmt:       insertMissingBundleStmt,
 		fetchPrioBundlesStmt:          fetchPrioBundlesStmt,
 		fetchGetLatestUuidBundlesStmt: fetchGetLatestUuidBundlesStmt,
+		fetchBundlesByIdsStmt:         fetchBundlesByIdsStmt,
 	}, nil
 }
 
@@ -86,10 +121,7 @@ func Min(l, r int) int {
 
 func (ds *DatabaseService) GetPriorityBundles(ctx context.Context, blockNum int64, isHighPrio bool) ([]DbBundle, error) {
 	var bundles []DbBundle
-	arg := map[string]interface{}{"param_block_number": uint64(blockNum), "is_high_prio": isHighPrio, "limit": lowPrioLimitSize}
-	if isHighPrio {
-		arg["limit"] = highPrioLimitSize
-	}
+	arg := map[string]interface{}{"param_block_number": uint64(blockNum), "is_high_prio": isHighPrio, "limit": priorityLimit(isHighPrio)}
 	if err := ds.fetchPrioBundlesStmt.SelectContext(ctx, &bundles, arg); err != nil {
 		return nil, err
 	}
@@ -113,3 +145,63 @@ func (ds *DatabaseService) GetLatestUuidBundles(ctx context.Context, blockNum in
 	}
 	return latestBundles, nil
 }
+
+// GetBundlesByIds returns the bundles with the given ids which pay enough to be considered,
+// like GetPriorityBundles, best paying first.
+func (ds *DatabaseService) GetBundlesByIds(ctx context.Context, ids []uint64) ([]DbBundle, error) {
+	dbIds := make([]int64, len(ids))
+	for i, id := range ids {
+		dbIds[i] = int64(id)
+	}
+	var bundles []DbBundle
+	arg := map[string]interface{}{"ids": pq.Array(dbIds)}
+	if err := ds.fetchBundlesByIdsStmt.SelectContext(ctx, &bundles, arg); err != nil {
+		return nil, err
+	}
+	return bundles, nil
+}
+
+// ListenBundles listens on the given channel, on which the id of each inserted bundle is
+// expected to be sent, e.g. by a trigger calling pg_notify(channel, NEW.id::text).
+func (ds *DatabaseService) ListenBundles(ctx context.Context, channel string) (<-chan BundleNotification, error) {
+	listener := pq.NewListener(ds.dsn, 100*time.Millisecond, 10*time.Second, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn("Bundle notification listener error", "event", event, "err", err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	notifications := make(chan BundleNotification, cap(listener.Notify))
+	go func() {
+		defer close(notifications)
+		defer listener.Close()
+		for {
+			select {
+			case n := <-listener.Notify:
+				notification := BundleNotification{ReceivedAt: time.Now()}
+				if n == nil {
+					// Sent after reconnecting, notifications may have been missed
+					notification.Reconnected = true
+				} else {
+					id, err := strconv.ParseUint(n.Extra, 10, 64)
+					if err != nil {
+						log.Warn("Invalid bundle notification", "channel", n.Channel, "payload", n.Extra)
+						continue
+					}
+					notification.BundleId = id
+				}
+				select {
+				case notifications <- notification:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return notifications, nil
+}
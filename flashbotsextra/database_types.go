@@ -42,6 +42,8 @@ type DbBundle struct {
 	ParamTimestamp         *uint64   `db:"param_timestamp"`
 	ReceivedTimestamp      time.Time `db:"received_timestamp"`
 	ParamRevertingTxHashes *string   `db:"param_reverting_tx_hashes"`
+	SigningAddress         *string   `db:"signing_address"`
+	IsHighPrio             bool      `db:"is_high_prio"`
 
 	CoinbaseDiff      string `db:"coinbase_diff"`
 	TotalGasUsed      uint64 `db:"total_gas_used"`
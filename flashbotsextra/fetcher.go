@@ -13,7 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
-	"golang.org/x/crypto/sha3"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 type Fetcher interface {
@@ -26,6 +26,8 @@ type bundleFetcher struct {
 	blockNumCh         chan int64
 	bundlesCh          chan []types.MevBundle
 	shouldPushToTxPool bool // Added for testing
+	sources            []OrderSource
+	notifyChannel      string
 }
 
 func NewBundleFetcher(backend *eth.Ethereum, db IDatabaseService, blockNumCh chan int64, bundlesCh chan []types.MevBundle, shouldPushToTxPool bool) *bundleFetcher {
@@ -38,15 +40,31 @@ func NewBundleFetcher(backend *eth.Ethereum, db IDatabaseService, blockNumCh cha
 	}
 }
 
+// AddOrderSource adds a source of bundles pushed next to the bundles of the database. It must
+// be called before Run.
+func (b *bundleFetcher) AddOrderSource(source OrderSource) {
+	b.sources = append(b.sources, source)
+}
+
+// ListenBundleNotifications fetches bundles as their ids are notified on the given channel of
+// the database instead of polling them, if the database supports notifications. It must be
+// called before Run.
+func (b *bundleFetcher) ListenBundleNotifications(channel string) {
+	b.notifyChannel = channel
+}
+
 func (b *bundleFetcher) Run() {
 	log.Info("Start bundle fetcher")
 	if b.shouldPushToTxPool {
-		eventCh := make(chan core.ChainHeadEvent)
-		b.backend.BlockChain().SubscribeChainHeadEvent(eventCh)
-		pushBlockNum := func() {
-			for currentBlockNum := range eventCh {
-				b.blockNumCh <- currentBlockNum.Block.Header().Number.Int64()
-			}
+		// Head numbers are only consumed by the database source
+		if b.db != nil {
+			eventCh := make(chan core.ChainHeadEvent)
+			b.backend.BlockChain().SubscribeChainHeadEvent(eventCh)
+			go func() {
+				for currentBlockNum := range eventCh {
+					b.blockNumCh <- currentBlockNum.Block.Header().Number.Int64()
+				}
+			}()
 		}
 		addMevBundle := func() {
 			log.Info("Start receiving mev bundles")
@@ -54,13 +72,33 @@ func (b *bundleFetcher) Run() {
 				b.backend.TxPool().AddMevBundles(bundles)
 			}
 		}
-		go pushBlockNum()
 		go addMevBundle()
 	}
-	pushMevBundles := func(bundles []DbBundle) {
+	pushBundles := func(bundles []types.MevBundle) {
+		b.bundlesCh <- bundles
+	}
+	sources := b.sources
+	if b.db != nil {
+		sources = append([]OrderSource{&dbOrderSource{fetcher: b}}, sources...)
+	}
+	for _, source := range sources {
+		go runOrderSource(context.Background(), source, pushBundles)
+	}
+}
+
+// dbOrderSource is the source polling the bundles of the database for the block built on
+// each new head.
+type dbOrderSource struct {
+	fetcher *bundleFetcher
+}
+
+func (s *dbOrderSource) Name() string { return "db" }
+
+func (s *dbOrderSource) Run(ctx context.Context, push func([]types.MevBundle)) error {
+	s.fetcher.fetchAndPush(ctx, func(bundles []DbBundle) {
 		mevBundles := make([]types.MevBundle, 0)
 		for _, bundle := range bundles {
-			mevBundle, err := b.dbBundleToMevBundle(bundle)
+			mevBundle, err := s.fetcher.dbBundleToMevBundle(bundle)
 			if err != nil {
 				log.Error("failed to convert db bundle to mev bundle", "err", err)
 				continue
@@ -68,10 +106,10 @@ func (b *bundleFetcher) Run() {
 			mevBundles = append(mevBundles, *mevBundle)
 		}
 		if len(mevBundles) > 0 {
-			b.bundlesCh <- mevBundles
+			push(mevBundles)
 		}
-	}
-	go b.fetchAndPush(context.Background(), pushMevBundles)
+	})
+	return nil
 }
 
 func (b *bundleFetcher) GetLatestUuidBundles(ctx context.Context, blockNum int64) ([]types.LatestUuidBundle, error) {
@@ -83,43 +121,201 @@ func (b *bundleFetcher) fetchAndPush(ctx context.Context, pushMevBundles func(bu
 	lowPrioBundleTicker := time.NewTicker(time.Second * 2)
 	defer lowPrioBundleTicker.Stop()
 
+	// Notified bundles are pushed within the limits of the polled ones, per block and priority.
+	quota := make(bundleQuota)
+
+	poll := func(isHighPrio bool) {
+		ctxP, cancelP := context.WithTimeout(ctx, time.Second*3)
+		bundles, err := b.db.GetPriorityBundles(ctxP, currentBlockNum+1, isHighPrio)
+		cancelP()
+		if err != nil {
+			log.Error("failed to fetch bundles", "highPrio", isHighPrio, "err", err)
+			return
+		}
+		log.Debug("Fetching bundles", "highPrio", isHighPrio, "size", len(bundles), "currentlyBuiltBlockNum", currentBlockNum+1)
+		quota.record(uint64(currentBlockNum+1), isHighPrio, len(bundles))
+		if len(bundles) != 0 {
+			pushMevBundles(bundles)
+		}
+	}
+
+	// In push mode, bundles are fetched as their ids are notified, and polled only on the
+	// first block and after reconnecting, when notifications may have been missed.
+	notifications := b.listenBundles(ctx)
+	pollOnBlock := true
+
 	for {
 		select {
 		case currentBlockNum = <-b.blockNumCh:
-			ctxH, cancelH := context.WithTimeout(ctx, time.Second*3)
-			bundles, err := b.db.GetPriorityBundles(ctxH, currentBlockNum+1, true)
-			cancelH()
-			if err != nil {
-				log.Error("failed to fetch high prio bundles", "err", err)
+			quota.prune(uint64(currentBlockNum))
+			if notifications != nil && !pollOnBlock {
 				continue
 			}
-			log.Debug("Fetching High prio bundles", "size", len(bundles), "currentlyBuiltBlockNum", currentBlockNum+1)
-			if len(bundles) != 0 {
-				pushMevBundles(bundles)
+			pollOnBlock = false
+			poll(true)
+			if notifications != nil {
+				poll(false)
 			}
 
 		case <-lowPrioBundleTicker.C:
-			if currentBlockNum == 0 {
+			if currentBlockNum == 0 || notifications != nil {
 				continue
 			}
-			ctxL, cancelL := context.WithTimeout(ctx, time.Second*3)
-			bundles, err := b.db.GetPriorityBundles(ctxL, currentBlockNum+1, false)
-			cancelL()
-			if err != nil {
-				log.Error("failed to fetch low prio bundles", "err", err)
+			poll(false)
+
+		case notification, ok := <-notifications:
+			if !ok {
+				log.Warn("Bundle notifications stopped, polling bundles")
+				notifications = nil
 				continue
 			}
-			log.Debug("Fetching low prio bundles", "len", len(bundles), "currentlyBuiltBlockNum", currentBlockNum+1)
-			if len(bundles) != 0 {
-				pushMevBundles(bundles)
+			pending := []BundleNotification{notification}
+		drain:
+			for len(pending) < cap(notifications) {
+				select {
+				case notification, ok := <-notifications:
+					if !ok {
+						break drain
+					}
+					pending = append(pending, notification)
+				default:
+					break drain
+				}
 			}
+			b.pushNotifiedBundles(ctx, pending, currentBlockNum, quota, pushMevBundles, func() {
+				if currentBlockNum == 0 {
+					pollOnBlock = true
+					return
+				}
+				poll(true)
+				poll(false)
+			})
+
 		case <-ctx.Done():
-			close(b.bundlesCh)
 			return
 		}
 	}
 }
 
+// listenBundles returns the bundle notifications of the database in push mode, or nil to
+// poll bundles.
+func (b *bundleFetcher) listenBundles(ctx context.Context) <-chan BundleNotification {
+	if b.notifyChannel == "" {
+		return nil
+	}
+	listener, ok := b.db.(IBundleListener)
+	if !ok {
+		log.Warn("Database does not support bundle notifications, polling bundles")
+		return nil
+	}
+	notifications, err := listener.ListenBundles(ctx, b.notifyChannel)
+	if err != nil {
+		log.Error("failed to listen for bundle notifications, polling bundles", "channel", b.notifyChannel, "err", err)
+		return nil
+	}
+	log.Info("Listening for bundle notifications", "channel", b.notifyChannel)
+	return notifications
+}
+
+// bundleQuotaKey identifies the bundles of a priority targeting a block.
+type bundleQuotaKey struct {
+	blockNum   uint64
+	isHighPrio bool
+}
+
+// bundleQuota counts the bundles pushed per block and priority, so that no more are pushed
+// than GetPriorityBundles would return for them.
+type bundleQuota map[bundleQuotaKey]int
+
+// record accounts for the polled bundles of a block and priority, which are the best paying
+// ones at the time of the poll.
+func (q bundleQuota) record(blockNum uint64, isHighPrio bool, count int) {
+	key := bundleQuotaKey{blockNum, isHighPrio}
+	if count > q[key] {
+		q[key] = count
+	}
+}
+
+// take returns the bundles within the limit of their block and priority, in order, and
+// accounts for them.
+func (q bundleQuota) take(bundles []DbBundle) []DbBundle {
+	taken := make([]DbBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		key := bundleQuotaKey{bundle.ParamBlockNumber, bundle.IsHighPrio}
+		if q[key] >= priorityLimit(bundle.IsHighPrio) {
+			continue
+		}
+		q[key]++
+		taken = append(taken, bundle)
+	}
+	return taken
+}
+
+// prune forgets the blocks up to the current one.
+func (q bundleQuota) prune(currentBlockNum uint64) {
+	for key := range q {
+		if key.blockNum <= currentBlockNum {
+			delete(q, key)
+		}
+	}
+}
+
+// pushNotifiedBundles fetches and pushes the notified bundles targeting blocks after the
+// current one, within the quota of their block and priority, calling repoll if
+// notifications may have been missed.
+func (b *bundleFetcher) pushNotifiedBundles(ctx context.Context, notifications []BundleNotification, currentBlockNum int64, quota bundleQuota, pushMevBundles func(bundles []DbBundle), repoll func()) {
+	var (
+		ids         []uint64
+		reconnected bool
+	)
+	for _, notification := range notifications {
+		if notification.Reconnected {
+			reconnected = true
+		} else {
+			ids = append(ids, notification.BundleId)
+		}
+	}
+	if metrics.EnabledBuilder {
+		bundleNotificationMeter.Mark(int64(len(ids)))
+	}
+	if reconnected {
+		if metrics.EnabledBuilder {
+			bundleNotifyReconnectMeter.Mark(1)
+		}
+		log.Info("Bundle notifications reconnected, polling bundles")
+		repoll()
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	ctxN, cancelN := context.WithTimeout(ctx, time.Second*3)
+	bundles, err := b.db.(IBundleListener).GetBundlesByIds(ctxN, ids)
+	cancelN()
+	if err != nil {
+		log.Error("failed to fetch notified bundles", "count", len(ids), "err", err)
+		return
+	}
+	current := make([]DbBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		if int64(bundle.ParamBlockNumber) > currentBlockNum {
+			current = append(current, bundle)
+		}
+	}
+	current = quota.take(current)
+	log.Debug("Fetching notified bundles", "notified", len(ids), "size", len(current), "currentBlockNum", currentBlockNum)
+	if len(current) != 0 {
+		pushMevBundles(current)
+	}
+	if metrics.EnabledBuilder {
+		for _, notification := range notifications {
+			if !notification.Reconnected {
+				bundleNotifyToPoolTimer.UpdateSince(notification.ReceivedAt)
+			}
+		}
+	}
+}
+
 func (b *bundleFetcher) dbBundleToMevBundle(arg DbBundle) (*types.MevBundle, error) {
 	signedTxsStr := strings.Split(arg.ParamSignedTxs, ",")
 	if len(signedTxsStr) == 0 {
@@ -156,16 +352,18 @@ func (b *bundleFetcher) dbBundleToMevBundle(arg DbBundle) (*types.MevBundle, err
 	if arg.ParamTimestamp != nil {
 		minTimestamp = *arg.ParamTimestamp
 	}
-	bundleHasher := sha3.NewLegacyKeccak256()
-	for _, tx := range txs {
-		bundleHasher.Write(tx.Hash().Bytes())
-	}
-	bundleHash := common.BytesToHash(bundleHasher.Sum(nil))
-	return &types.MevBundle{
+	bundle := &types.MevBundle{
 		Txs:               txs,
 		BlockNumber:       new(big.Int).SetUint64(arg.ParamBlockNumber),
 		MinTimestamp:      minTimestamp,
 		RevertingTxHashes: revertingTxHashes,
-		Hash:              bundleHash,
-	}, nil
+		Hash:              bundleHash(txs),
+	}
+	// The signing address of the bundles in the database was authenticated with the
+	// signature of the request which submitted them.
+	if arg.SigningAddress != nil && common.IsHexAddress(*arg.SigningAddress) {
+		bundle.SigningAddress = common.HexToAddress(*arg.SigningAddress)
+		bundle.SignerAuthenticated = true
+	}
+	return bundle, nil
 }
@@ -0,0 +1,112 @@
+package flashbotsextra
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// notifyingDbService notifies the bundles of a channel and records the polls.
+type notifyingDbService struct {
+	NilDbService
+	notifications chan BundleNotification
+	bundles       map[uint64]DbBundle
+
+	mu    sync.Mutex
+	polls []int64
+}
+
+func (s *notifyingDbService) GetPriorityBundles(ctx context.Context, blockNum int64, isHighPrio bool) ([]DbBundle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polls = append(s.polls, blockNum)
+	return nil, nil
+}
+
+func (s *notifyingDbService) pollCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.polls)
+}
+
+func (s *notifyingDbService) ListenBundles(ctx context.Context, channel string) (<-chan BundleNotification, error) {
+	return s.notifications, nil
+}
+
+func (s *notifyingDbService) GetBundlesByIds(ctx context.Context, ids []uint64) ([]DbBundle, error) {
+	var bundles []DbBundle
+	for _, id := range ids {
+		if bundle, ok := s.bundles[id]; ok {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles, nil
+}
+
+func TestFetchAndPushNotifications(t *testing.T) {
+	db := &notifyingDbService{
+		notifications: make(chan BundleNotification, 8),
+		bundles: map[uint64]DbBundle{
+			1: {DbId: 1, ParamBlockNumber: 11},
+			2: {DbId: 2, ParamBlockNumber: 10},
+		},
+	}
+	blockNumCh := make(chan int64)
+	fetcher := NewBundleFetcher(nil, db, blockNumCh, make(chan []types.MevBundle), false)
+	fetcher.ListenBundleNotifications("bundles")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pushed := make(chan []DbBundle, 8)
+	go fetcher.fetchAndPush(ctx, func(bundles []DbBundle) { pushed <- bundles })
+
+	// Bundles are polled on the first block only.
+	blockNumCh <- 10
+	blockNumCh <- 11
+	require.Equal(t, 2, db.pollCount())
+
+	// Notified bundles are pushed, unless they target past blocks.
+	blockNumCh <- 10
+	db.notifications <- BundleNotification{BundleId: 1, ReceivedAt: time.Now()}
+	db.notifications <- BundleNotification{BundleId: 2, ReceivedAt: time.Now()}
+	select {
+	case bundles := <-pushed:
+		require.Len(t, bundles, 1)
+		require.Equal(t, uint64(1), bundles[0].DbId)
+	case <-time.After(time.Second):
+		t.Fatal("missing notified bundles")
+	}
+
+	// Bundles are polled again after reconnecting.
+	db.notifications <- BundleNotification{Reconnected: true, ReceivedAt: time.Now()}
+	require.Eventually(t, func() bool { return db.pollCount() == 4 }, time.Second, 10*time.Millisecond)
+	require.Empty(t, pushed)
+}
+
+func TestBundleQuota(t *testing.T) {
+	quota := make(bundleQuota)
+	quota.record(11, true, highPrioLimitSize-1)
+	quota.record(11, false, lowPrioLimitSize)
+
+	bundles := []DbBundle{
+		{DbId: 1, ParamBlockNumber: 11, IsHighPrio: true},
+		{DbId: 2, ParamBlockNumber: 11, IsHighPrio: true},
+		{DbId: 3, ParamBlockNumber: 11},
+		{DbId: 4, ParamBlockNumber: 12},
+	}
+	taken := quota.take(bundles)
+	require.Len(t, taken, 2)
+	require.Equal(t, uint64(1), taken[0].DbId)
+	require.Equal(t, uint64(4), taken[1].DbId)
+
+	// A poll after the notified bundles does not lower the count.
+	quota.record(12, false, 0)
+	require.Equal(t, 1, quota[bundleQuotaKey{12, false}])
+
+	quota.prune(11)
+	require.Len(t, quota, 1)
+}
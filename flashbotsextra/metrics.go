@@ -0,0 +1,11 @@
+package flashbotsextra
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	bundleNotificationMeter    = metrics.NewRegisteredMeter("flashbotsextra/bundles/notify", nil)
+	bundleNotifyReconnectMeter = metrics.NewRegisteredMeter("flashbotsextra/bundles/notify/reconnect", nil)
+	bundleNotifyToPoolTimer    = metrics.NewRegisteredTimer("flashbotsextra/bundles/notify/latency", nil)
+)
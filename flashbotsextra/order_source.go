@@ -0,0 +1,233 @@
+package flashbotsextra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/time/rate"
+)
+
+// OrderSource is a source of bundles for the bundle pool, such as the bundle database, a
+// replay file or an upstream order flow endpoint.
+type OrderSource interface {
+	// Name identifies the source in logs and metrics.
+	Name() string
+	// Run receives bundles from the source and passes them to push until the context is
+	// cancelled or the source is exhausted.
+	Run(ctx context.Context, push func([]types.MevBundle)) error
+}
+
+// OrderSourceBundle is a bundle as received from order sources, in the format of the
+// arguments of eth_sendBundle.
+type OrderSourceBundle struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	ReplacementUuid   *uuid.UUID      `json:"replacementUuid,omitempty"`
+	SigningAddress    *common.Address `json:"signingAddress,omitempty"`
+	MinTimestamp      *uint64         `json:"minTimestamp,omitempty"`
+	MaxTimestamp      *uint64         `json:"maxTimestamp,omitempty"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes,omitempty"`
+	TopOfBlock        bool            `json:"topOfBlock,omitempty"`
+}
+
+// MevBundle decodes the transactions of the bundle into a bundle of the pool.
+func (b *OrderSourceBundle) MevBundle() (*types.MevBundle, error) {
+	if len(b.Txs) == 0 {
+		return nil, errors.New("bundle missing txs")
+	}
+	if b.BlockNumber == 0 {
+		return nil, errors.New("bundle missing blockNumber")
+	}
+
+	txs := make(types.Transactions, 0, len(b.Txs))
+	for i, encodedTx := range b.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encodedTx); err != nil {
+			return nil, fmt.Errorf("could not decode bundle tx %d: %w", i, err)
+		}
+		txs = append(txs, tx)
+	}
+	bundle := &types.MevBundle{
+		Txs:               txs,
+		BlockNumber:       new(big.Int).SetUint64(uint64(b.BlockNumber)),
+		RevertingTxHashes: b.RevertingTxHashes,
+		Hash:              bundleHash(txs),
+		TopOfBlock:        b.TopOfBlock,
+	}
+	if b.ReplacementUuid != nil {
+		bundle.Uuid = *b.ReplacementUuid
+	}
+	if b.SigningAddress != nil {
+		bundle.SigningAddress = *b.SigningAddress
+	}
+	if b.MinTimestamp != nil {
+		bundle.MinTimestamp = *b.MinTimestamp
+	}
+	if b.MaxTimestamp != nil {
+		bundle.MaxTimestamp = *b.MaxTimestamp
+	}
+	return bundle, nil
+}
+
+// bundleHash is the hash of the bundle pool, over the hashes of the bundle transactions.
+func bundleHash(txs types.Transactions) common.Hash {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, tx := range txs {
+		hasher.Write(tx.Hash().Bytes())
+	}
+	return common.BytesToHash(hasher.Sum(nil))
+}
+
+// orderSourceMeter returns the meter of the given kind of the named source.
+func orderSourceMeter(name, kind string) metrics.Meter {
+	if !metrics.EnabledBuilder {
+		return metrics.NilMeter{}
+	}
+	return metrics.GetOrRegisterMeter(fmt.Sprintf("flashbotsextra/ordersource/%s/%s", name, kind), nil)
+}
+
+// runOrderSource runs the source until it is exhausted or the context is cancelled,
+// counting the bundles it pushes.
+func runOrderSource(ctx context.Context, source OrderSource, push func([]types.MevBundle)) {
+	bundlesMeter := orderSourceMeter(source.Name(), "bundles")
+	log.Info("Start order source", "source", source.Name())
+	err := source.Run(ctx, func(bundles []types.MevBundle) {
+		bundlesMeter.Mark(int64(len(bundles)))
+		push(bundles)
+	})
+	if err != nil && ctx.Err() == nil {
+		orderSourceMeter(source.Name(), "errors").Mark(1)
+		log.Error("Order source failed", "source", source.Name(), "err", err)
+		return
+	}
+	log.Info("Order source stopped", "source", source.Name())
+}
+
+// OrderSourceLimits limit the rate of bundles accepted from a source.
+type OrderSourceLimits struct {
+	// Rate is the number of bundles accepted per second, zero for no limit
+	Rate float64
+	// Burst is the number of bundles accepted at once, at least one
+	Burst int
+}
+
+type limitedOrderSource struct {
+	OrderSource
+	limiter *rate.Limiter
+}
+
+// NewLimitedOrderSource limits the rate of bundles accepted from the source, dropping the
+// bundles above the limits.
+func NewLimitedOrderSource(source OrderSource, limits OrderSourceLimits) OrderSource {
+	if limits.Rate <= 0 {
+		return source
+	}
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	return &limitedOrderSource{
+		OrderSource: source,
+		limiter:     rate.NewLimiter(rate.Limit(limits.Rate), limits.Burst),
+	}
+}
+
+func (s *limitedOrderSource) Run(ctx context.Context, push func([]types.MevBundle)) error {
+	rateLimitedMeter := orderSourceMeter(s.Name(), "ratelimited")
+	return s.OrderSource.Run(ctx, func(bundles []types.MevBundle) {
+		allowed := make([]types.MevBundle, 0, len(bundles))
+		for _, bundle := range bundles {
+			if s.limiter.Allow() {
+				allowed = append(allowed, bundle)
+			}
+		}
+		if dropped := len(bundles) - len(allowed); dropped > 0 {
+			rateLimitedMeter.Mark(int64(dropped))
+			log.Debug("Order source rate limited", "source", s.Name(), "dropped", dropped)
+		}
+		if len(allowed) > 0 {
+			push(allowed)
+		}
+	})
+}
+
+// NewOrderSource creates an order source from its URL:
+//
+//	jsonl:<path>           bundles read from a JSON lines file, or stdin with "-"
+//	ws://..., wss://...    bundles of a newBundles subscription of an upstream endpoint
+//
+// Upstream geth nodes must run with --rpc.fullbundles for WebSocket sources, as they
+// otherwise only send the hints of the bundles, which are dropped.
+//
+// The query parameters "name", "rate" and "burst" set the name of the source in metrics
+// and its limits, and "token_file" the file holding a bearer token for WebSocket sources.
+// They are removed from the URL before connecting.
+func NewOrderSource(rawurl string) (OrderSource, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	name := query.Get("name")
+	if name == "" {
+		name = u.Scheme
+	}
+	var limits OrderSourceLimits
+	if s := query.Get("rate"); s != "" {
+		if limits.Rate, err = strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("invalid rate %q: %w", s, err)
+		}
+	}
+	if s := query.Get("burst"); s != "" {
+		if limits.Burst, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("invalid burst %q: %w", s, err)
+		}
+	}
+	tokenFile := query.Get("token_file")
+	for _, key := range []string{"name", "rate", "burst", "token_file"} {
+		query.Del(key)
+	}
+	u.RawQuery = query.Encode()
+
+	var source OrderSource
+	switch u.Scheme {
+	case "jsonl":
+		if tokenFile != "" {
+			return nil, errors.New("token_file is only supported by WebSocket sources")
+		}
+		path := u.Opaque
+		if path == "" {
+			path = u.Path
+		}
+		if path == "" {
+			return nil, errors.New("missing JSON lines path")
+		}
+		source = NewJSONLinesSource(name, path)
+	case "ws", "wss":
+		header := make(http.Header)
+		if tokenFile != "" {
+			token, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not read token file: %w", err)
+			}
+			header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+		}
+		source = NewWebSocketSource(name, u.String(), header)
+	default:
+		return nil, fmt.Errorf("unsupported order source scheme %q, expected jsonl, ws or wss", u.Scheme)
+	}
+	return NewLimitedOrderSource(source, limits), nil
+}
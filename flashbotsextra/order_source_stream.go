@@ -0,0 +1,182 @@
+package flashbotsextra
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// jsonLinesMaxLineSize is the size of the largest bundle read from JSON lines sources
+	jsonLinesMaxLineSize = 16 * 1024 * 1024
+
+	webSocketReconnectDelay = 5 * time.Second
+)
+
+// JSONLinesSource reads bundles from a file or stdin holding a JSON bundle per line, as
+// used to replay recorded order flow.
+type JSONLinesSource struct {
+	name string
+	path string
+}
+
+// NewJSONLinesSource creates a source reading the JSON lines file at the path, or stdin if
+// the path is "-".
+func NewJSONLinesSource(name, path string) *JSONLinesSource {
+	return &JSONLinesSource{name: name, path: path}
+}
+
+func (s *JSONLinesSource) Name() string { return s.name }
+
+func (s *JSONLinesSource) Run(ctx context.Context, push func([]types.MevBundle)) error {
+	var r io.Reader = os.Stdin
+	if s.path != "-" {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	return s.read(ctx, r, push)
+}
+
+func (s *JSONLinesSource) read(ctx context.Context, r io.Reader, push func([]types.MevBundle)) error {
+	invalidMeter := orderSourceMeter(s.name, "invalid")
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, jsonLinesMaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var args OrderSourceBundle
+		if err := json.Unmarshal(data, &args); err != nil {
+			invalidMeter.Mark(1)
+			log.Warn("Invalid bundle in order source", "source", s.name, "line", line, "err", err)
+			continue
+		}
+		bundle, err := args.MevBundle()
+		if err != nil {
+			invalidMeter.Mark(1)
+			log.Warn("Invalid bundle in order source", "source", s.name, "line", line, "err", err)
+			continue
+		}
+		push([]types.MevBundle{*bundle})
+	}
+	return scanner.Err()
+}
+
+// WebSocketSource receives bundles from the newBundles subscription of an upstream order
+// flow endpoint, reconnecting when the connection is lost. Upstream geth nodes only send
+// the hints of the bundles unless they run with --rpc.fullbundles.
+type WebSocketSource struct {
+	name   string
+	url    string
+	header http.Header
+
+	reconnectDelay time.Duration
+	hintsLogged    bool // set once hint-only notifications have been reported
+}
+
+// NewWebSocketSource creates a source subscribing to the endpoint at the URL, sending the
+// given headers, e.g. for authentication, when connecting.
+func NewWebSocketSource(name, url string, header http.Header) *WebSocketSource {
+	return &WebSocketSource{
+		name:           name,
+		url:            url,
+		header:         header,
+		reconnectDelay: webSocketReconnectDelay,
+	}
+}
+
+// webSocketBundle is a notification of the newBundles subscription. The sbundles sent
+// by the subscriptions of geth nodes aren't supported by order sources.
+type webSocketBundle struct {
+	OrderSourceBundle
+	Hints   json.RawMessage `json:"hints,omitempty"`
+	SBundle json.RawMessage `json:"sbundle,omitempty"`
+}
+
+// hintOnly reports whether the notification holds the hints of a bundle but not the
+// bundle itself, as sent by nodes not running with --rpc.fullbundles.
+func (b *webSocketBundle) hintOnly() bool {
+	return b.Hints != nil && len(b.Txs) == 0 && b.SBundle == nil
+}
+
+func (s *WebSocketSource) Name() string { return s.name }
+
+func (s *WebSocketSource) Run(ctx context.Context, push func([]types.MevBundle)) error {
+	errorsMeter := orderSourceMeter(s.name, "errors")
+	for {
+		err := s.subscribe(ctx, push)
+		if ctx.Err() != nil {
+			return nil
+		}
+		errorsMeter.Mark(1)
+		log.Warn("Order source subscription lost, reconnecting", "source", s.name, "err", err, "delay", s.reconnectDelay)
+
+		select {
+		case <-time.After(s.reconnectDelay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *WebSocketSource) subscribe(ctx context.Context, push func([]types.MevBundle)) error {
+	client, err := rpc.DialOptions(ctx, s.url, rpc.WithHeaders(s.header))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	bundlesCh := make(chan *webSocketBundle, 64)
+	sub, err := client.EthSubscribe(ctx, bundlesCh, "newBundles")
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	log.Info("Subscribed to order source", "source", s.name)
+
+	invalidMeter := orderSourceMeter(s.name, "invalid")
+	for {
+		select {
+		case args := <-bundlesCh:
+			if args.SBundle != nil {
+				continue
+			}
+			if args.hintOnly() {
+				invalidMeter.Mark(1)
+				if !s.hintsLogged {
+					s.hintsLogged = true
+					log.Error("Order source sends bundle hints without txs, the upstream node must run with --rpc.fullbundles", "source", s.name)
+				}
+				continue
+			}
+			bundle, err := args.MevBundle()
+			if err != nil {
+				invalidMeter.Mark(1)
+				log.Warn("Invalid bundle in order source", "source", s.name, "err", err)
+				continue
+			}
+			push([]types.MevBundle{*bundle})
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package flashbotsextra
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrderSourceBundle(t *testing.T, blockNumber uint64, nonces ...uint64) OrderSourceBundle {
+	bundle := OrderSourceBundle{BlockNumber: hexutil.Uint64(blockNumber)}
+	for _, nonce := range nonces {
+		encodedTx, err := simpleTx(nonce).MarshalBinary()
+		require.NoError(t, err)
+		bundle.Txs = append(bundle.Txs, encodedTx)
+	}
+	return bundle
+}
+
+func TestNewOrderSource(t *testing.T) {
+	source, err := NewOrderSource("jsonl:-")
+	require.NoError(t, err)
+	require.Equal(t, &JSONLinesSource{name: "jsonl", path: "-"}, source)
+
+	source, err = NewOrderSource("jsonl:/tmp/bundles.jsonl?name=replay&rate=10&burst=5")
+	require.NoError(t, err)
+	limited, ok := source.(*limitedOrderSource)
+	require.True(t, ok)
+	require.Equal(t, &JSONLinesSource{name: "replay", path: "/tmp/bundles.jsonl"}, limited.OrderSource)
+	require.Equal(t, 5, limited.limiter.Burst())
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0o600))
+	source, err = NewOrderSource("wss://orders.example.com/ws?key=1&token_file=" + tokenFile)
+	require.NoError(t, err)
+	ws, ok := source.(*WebSocketSource)
+	require.True(t, ok)
+	require.Equal(t, "wss", ws.Name())
+	require.Equal(t, "wss://orders.example.com/ws?key=1", ws.url)
+	require.Equal(t, "Bearer secret", ws.header.Get("Authorization"))
+
+	for _, invalid := range []string{"http://orders.example.com", "jsonl:", "jsonl:-?rate=fast", "jsonl:-?token_file=" + tokenFile} {
+		_, err := NewOrderSource(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestJSONLinesSource(t *testing.T) {
+	var lines []string
+	for _, bundle := range []OrderSourceBundle{testOrderSourceBundle(t, 10, 0, 1), testOrderSourceBundle(t, 0, 2), testOrderSourceBundle(t, 11, 3)} {
+		data, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		lines = append(lines, string(data))
+	}
+	lines = append(lines, "", "not json")
+	path := filepath.Join(t.TempDir(), "bundles.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+
+	// Invalid lines are skipped.
+	var bundles []types.MevBundle
+	err := NewJSONLinesSource("replay", path).Run(context.Background(), func(pushed []types.MevBundle) {
+		bundles = append(bundles, pushed...)
+	})
+	require.NoError(t, err)
+	require.Len(t, bundles, 2)
+	require.Equal(t, uint64(10), bundles[0].BlockNumber.Uint64())
+	require.Len(t, bundles[0].Txs, 2)
+	require.Equal(t, bundleHash(bundles[0].Txs), bundles[0].Hash)
+	require.Equal(t, uint64(11), bundles[1].BlockNumber.Uint64())
+
+	// The limits of the source drop the bundles above the burst.
+	bundles = nil
+	source := NewLimitedOrderSource(NewJSONLinesSource("replay", path), OrderSourceLimits{Rate: 0.001, Burst: 1})
+	require.NoError(t, source.Run(context.Background(), func(pushed []types.MevBundle) {
+		bundles = append(bundles, pushed...)
+	}))
+	require.Len(t, bundles, 1)
+	require.Equal(t, uint64(10), bundles[0].BlockNumber.Uint64())
+}
+
+// testOrderFlowService is an upstream order flow endpoint sending the bundles of a channel
+// to newBundles subscribers.
+type testOrderFlowService struct {
+	bundles chan OrderSourceBundle
+}
+
+func (s *testOrderFlowService) NewBundles(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case bundle := <-s.bundles:
+				notifier.Notify(sub.ID, bundle)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func TestWebSocketSource(t *testing.T) {
+	service := &testOrderFlowService{bundles: make(chan OrderSourceBundle)}
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("eth", service))
+
+	wsHandler := server.WebsocketHandler([]string{"*"})
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		wsHandler.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan []types.MevBundle, 1)
+	source := NewWebSocketSource("upstream", url, http.Header{"Authorization": {"Bearer secret"}})
+	source.reconnectDelay = 10 * time.Millisecond
+	done := make(chan error, 1)
+	go func() { done <- source.Run(ctx, func(bundles []types.MevBundle) { received <- bundles }) }()
+
+	select {
+	case service.bundles <- testOrderSourceBundle(t, 12, 0):
+	case <-time.After(5 * time.Second):
+		t.Fatal("source did not subscribe")
+	}
+	select {
+	case bundles := <-received:
+		require.Len(t, bundles, 1)
+		require.Equal(t, uint64(12), bundles[0].BlockNumber.Uint64())
+		require.Equal(t, simpleTx(0).Hash(), bundles[0].Txs[0].Hash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("missing bundle")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// Sources without the credentials of the endpoint cannot subscribe.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.Error(t, NewWebSocketSource("upstream", url, nil).subscribe(ctx, func([]types.MevBundle) {}))
+}
+
+func TestWebSocketBundleHintOnly(t *testing.T) {
+	for _, tt := range []struct {
+		data     string
+		hintOnly bool
+	}{
+		{`{"hash":"0x01","blockNumber":"0xc","hints":[{"hash":"0x02"}]}`, true},
+		{`{"hash":"0x01","blockNumber":"0xc","hints":[{"hash":"0x02"}],"txs":["0x03"]}`, false},
+		{`{"hash":"0x01","blockNumber":"0xc","hints":[],"sbundle":{}}`, false},
+		{`{"blockNumber":"0xc","txs":["0x03"]}`, false},
+	} {
+		var bundle webSocketBundle
+		require.NoError(t, json.Unmarshal([]byte(tt.data), &bundle))
+		require.Equal(t, tt.hintOnly, bundle.hintOnly(), tt.data)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+// BundleData encapsulates the arguments to `callBundle`.
+type BundleData struct {
+	Txs              []hexutil.Bytes // The signed transactions of the bundle.
+	BlockNumber      Long            // The number of the simulated block.
+	StateBlockNumber *Long           // The number of the block the bundle is simulated on top of.
+	StateBlockHash   *common.Hash    // The hash of the block the bundle is simulated on top of.
+	Coinbase         *common.Address // The coinbase of the simulated block.
+	Timestamp        *Long           // The timestamp of the simulated block.
+	Timeout          *Long           // The timeout of the simulation, in milliseconds.
+	GasLimit         *Long           // The gas limit of the simulated block.
+	Difficulty       *hexutil.Big    // The difficulty of the simulated block.
+	BaseFee          *hexutil.Big    // The base fee of the simulated block.
+	ExcessBlobGas    *Long           // The excess blob gas of the simulated block.
+}
+
+// callBundleArgs converts the bundle to the arguments of eth_callBundle.
+func (d *BundleData) callBundleArgs() ethapi.CallBundleArgs {
+	args := ethapi.CallBundleArgs{
+		Txs:                    d.Txs,
+		BlockNumber:            rpc.BlockNumber(d.BlockNumber),
+		StateBlockNumberOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber),
+	}
+	if d.StateBlockNumber != nil {
+		args.StateBlockNumberOrHash = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(*d.StateBlockNumber))
+	} else if d.StateBlockHash != nil {
+		args.StateBlockNumberOrHash = rpc.BlockNumberOrHashWithHash(*d.StateBlockHash, false)
+	}
+	if d.Coinbase != nil {
+		coinbase := d.Coinbase.Hex()
+		args.Coinbase = &coinbase
+	}
+	if d.Timestamp != nil {
+		timestamp := uint64(*d.Timestamp)
+		args.Timestamp = &timestamp
+	}
+	if d.Timeout != nil {
+		timeout := int64(*d.Timeout)
+		args.Timeout = &timeout
+	}
+	if d.GasLimit != nil {
+		gasLimit := uint64(*d.GasLimit)
+		args.GasLimit = &gasLimit
+	}
+	if d.Difficulty != nil {
+		args.Difficulty = d.Difficulty.ToInt()
+	}
+	if d.BaseFee != nil {
+		args.BaseFee = d.BaseFee.ToInt()
+	}
+	if d.ExcessBlobGas != nil {
+		excessBlobGas := uint64(*d.ExcessBlobGas)
+		args.ExcessBlobGas = &excessBlobGas
+	}
+	return args
+}
+
+// BundleResult encapsulates the result of an invocation of the `callBundle` accessor.
+type BundleResult struct {
+	res *ethapi.CallBundleResult
+}
+
+func (b *BundleResult) BundleHash() common.Hash {
+	return b.res.BundleHash
+}
+
+func (b *BundleResult) BundleGasPrice() hexutil.Big {
+	return bigInt(new(uint256.Int).Div(b.res.CoinbaseDiff, uint256.NewInt(b.res.TotalGasUsed)))
+}
+
+func (b *BundleResult) GasFees() hexutil.Big {
+	return bigInt(b.res.GasFees)
+}
+
+func (b *BundleResult) CoinbaseDiff() hexutil.Big {
+	return bigInt(b.res.CoinbaseDiff)
+}
+
+func (b *BundleResult) EthSentToCoinbase() hexutil.Big {
+	return bigInt(new(uint256.Int).Sub(b.res.CoinbaseDiff, b.res.GasFees))
+}
+
+func (b *BundleResult) TotalGasUsed() hexutil.Uint64 {
+	return hexutil.Uint64(b.res.TotalGasUsed)
+}
+
+func (b *BundleResult) TotalBlobGasUsed() hexutil.Uint64 {
+	return hexutil.Uint64(b.res.TotalBlobGasUsed)
+}
+
+func (b *BundleResult) StateBlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(b.res.StateBlockNumber)
+}
+
+func (b *BundleResult) Results() []*BundleTransactionResult {
+	ret := make([]*BundleTransactionResult, len(b.res.Results))
+	for i := range b.res.Results {
+		ret[i] = &BundleTransactionResult{&b.res.Results[i]}
+	}
+	return ret
+}
+
+// BundleTransactionResult encapsulates the result of a transaction of a simulated bundle.
+type BundleTransactionResult struct {
+	res *ethapi.CallBundleTxResult
+}
+
+func (t *BundleTransactionResult) Hash() common.Hash {
+	return t.res.TxHash
+}
+
+func (t *BundleTransactionResult) From() common.Address {
+	return t.res.From
+}
+
+func (t *BundleTransactionResult) To() *common.Address {
+	return t.res.To
+}
+
+func (t *BundleTransactionResult) GasUsed() hexutil.Uint64 {
+	return hexutil.Uint64(t.res.GasUsed)
+}
+
+func (t *BundleTransactionResult) BlobGasUsed() *hexutil.Uint64 {
+	if t.res.BlobGasUsed == 0 {
+		return nil
+	}
+	ret := hexutil.Uint64(t.res.BlobGasUsed)
+	return &ret
+}
+
+func (t *BundleTransactionResult) GasPrice() hexutil.Big {
+	return bigInt(new(uint256.Int).Div(t.res.CoinbaseDiff, uint256.NewInt(t.res.GasUsed)))
+}
+
+func (t *BundleTransactionResult) GasFees() hexutil.Big {
+	return bigInt(t.res.GasFees)
+}
+
+func (t *BundleTransactionResult) CoinbaseDiff() hexutil.Big {
+	return bigInt(t.res.CoinbaseDiff)
+}
+
+func (t *BundleTransactionResult) EthSentToCoinbase() hexutil.Big {
+	return bigInt(new(uint256.Int).Sub(t.res.CoinbaseDiff, t.res.GasFees))
+}
+
+func (t *BundleTransactionResult) Value() *hexutil.Bytes {
+	if t.res.Result.Err != nil {
+		return nil
+	}
+	ret := hexutil.Bytes(t.res.Result.Return())
+	return &ret
+}
+
+func (t *BundleTransactionResult) Error() *string {
+	if t.res.Result.Err == nil {
+		return nil
+	}
+	ret := t.res.Result.Err.Error()
+	return &ret
+}
+
+func (t *BundleTransactionResult) Revert() *hexutil.Bytes {
+	revert := t.res.Result.Revert()
+	if len(revert) == 0 {
+		return nil
+	}
+	ret := hexutil.Bytes(revert)
+	return &ret
+}
+
+func (r *Resolver) CallBundle(ctx context.Context, args struct{ Bundle BundleData }) (*BundleResult, error) {
+	res, err := ethapi.SimulateBundle(ctx, r.backend, ethapi.NewChainContext(ctx, r.backend), args.Bundle.callBundleArgs())
+	if err != nil {
+		return nil, err
+	}
+	return &BundleResult{res}, nil
+}
+
+func bigInt(v *uint256.Int) hexutil.Big {
+	return hexutil.Big(*v.ToBig())
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestCallBundle(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		// Contracts returning and reverting with a single byte
+		returner = common.HexToAddress("0x0b")
+		reverter = common.HexToAddress("0x0a")
+
+		genesis = &core.Genesis{
+			Config:     params.AllEthashProtocolChanges,
+			GasLimit:   11500000,
+			Difficulty: common.Big1,
+			Alloc: types.GenesisAlloc{
+				addr:     {Balance: big.NewInt(params.Ether)},
+				returner: {Balance: common.Big0, Code: common.FromHex("60bb60005360016000f3")},
+				reverter: {Balance: common.Big0, Code: common.FromHex("60aa60005360016000fd")},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		stack  = createNode(t)
+	)
+	defer stack.Close()
+
+	handler, _ := newGQLService(t, stack, false, genesis, 1, func(i int, gen *core.BlockGen) {})
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+
+	var (
+		txs []*types.Transaction
+		raw []string
+	)
+	for i, to := range []common.Address{returner, reverter} {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   genesis.Config.ChainID,
+			Nonce:     uint64(i),
+			To:        &to,
+			Gas:       100000,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(100 * params.GWei),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, _ := tx.MarshalBinary()
+		txs = append(txs, tx)
+		raw = append(raw, fmt.Sprintf("%q", hexutil.Encode(enc)))
+	}
+	body := fmt.Sprintf(`{callBundle(bundle: {txs: [%s, %s], blockNumber: 2}) {
+		bundleHash totalGasUsed totalBlobGasUsed stateBlockNumber gasFees coinbaseDiff ethSentToCoinbase
+		results { hash from to gasUsed blobGasUsed gasFees ethSentToCoinbase value error revert }
+	} }`, raw[0], raw[1])
+	res := handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+	if res.Errors != nil {
+		t.Fatalf("failed to execute query: %v", res.Errors)
+	}
+	var have struct {
+		CallBundle struct {
+			BundleHash        common.Hash
+			TotalGasUsed      hexutil.Uint64
+			TotalBlobGasUsed  hexutil.Uint64
+			StateBlockNumber  hexutil.Uint64
+			GasFees           *hexutil.Big
+			CoinbaseDiff      *hexutil.Big
+			EthSentToCoinbase *hexutil.Big
+			Results           []struct {
+				Hash              common.Hash
+				From              common.Address
+				To                *common.Address
+				GasUsed           hexutil.Uint64
+				BlobGasUsed       *hexutil.Uint64
+				GasFees           *hexutil.Big
+				EthSentToCoinbase *hexutil.Big
+				Value             *hexutil.Bytes
+				Error             *string
+				Revert            *hexutil.Bytes
+			}
+		}
+	}
+	if err := json.Unmarshal(res.Data, &have); err != nil {
+		t.Fatal(err)
+	}
+	bundle := have.CallBundle
+	if want := crypto.Keccak256Hash(txs[0].Hash().Bytes(), txs[1].Hash().Bytes()); bundle.BundleHash != want {
+		t.Errorf("wrong bundle hash %x, want %x", bundle.BundleHash, want)
+	}
+	if bundle.StateBlockNumber != 1 || bundle.TotalBlobGasUsed != 0 {
+		t.Errorf("wrong bundle result %+v", bundle)
+	}
+	wantFees := new(big.Int).Mul(big.NewInt(int64(bundle.TotalGasUsed)), big.NewInt(params.GWei))
+	if bundle.GasFees.ToInt().Cmp(wantFees) != 0 || bundle.CoinbaseDiff.ToInt().Cmp(wantFees) != 0 || bundle.EthSentToCoinbase.ToInt().Sign() != 0 {
+		t.Errorf("wrong bundle fees %v, coinbase diff %v, sent to coinbase %v, want fees %v", bundle.GasFees, bundle.CoinbaseDiff, bundle.EthSentToCoinbase, wantFees)
+	}
+	if len(bundle.Results) != 2 {
+		t.Fatalf("wrong number of results %d", len(bundle.Results))
+	}
+	ret, rev := bundle.Results[0], bundle.Results[1]
+	if ret.Hash != txs[0].Hash() || ret.From != addr || *ret.To != returner || ret.BlobGasUsed != nil {
+		t.Errorf("wrong result of returning tx %+v", ret)
+	}
+	if ret.Value == nil || ret.Value.String() != "0xbb" || ret.Error != nil || ret.Revert != nil {
+		t.Errorf("wrong output of returning tx: value %v, error %v, revert %v", ret.Value, ret.Error, ret.Revert)
+	}
+	if rev.Value != nil || rev.Error == nil || *rev.Error != "execution reverted" || rev.Revert == nil || rev.Revert.String() != "0xaa" {
+		t.Errorf("wrong output of reverting tx: value %v, error %v, revert %v", rev.Value, rev.Error, rev.Revert)
+	}
+	if ret.GasUsed+rev.GasUsed != bundle.TotalGasUsed {
+		t.Errorf("wrong gas used %d + %d, total %d", ret.GasUsed, rev.GasUsed, bundle.TotalGasUsed)
+	}
+
+	// Bundles are simulated on top of the requested state block.
+	body = fmt.Sprintf(`{callBundle(bundle: {txs: [%s], blockNumber: 1, stateBlockNumber: 0}) { stateBlockNumber } }`, raw[0])
+	res = handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+	if res.Errors != nil {
+		t.Fatalf("failed to execute query: %v", res.Errors)
+	}
+	if string(res.Data) != `{"callBundle":{"stateBlockNumber":"0x0"}}` {
+		t.Errorf("wrong state block result %s", res.Data)
+	}
+}
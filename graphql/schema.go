@@ -312,6 +312,98 @@ const schema string = `
         status: Long!
     }
 
+    # BundleData represents a bundle of signed transactions to simulate on top of a
+    # block. Fields other than txs and blockNumber are optional.
+    input BundleData {
+        # Txs are the RLP-encoded signed transactions of the bundle.
+        txs: [Bytes!]!
+        # BlockNumber is the number of the block the bundle is simulated in.
+        blockNumber: Long!
+        # StateBlockNumber is the number of the block whose state the bundle is
+        # simulated on top of. Defaults to the latest block if neither the number
+        # nor the hash is supplied.
+        stateBlockNumber: Long
+        # StateBlockHash is the hash of the block whose state the bundle is
+        # simulated on top of.
+        stateBlockHash: Bytes32
+        # Coinbase is the coinbase of the simulated block. Defaults to the coinbase
+        # of the state block.
+        coinbase: Address
+        # Timestamp is the timestamp of the simulated block. Defaults to 12 seconds
+        # after the state block.
+        timestamp: Long
+        # Timeout is the timeout of the simulation, in milliseconds. Defaults to 5
+        # seconds, 0 meaning no timeout.
+        timeout: Long
+        # GasLimit is the gas limit of the simulated block. Defaults to the gas limit
+        # of the state block.
+        gasLimit: Long
+        # Difficulty is the difficulty of the simulated block. Defaults to the
+        # difficulty of the state block.
+        difficulty: BigInt
+        # BaseFee is the base fee of the simulated block. Defaults to the base fee
+        # following the state block.
+        baseFee: BigInt
+        # ExcessBlobGas is the excess blob gas of the simulated block. Defaults to
+        # the excess blob gas following the state block.
+        excessBlobGas: Long
+    }
+
+    # BundleTransactionResult is the result of a transaction of a simulated bundle.
+    type BundleTransactionResult {
+        # Hash is the hash of the transaction.
+        hash: Bytes32!
+        # From is the sender of the transaction.
+        from: Address!
+        # To is the recipient of the transaction, null for contract creations.
+        to: Address
+        # GasUsed is the amount of gas used by the transaction.
+        gasUsed: Long!
+        # BlobGasUsed is the amount of blob gas used by the transaction, null if it
+        # is not a blob transaction.
+        blobGasUsed: Long
+        # GasPrice is the payment to the coinbase per unit of gas used, in wei.
+        gasPrice: BigInt!
+        # GasFees is the priority fee paid to the coinbase, in wei.
+        gasFees: BigInt!
+        # CoinbaseDiff is the balance change of the coinbase, in wei.
+        coinbaseDiff: BigInt!
+        # EthSentToCoinbase is the value transferred to the coinbase on top of the
+        # priority fee, in wei.
+        ethSentToCoinbase: BigInt!
+        # Value is the return data of the transaction, null if it failed.
+        value: Bytes
+        # Error is the error the transaction failed with, null if it succeeded.
+        error: String
+        # Revert is the revert data of the transaction, null if it did not revert.
+        revert: Bytes
+    }
+
+    # BundleResult is the result of a bundle simulation.
+    type BundleResult {
+        # BundleHash is the hash of the concatenated transaction hashes.
+        bundleHash: Bytes32!
+        # BundleGasPrice is the payment to the coinbase per unit of gas used by the
+        # bundle, in wei.
+        bundleGasPrice: BigInt!
+        # GasFees is the priority fee paid to the coinbase by the bundle, in wei.
+        gasFees: BigInt!
+        # CoinbaseDiff is the balance change of the coinbase, in wei.
+        coinbaseDiff: BigInt!
+        # EthSentToCoinbase is the value transferred to the coinbase on top of the
+        # priority fees, in wei.
+        ethSentToCoinbase: BigInt!
+        # TotalGasUsed is the amount of gas used by the bundle.
+        totalGasUsed: Long!
+        # TotalBlobGasUsed is the amount of blob gas used by the bundle.
+        totalBlobGasUsed: Long!
+        # StateBlockNumber is the number of the block the bundle was simulated on
+        # top of.
+        stateBlockNumber: Long!
+        # Results are the results of the transactions of the bundle.
+        results: [BundleTransactionResult!]!
+    }
+
     # FilterCriteria encapsulates log filter criteria for searching log entries.
     input FilterCriteria {
         # FromBlock is the block at which to start searching, inclusive. Defaults
@@ -385,6 +477,9 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # CallBundle simulates a bundle of signed transactions on top of a block, as
+        # the eth_callBundle method does.
+        callBundle(bundle: BundleData!): BundleResult!
     }
 
     type Mutation {
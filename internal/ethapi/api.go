@@ -1048,6 +1048,38 @@ func (diff *BlockOverrides) Apply(blockCtx *vm.BlockContext) {
 	}
 }
 
+// MakeHeader returns a new header object with the overridden fields.
+// Note: MakeHeader ignores BlobBaseFee if set. That's because the header has no such
+// field, and instead the blob base fee is derived from the excess blob gas.
+func (diff *BlockOverrides) MakeHeader(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	h := types.CopyHeader(header)
+	if diff.Number != nil {
+		h.Number = diff.Number.ToInt()
+	}
+	if diff.Difficulty != nil {
+		h.Difficulty = diff.Difficulty.ToInt()
+	}
+	if diff.Time != nil {
+		h.Time = uint64(*diff.Time)
+	}
+	if diff.GasLimit != nil {
+		h.GasLimit = uint64(*diff.GasLimit)
+	}
+	if diff.Coinbase != nil {
+		h.Coinbase = *diff.Coinbase
+	}
+	if diff.Random != nil {
+		h.MixDigest = *diff.Random
+	}
+	if diff.BaseFee != nil {
+		h.BaseFee = diff.BaseFee.ToInt()
+	}
+	return h
+}
+
 // ChainContextBackend provides methods required to implement ChainContext.
 type ChainContextBackend interface {
 	Engine() consensus.Engine
@@ -2186,6 +2218,7 @@ type SendBundleArgs struct {
 	MinTimestamp      *uint64         `json:"minTimestamp"`
 	MaxTimestamp      *uint64         `json:"maxTimestamp"`
 	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+	TopOfBlock        bool            `json:"topOfBlock"`
 }
 
 // SendBundle will add the signed transaction to the transaction pool.
@@ -2225,7 +2258,7 @@ func (s *PrivateTxBundleAPI) SendBundle(ctx context.Context, args SendBundleArgs
 		maxTimestamp = *args.MaxTimestamp
 	}
 
-	go s.b.SendBundle(ctx, txs, args.BlockNumber, replacementUuid, signingAddress, minTimestamp, maxTimestamp, args.RevertingTxHashes)
+	go s.b.SendBundle(ctx, txs, args.BlockNumber, replacementUuid, signingAddress, minTimestamp, maxTimestamp, args.RevertingTxHashes, args.TopOfBlock)
 
 	return nil
 }
@@ -2255,6 +2288,30 @@ type CallBundleArgs struct {
 	ExcessBlobGas          *uint64               `json:"excessBlobGas"`
 }
 
+// CallBundleTxResult is the result of a transaction of a bundle simulated with
+// SimulateBundle.
+type CallBundleTxResult struct {
+	TxHash       common.Hash
+	From         common.Address
+	To           *common.Address
+	GasUsed      uint64
+	BlobGasUsed  uint64       // Zero unless the transaction is a blob transaction
+	GasFees      *uint256.Int // Priority fees paid to the coinbase
+	CoinbaseDiff *uint256.Int // Balance change of the coinbase
+	Result       *core.ExecutionResult
+}
+
+// CallBundleResult is the result of a bundle simulated with SimulateBundle.
+type CallBundleResult struct {
+	Results          []CallBundleTxResult
+	BundleHash       common.Hash
+	GasFees          *uint256.Int
+	CoinbaseDiff     *uint256.Int
+	TotalGasUsed     uint64
+	TotalBlobGasUsed uint64
+	StateBlockNumber uint64
+}
+
 // CallBundle will simulate a bundle of transactions at the top of a given block
 // number with the state of another (or the same) block. This can be used to
 // simulate future blocks with the current state, or it can be used to simulate
@@ -2262,6 +2319,66 @@ type CallBundleArgs struct {
 // The sender is responsible for signing the transactions and using the correct
 // nonce and ensuring validity
 func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[string]interface{}, error) {
+	res, err := SimulateBundle(ctx, s.b, s.chain, args)
+	if err != nil {
+		return nil, err
+	}
+	results := []map[string]interface{}{}
+	for _, tx := range res.Results {
+		to := "0x"
+		if tx.To != nil {
+			to = tx.To.String()
+		}
+		jsonResult := map[string]interface{}{
+			"txHash":      tx.TxHash.String(),
+			"gasUsed":     tx.GasUsed,
+			"fromAddress": tx.From.String(),
+			"toAddress":   to,
+		}
+		if tx.Result.Err != nil {
+			jsonResult["error"] = tx.Result.Err.Error()
+			revert := tx.Result.Revert()
+			if len(revert) > 0 {
+				jsonResult["revert"] = string(revert)
+			}
+		} else {
+			dst := make([]byte, hex.EncodedLen(len(tx.Result.Return())))
+			hex.Encode(dst, tx.Result.Return())
+			jsonResult["value"] = "0x" + string(dst)
+		}
+		jsonResult["coinbaseDiff"] = tx.CoinbaseDiff.String()
+		jsonResult["gasFees"] = tx.GasFees.String()
+		jsonResult["ethSentToCoinbase"] = new(uint256.Int).Sub(tx.CoinbaseDiff, tx.GasFees).String()
+		jsonResult["gasPrice"] = new(uint256.Int).Div(tx.CoinbaseDiff, uint256.NewInt(tx.GasUsed)).String()
+		jsonResult["gasUsed"] = tx.GasUsed
+
+		if tx.BlobGasUsed > 0 {
+			jsonResult["blobGasUsed"] = tx.BlobGasUsed
+		}
+		results = append(results, jsonResult)
+	}
+
+	ret := map[string]interface{}{}
+	ret["results"] = results
+	ret["coinbaseDiff"] = res.CoinbaseDiff.String()
+	ret["gasFees"] = res.GasFees.String()
+	ret["ethSentToCoinbase"] = new(uint256.Int).Sub(res.CoinbaseDiff, res.GasFees).String()
+	ret["bundleGasPrice"] = new(uint256.Int).Div(res.CoinbaseDiff, uint256.NewInt(res.TotalGasUsed)).String()
+	ret["totalGasUsed"] = res.TotalGasUsed
+	ret["stateBlockNumber"] = int64(res.StateBlockNumber)
+
+	if res.TotalBlobGasUsed > 0 {
+		ret["totalBlobGasUsed"] = res.TotalBlobGasUsed
+	}
+
+	ret["bundleHash"] = "0x" + common.Bytes2Hex(res.BundleHash.Bytes())
+	return ret, nil
+}
+
+// SimulateBundle simulates a bundle as eth_callBundle does, returning the results of
+// the simulation rather than their JSON encoding. The chain provides the headers and
+// consensus engine the bundle is executed with.
+func SimulateBundle(ctx context.Context, b Backend, chain core.ChainContext, args CallBundleArgs) (*CallBundleResult, error) {
 	if len(args.Txs) == 0 {
 		return nil, errors.New("bundle missing txs")
 	}
@@ -2298,7 +2415,7 @@ func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[st
 	// this makes sure resources are cleaned up.
 	defer cancel()
 
-	state, parent, err := s.b.StateAndHeaderByNumberOrHash(ctx, args.StateBlockNumberOrHash)
+	state, parent, err := b.StateAndHeaderByNumberOrHash(ctx, args.StateBlockNumberOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -2323,14 +2440,14 @@ func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[st
 	var baseFee *big.Int
 	if args.BaseFee != nil {
 		baseFee = args.BaseFee
-	} else if s.b.ChainConfig().IsLondon(big.NewInt(args.BlockNumber.Int64())) {
-		baseFee = eip1559.CalcBaseFee(s.b.ChainConfig(), parent)
+	} else if b.ChainConfig().IsLondon(big.NewInt(args.BlockNumber.Int64())) {
+		baseFee = eip1559.CalcBaseFee(b.ChainConfig(), parent)
 	}
 
 	var excessBlobGas uint64
 	if args.ExcessBlobGas != nil {
 		excessBlobGas = *args.ExcessBlobGas
-	} else if s.b.ChainConfig().IsCancun(big.NewInt(args.BlockNumber.Int64()), timestamp) {
+	} else if b.ChainConfig().IsCancun(big.NewInt(args.BlockNumber.Int64()), timestamp) {
 		excessBlobGas = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
 	}
 
@@ -2351,14 +2468,14 @@ func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[st
 	// and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
 
-	results := []map[string]interface{}{}
+	res := &CallBundleResult{
+		GasFees:          new(uint256.Int),
+		StateBlockNumber: parent.Number.Uint64(),
+	}
 	coinbaseBalanceBefore := state.GetBalance(coinbase)
 
 	bundleHash := sha3.NewLegacyKeccak256()
-	signer := types.MakeSigner(s.b.ChainConfig(), blockNumber, timestamp)
-	var totalGasUsed uint64
-	var totalBlobGasUsed uint64
-	gasFees := new(uint256.Int)
+	signer := types.MakeSigner(b.ChainConfig(), blockNumber, timestamp)
 	for i, tx := range txs {
 		// Check if the context was cancelled (eg. timed-out)
 		if err := ctx.Err(); err != nil {
@@ -2368,27 +2485,16 @@ func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[st
 		coinbaseBalanceBeforeTx := state.GetBalance(coinbase)
 		state.SetTxContext(tx.Hash(), i)
 
-		receipt, result, err := core.ApplyTransactionWithResult(s.b.ChainConfig(), s.chain, &coinbase, gp, state, header, tx, &header.GasUsed, vmconfig)
+		receipt, result, err := core.ApplyTransactionWithResult(b.ChainConfig(), chain, &coinbase, gp, state, header, tx, &header.GasUsed, vmconfig)
 		if err != nil {
 			return nil, fmt.Errorf("err: %w; txhash %s", err, tx.Hash())
 		}
 
-		txHash := tx.Hash().String()
 		from, err := types.Sender(signer, tx)
 		if err != nil {
 			return nil, fmt.Errorf("err: %w; txhash %s", err, tx.Hash())
 		}
-		to := "0x"
-		if tx.To() != nil {
-			to = tx.To().String()
-		}
-		jsonResult := map[string]interface{}{
-			"txHash":      txHash,
-			"gasUsed":     receipt.GasUsed,
-			"fromAddress": from.String(),
-			"toAddress":   to,
-		}
-		totalGasUsed += receipt.GasUsed
+		res.TotalGasUsed += receipt.GasUsed
 		gasPrice, err := tx.EffectiveGasTip(header.BaseFee)
 		if err != nil {
 			return nil, fmt.Errorf("err: %w; txhash %s", err, tx.Hash())
@@ -2398,49 +2504,27 @@ func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[st
 			return nil, fmt.Errorf("err: %w; txhash %s", err, tx.Hash())
 		}
 		gasFeesTx := new(uint256.Int).Mul(uint256.NewInt(receipt.GasUsed), uint256GasPrice)
-		gasFees.Add(gasFees, gasFeesTx)
+		res.GasFees.Add(res.GasFees, gasFeesTx)
 		bundleHash.Write(tx.Hash().Bytes())
-		if result.Err != nil {
-			jsonResult["error"] = result.Err.Error()
-			revert := result.Revert()
-			if len(revert) > 0 {
-				jsonResult["revert"] = string(revert)
-			}
-		} else {
-			dst := make([]byte, hex.EncodedLen(len(result.Return())))
-			hex.Encode(dst, result.Return())
-			jsonResult["value"] = "0x" + string(dst)
-		}
-		coinbaseDiffTx := new(uint256.Int).Sub(state.GetBalance(coinbase), coinbaseBalanceBeforeTx)
-		jsonResult["coinbaseDiff"] = coinbaseDiffTx.String()
-		jsonResult["gasFees"] = gasFeesTx.String()
-		jsonResult["ethSentToCoinbase"] = new(uint256.Int).Sub(coinbaseDiffTx, gasFeesTx).String()
-		jsonResult["gasPrice"] = new(uint256.Int).Div(coinbaseDiffTx, uint256.NewInt(receipt.GasUsed)).String()
-		jsonResult["gasUsed"] = receipt.GasUsed
 
+		txResult := CallBundleTxResult{
+			TxHash:       tx.Hash(),
+			From:         from,
+			To:           tx.To(),
+			GasUsed:      receipt.GasUsed,
+			GasFees:      gasFeesTx,
+			CoinbaseDiff: new(uint256.Int).Sub(state.GetBalance(coinbase), coinbaseBalanceBeforeTx),
+			Result:       result,
+		}
 		if tx.Type() == types.BlobTxType {
-			totalBlobGasUsed += receipt.BlobGasUsed
-			jsonResult["blobGasUsed"] = receipt.BlobGasUsed
+			res.TotalBlobGasUsed += receipt.BlobGasUsed
+			txResult.BlobGasUsed = receipt.BlobGasUsed
 		}
-		results = append(results, jsonResult)
+		res.Results = append(res.Results, txResult)
 	}
-
-	ret := map[string]interface{}{}
-	ret["results"] = results
-	coinbaseDiff := new(uint256.Int).Sub(state.GetBalance(coinbase), coinbaseBalanceBefore)
-	ret["coinbaseDiff"] = coinbaseDiff.String()
-	ret["gasFees"] = gasFees.String()
-	ret["ethSentToCoinbase"] = new(uint256.Int).Sub(coinbaseDiff, gasFees).String()
-	ret["bundleGasPrice"] = new(uint256.Int).Div(coinbaseDiff, uint256.NewInt(totalGasUsed)).String()
-	ret["totalGasUsed"] = totalGasUsed
-	ret["stateBlockNumber"] = parent.Number.Int64()
-
-	if totalBlobGasUsed > 0 {
-		ret["totalBlobGasUsed"] = totalBlobGasUsed
-	}
-
-	ret["bundleHash"] = "0x" + common.Bytes2Hex(bundleHash.Sum(nil))
-	return ret, nil
+	res.CoinbaseDiff = new(uint256.Int).Sub(state.GetBalance(coinbase), coinbaseBalanceBefore)
+	res.BundleHash = common.BytesToHash(bundleHash.Sum(nil))
+	return res, nil
 }
 
 // EstimateGasBundleArgs represents the arguments for a call
@@ -546,9 +546,18 @@ func (b testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOr
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
 	}
-	panic("only implemented for number")
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		header := b.chain.GetHeaderByHash(hash)
+		if header == nil {
+			return nil, nil, errors.New("header not found")
+		}
+		stateDb, err := b.chain.StateAt(header.Root)
+		return stateDb, header, err
+	}
+	panic("unknown type rpc.BlockNumberOrHash")
 }
 func (b testBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) { panic("implement me") }
+func (b testBackend) BestPendingBlock() *types.Block                          { return b.pending }
 func (b testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	header, err := b.HeaderByHash(ctx, hash)
 	if header == nil || err != nil {
@@ -619,12 +628,19 @@ func (b testBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscripti
 func (b testBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	panic("implement me")
 }
-func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
+func (b testBackend) SubscribeNewBundlesEvent(ch chan<- core.NewBundlesEvent) event.Subscription {
+	panic("implement me")
+}
+func (b testBackend) SubscribePendingBlockEvent(ch chan<- core.NewPendingBlockEvent) event.Subscription {
+	panic("implement me")
+}
+func (b testBackend) BloomStatus() (uint64, uint64)    { panic("implement me") }
+func (b testBackend) LogIndexStatus() (uint64, uint64) { panic("implement me") }
 func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	panic("implement me")
 }
 func (b testBackend) CancelSBundles(ctx context.Context, hashes []common.Hash) { panic("implement me") }
-func (b testBackend) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+func (b testBackend) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash, topOfBlock bool) error {
 	panic("implement me")
 }
 func (b testBackend) SendSBundle(ctx context.Context, sbundle *types.SBundle) error {
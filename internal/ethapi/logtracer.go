@@ -0,0 +1,110 @@
+package ethapi
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// keccak256("Transfer(address,address,uint256)")
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	// ERC-7528
+	transferAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+)
+
+// tracer collects the logs of a call, including ETH transfers as ERC20 Transfer logs of
+// the ERC-7528 address when traceTransfers is set. The logs of reverted call frames are
+// dropped.
+type tracer struct {
+	// logs keeps the logs of the current call frame and its parents
+	logs           []*types.Log
+	frames         []int
+	traceTransfers bool
+}
+
+func newTracer(traceTransfers bool) *tracer {
+	return &tracer{traceTransfers: traceTransfers}
+}
+
+func (t *tracer) reset() {
+	t.logs = nil
+	t.frames = nil
+}
+
+func (t *tracer) CaptureTxStart(gasLimit uint64) {
+	t.reset()
+}
+
+func (t *tracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *tracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.captureTransfer(from, to, value)
+}
+
+func (t *tracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if err != nil {
+		t.logs = nil
+	}
+}
+
+func (t *tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, len(t.logs))
+	if typ != vm.DELEGATECALL && typ != vm.STATICCALL {
+		t.captureTransfer(from, to, value)
+	}
+}
+
+func (t *tracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	size := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if err != nil {
+		t.logs = t.logs[:size]
+	}
+}
+
+func (t *tracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op < vm.LOG0 || op > vm.LOG4 || err != nil {
+		return
+	}
+	stack := scope.Stack.Data()
+	size := int(op - vm.LOG0)
+	if len(stack) < size+2 {
+		return
+	}
+	var (
+		offset = scope.Stack.Back(0)
+		length = scope.Stack.Back(1)
+		topics = make([]common.Hash, size)
+	)
+	for i := 0; i < size; i++ {
+		topics[i] = scope.Stack.Back(2 + i).Bytes32()
+	}
+	// Tracing happens before the memory expansion of the log, the expanded memory is zero
+	data := make([]byte, length.Uint64())
+	if start := offset.Uint64(); start < uint64(scope.Memory.Len()) {
+		copy(data, scope.Memory.Data()[start:])
+	}
+	t.logs = append(t.logs, &types.Log{
+		Address: scope.Contract.Address(),
+		Topics:  topics,
+		Data:    data,
+	})
+}
+
+func (t *tracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *tracer) captureTransfer(from, to common.Address, value *big.Int) {
+	if !t.traceTransfers || value == nil || value.Sign() <= 0 {
+		return
+	}
+	t.logs = append(t.logs, &types.Log{
+		Address: transferAddress,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.BigToHash(value).Bytes(),
+	})
+}
@@ -13,8 +13,11 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -22,6 +25,7 @@ const maxDepth = 5
 const maxBodySize = 50
 const defaultSimTimeout = time.Second * 5
 const maxSimTimeout = time.Second * 30
+const maxSimBundles = 100
 
 var (
 	ErrMaxDepth         = errors.New("max depth reached")
@@ -29,6 +33,8 @@ var (
 	ErrBundleTooLarge   = errors.New("bundle too large")
 	ErrInvalidValidity  = errors.New("invalid validity")
 	ErrInvalidInclusion = errors.New("invalid inclusion")
+	ErrTooManyBundles   = errors.New("too many bundles")
+	ErrNoPendingBlock   = errors.New("no pending block")
 )
 
 type MevAPI struct {
@@ -182,6 +188,7 @@ type SimMevBundleResponse struct {
 	Success         bool                     `json:"success"`
 	Error           string                   `json:"error,omitempty"`
 	StateBlock      hexutil.Uint64           `json:"stateBlock"`
+	PendingBlock    *common.Hash             `json:"pendingBlock,omitempty"`
 	MevGasPrice     hexutil.U256             `json:"mevGasPrice"`
 	Profit          hexutil.U256             `json:"profit"`
 	RefundableValue hexutil.U256             `json:"refundableValue"`
@@ -189,9 +196,12 @@ type SimMevBundleResponse struct {
 	BodyLogs        []core.SimBundleBodyLogs `json:"logs,omitempty"`
 	ExecError       string                   `json:"execError,omitempty"`
 	Revert          hexutil.Bytes            `json:"revert,omitempty"`
+	// StateDiffs has the state changes of each executed tx attributed to its call frames
+	StateDiffs []*logger.StateDiffFrame `json:"stateDiffs,omitempty"`
 }
 
 type SimMevBundleAuxArgs struct {
+	// parentBlock "pending" simulates on top of the best block built for the next slot
 	ParentBlock *rpc.BlockNumberOrHash `json:"parentBlock"`
 	// override the default values for the block header
 	BlockNumber *hexutil.Big    `json:"blockNumber"`
@@ -200,11 +210,25 @@ type SimMevBundleAuxArgs struct {
 	GasLimit    *hexutil.Uint64 `json:"gasLimit"`
 	BaseFee     *hexutil.Big    `json:"baseFee"`
 	Timeout     *int64          `json:"timeout"`
+	// return the state changes of the txs, see SimMevBundleResponse.StateDiffs
+	StateDiffs bool `json:"stateDiffs"`
 }
 
-func (api *MevAPI) SimBundle(ctx context.Context, args SendMevBundleArgs, aux SimMevBundleAuxArgs) (*SimMevBundleResponse, error) {
-	var cancel context.CancelFunc
+// simBundleEnv is the block and state bundles are simulated on, either a new block on top
+// of a parent block or the best pending block built by the builder.
+type simBundleEnv struct {
+	config       *params.ChainConfig
+	chain        *core.BlockChain
+	header       *types.Header
+	statedb      *state.StateDB
+	gasPool      *core.GasPool
+	gasUsed      uint64
+	txIdx        int
+	stateBlock   uint64
+	pendingBlock *common.Hash
+}
 
+func simTimeout(ctx context.Context, aux SimMevBundleAuxArgs) (context.Context, context.CancelFunc) {
 	timeout := defaultSimTimeout
 	if aux.Timeout != nil {
 		timeout = time.Duration(*aux.Timeout) * time.Millisecond
@@ -212,20 +236,19 @@ func (api *MevAPI) SimBundle(ctx context.Context, args SendMevBundleArgs, aux Si
 			timeout = maxSimTimeout
 		}
 	}
-	ctx, cancel = context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	bundle, err := ParseSBundleArgs(&args)
-	if err != nil {
-		return nil, err
-	}
+	return context.WithTimeout(ctx, timeout)
+}
 
+func (api *MevAPI) newSimBundleEnv(ctx context.Context, aux SimMevBundleAuxArgs) (*simBundleEnv, error) {
 	var parentBlock rpc.BlockNumberOrHash
 	if aux.ParentBlock != nil {
 		parentBlock = *aux.ParentBlock
 	} else {
 		parentBlock = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 	}
+	if number, ok := parentBlock.Number(); ok && number == rpc.PendingBlockNumber {
+		return api.newPendingSimBundleEnv(ctx, aux)
+	}
 
 	statedb, parentHeader, err := api.b.StateAndHeaderByNumberOrHash(ctx, parentBlock)
 	if err != nil {
@@ -241,9 +264,64 @@ func (api *MevAPI) SimBundle(ctx context.Context, args SendMevBundleArgs, aux Si
 		Coinbase:   parentHeader.Coinbase,
 		BaseFee:    eip1559.CalcBaseFee(api.b.ChainConfig(), parentHeader),
 	}
+	aux.overrideHeader(&header)
+
+	if api.b.ChainConfig().IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if parentHeader.ExcessBlobGas != nil && parentHeader.BlobGasUsed != nil {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*parentHeader.ExcessBlobGas, *parentHeader.BlobGasUsed)
+		}
+		header.ExcessBlobGas = &excessBlobGas
+	}
+
+	return &simBundleEnv{
+		config:     api.b.ChainConfig(),
+		chain:      api.chain,
+		header:     &header,
+		statedb:    statedb,
+		gasPool:    new(core.GasPool).AddGas(header.GasLimit),
+		stateBlock: parentHeader.Number.Uint64(),
+	}, nil
+}
+
+// newPendingSimBundleEnv prepares the simulation after the transactions of the best block
+// built for the next slot, by processing the block on top of its parent.
+func (api *MevAPI) newPendingSimBundleEnv(ctx context.Context, aux SimMevBundleAuxArgs) (*simBundleEnv, error) {
+	block := api.b.BestPendingBlock()
+	if block == nil {
+		return nil, ErrNoPendingBlock
+	}
+	statedb, parentHeader, err := api.b.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(block.ParentHash(), false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending block parent: %w", err)
+	}
+	if _, _, _, err := api.chain.Processor().Process(block, statedb, vm.Config{}); err != nil {
+		return nil, fmt.Errorf("failed to process pending block: %w", err)
+	}
 
+	header := block.Header()
+	aux.overrideHeader(header)
+	if header.GasLimit < block.GasUsed() {
+		return nil, fmt.Errorf("gas limit %d below pending block gas used %d", header.GasLimit, block.GasUsed())
+	}
+
+	pendingBlock := block.Hash()
+	return &simBundleEnv{
+		config:       api.b.ChainConfig(),
+		chain:        api.chain,
+		header:       header,
+		statedb:      statedb,
+		gasPool:      new(core.GasPool).AddGas(header.GasLimit - block.GasUsed()),
+		gasUsed:      block.GasUsed(),
+		txIdx:        len(block.Transactions()),
+		stateBlock:   parentHeader.Number.Uint64(),
+		pendingBlock: &pendingBlock,
+	}, nil
+}
+
+func (aux *SimMevBundleAuxArgs) overrideHeader(header *types.Header) {
 	if aux.BlockNumber != nil {
-		header.Number.Set(aux.BlockNumber.ToInt())
+		header.Number = new(big.Int).Set(aux.BlockNumber.ToInt())
 	}
 	if aux.Coinbase != nil {
 		header.Coinbase = *aux.Coinbase
@@ -257,20 +335,28 @@ func (api *MevAPI) SimBundle(ctx context.Context, args SendMevBundleArgs, aux Si
 	if aux.BaseFee != nil {
 		header.BaseFee = aux.BaseFee.ToInt()
 	}
+}
 
-	if api.b.ChainConfig().IsCancun(header.Number, header.Time) {
-		var excessBlobGas uint64
-		if parentHeader.ExcessBlobGas != nil && parentHeader.BlobGasUsed != nil {
-			excessBlobGas = eip4844.CalcExcessBlobGas(*parentHeader.ExcessBlobGas, *parentHeader.BlobGasUsed)
-		}
-		header.ExcessBlobGas = &excessBlobGas
+// commitBundle simulates the bundle on a copy of the state, keeping the changes if the
+// bundle succeeds. It returns the state before the bundle.
+func (env *simBundleEnv) commitBundle(bundle *types.SBundle, tracer vm.EVMLogger) (core.SimBundleResult, *state.StateDB, error) {
+	var (
+		statedb = env.statedb.Copy()
+		gasPool = *env.gasPool
+		gasUsed = env.gasUsed
+	)
+	res, err := core.SimBundle(env.config, env.chain, &env.header.Coinbase, &gasPool, statedb, env.header, bundle, env.txIdx, &gasUsed, vm.Config{Tracer: tracer}, true)
+	if err != nil {
+		return res, nil, err
 	}
+	pre := env.statedb
+	env.statedb, env.gasPool, env.gasUsed = statedb, &gasPool, gasUsed
+	env.txIdx += sbundleTxCount(bundle)
+	return res, pre, nil
+}
 
-	gp := new(core.GasPool).AddGas(header.GasLimit)
-
-	result := &SimMevBundleResponse{}
-	tmpGasUsed := uint64(0)
-	bundleRes, err := core.SimBundle(api.b.ChainConfig(), api.chain, &header.Coinbase, gp, statedb, &header, &bundle, 0, &tmpGasUsed, vm.Config{}, true)
+func (env *simBundleEnv) response(bundleRes core.SimBundleResult, err error) SimMevBundleResponse {
+	result := SimMevBundleResponse{}
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
@@ -280,13 +366,112 @@ func (api *MevAPI) SimBundle(ctx context.Context, args SendMevBundleArgs, aux Si
 	}
 	result.ExecError = bundleRes.ExecError
 	result.Revert = bundleRes.Revert
-	result.StateBlock = hexutil.Uint64(parentHeader.Number.Uint64())
+	result.StateBlock = hexutil.Uint64(env.stateBlock)
+	result.PendingBlock = env.pendingBlock
 	result.MevGasPrice = hexutil.U256(*bundleRes.MevGasPrice)
 	result.Profit = hexutil.U256(*bundleRes.TotalProfit)
 	result.RefundableValue = hexutil.U256(*bundleRes.RefundableValue)
 	result.GasUsed = hexutil.Uint64(bundleRes.GasUsed)
+	return result
+}
 
-	return result, nil
+// sbundleTxCount returns the number of transactions of the bundle and its inner bundles.
+func sbundleTxCount(bundle *types.SBundle) int {
+	count := 0
+	for _, el := range bundle.Body {
+		if el.Tx != nil {
+			count++
+		} else if el.Bundle != nil {
+			count += sbundleTxCount(el.Bundle)
+		}
+	}
+	return count
+}
+
+func (api *MevAPI) SimBundle(ctx context.Context, args SendMevBundleArgs, aux SimMevBundleAuxArgs) (*SimMevBundleResponse, error) {
+	ctx, cancel := simTimeout(ctx, aux)
+	defer cancel()
+
+	bundle, err := ParseSBundleArgs(&args)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := api.newSimBundleEnv(ctx, aux)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tracer     vm.EVMLogger
+		diffTracer *logger.StateDiffTracer
+	)
+	if aux.StateDiffs {
+		diffTracer = logger.NewStateDiffTracer()
+		tracer = diffTracer
+	}
+	bundleRes, _, err := env.commitBundle(&bundle, tracer)
+	result := env.response(bundleRes, err)
+	if diffTracer != nil {
+		result.StateDiffs = diffTracer.Frames()
+	}
+	return &result, nil
+}
+
+// SimMevBundlesResult is the result of a bundle of a sequence, with the changes of the
+// bundle to the state and to the balance of the coinbase.
+type SimMevBundlesResult struct {
+	SimMevBundleResponse
+	CoinbaseDiff *hexutil.Big           `json:"coinbaseDiff"`
+	StateDiff    *SimMevBundleStateDiff `json:"stateDiff,omitempty"`
+}
+
+// SimBundles simulates the bundles in order on the same state, each bundle on top of the
+// previous successful ones. Failed bundles leave the state unchanged for the next ones.
+func (api *MevAPI) SimBundles(ctx context.Context, args []SendMevBundleArgs, aux SimMevBundleAuxArgs) ([]*SimMevBundlesResult, error) {
+	if len(args) > maxSimBundles {
+		return nil, ErrTooManyBundles
+	}
+
+	ctx, cancel := simTimeout(ctx, aux)
+	defer cancel()
+
+	bundles := make([]types.SBundle, len(args))
+	for i := range args {
+		bundle, err := ParseSBundleArgs(&args[i])
+		if err != nil {
+			return nil, fmt.Errorf("bundle %d: %w", i, err)
+		}
+		bundles[i] = bundle
+	}
+
+	env, err := api.newSimBundleEnv(ctx, aux)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*SimMevBundlesResult, 0, len(bundles))
+	for i := range bundles {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("simulation of bundle %d: %w", i, err)
+		}
+		coinbase := env.header.Coinbase
+		coinbaseBefore := env.statedb.GetBalance(coinbase).ToBig()
+
+		tracer := newTouchedStateTracer()
+		tracer.touch(coinbase)
+		bundleRes, pre, err := env.commitBundle(&bundles[i], tracer)
+		result := &SimMevBundlesResult{
+			SimMevBundleResponse: env.response(bundleRes, err),
+			CoinbaseDiff:         new(hexutil.Big),
+		}
+		if err == nil {
+			result.CoinbaseDiff = (*hexutil.Big)(new(big.Int).Sub(env.statedb.GetBalance(coinbase).ToBig(), coinbaseBefore))
+			result.StateDiff = tracer.diff(pre, env.statedb)
+		}
+		results = append(results, result)
+	}
+	return results, nil
 }
 
 func (api *MevAPI) CancelBundleByHash(ctx context.Context, hash common.Hash) error {
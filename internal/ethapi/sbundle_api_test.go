@@ -0,0 +1,134 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimBundles(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		coinbase = common.HexToAddress("0xc0ffee")
+		// stores 1 at slot 0
+		storer  = common.HexToAddress("0x5707e")
+		genesis = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				storer:           {Code: common.FromHex("0x600160005500")},
+			},
+		}
+		signer = types.LatestSigner(params.MergedTestChainConfig)
+	)
+	backend := newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	})
+	api := NewMevAPI(backend, backend.chain)
+	head := backend.chain.CurrentBlock()
+	baseFee := head.BaseFee
+
+	bundle := func(block uint64, nonce uint64, to common.Address, tip int64) SendMevBundleArgs {
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			To:        &to,
+			Value:     big.NewInt(1000),
+			Gas:       50000,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: new(big.Int).Add(new(big.Int).Mul(baseFee, common.Big2), big.NewInt(tip)),
+		}), signer, accounts[0].key)
+		require.NoError(t, err)
+		data, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		return SendMevBundleArgs{
+			Version:   "v0.1",
+			Inclusion: MevBundleInclusion{BlockNumber: hexutil.Uint64(block)},
+			Body:      []MevBundleBody{{Tx: (*hexutil.Bytes)(&data)}},
+		}
+	}
+	next := head.Number.Uint64() + 1
+	aux := SimMevBundleAuxArgs{Coinbase: &coinbase}
+
+	// The second bundle reuses the nonce of the first one and fails, leaving the state
+	// unchanged for the third one.
+	results, err := api.SimBundles(context.Background(), []SendMevBundleArgs{
+		bundle(next, 0, accounts[1].addr, 10),
+		bundle(next, 0, accounts[1].addr, 20),
+		bundle(next, 1, storer, 30),
+	}, aux)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.True(t, results[0].Success)
+	require.Equal(t, big.NewInt(21000*10), results[0].CoinbaseDiff.ToInt())
+	diff := results[0].StateDiff
+	require.Equal(t, uint64(0), uint64(*diff.Pre[accounts[0].addr].Nonce))
+	require.Equal(t, uint64(1), uint64(*diff.Post[accounts[0].addr].Nonce))
+	require.Equal(t, uint64(1000), (*uint256.Int)(diff.Post[accounts[1].addr].Balance).Uint64())
+	require.Contains(t, diff.Post, coinbase)
+
+	require.False(t, results[1].Success)
+	require.NotEmpty(t, results[1].Error)
+	require.Zero(t, results[1].CoinbaseDiff.ToInt().Sign())
+	require.Nil(t, results[1].StateDiff)
+
+	require.True(t, results[2].Success)
+	require.Equal(t, common.BigToHash(common.Big1), results[2].StateDiff.Post[storer].Storage[common.Hash{}])
+	require.Equal(t, common.Hash{}, results[2].StateDiff.Pre[storer].Storage[common.Hash{}])
+	require.Equal(t, hexutil.Uint64(head.Number.Uint64()), results[2].StateBlock)
+	require.Nil(t, results[2].PendingBlock)
+
+	// The state changes of the txs are attributed to their call frames on request.
+	result, err := api.SimBundle(context.Background(), bundle(next, 0, storer, 30), SimMevBundleAuxArgs{Coinbase: &coinbase, StateDiffs: true})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+	require.Len(t, result.StateDiffs, 1)
+	frame := result.StateDiffs[0]
+	require.Equal(t, storer, frame.To)
+	require.Equal(t, common.BigToHash(common.Big1), frame.Post[storer].Storage[common.Hash{}])
+	require.Equal(t, big.NewInt(1000), frame.Post[storer].Balance.ToInt())
+
+	// Without a block built for the next slot there is no pending state.
+	pending := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	_, err = api.SimBundle(context.Background(), bundle(next, 1, storer, 30), SimMevBundleAuxArgs{ParentBlock: &pending})
+	require.ErrorIs(t, err, ErrNoPendingBlock)
+
+	// Bundles are simulated after the transactions of the pending block.
+	parent := backend.chain.GetBlockByHash(head.Hash())
+	blocks, _ := core.GenerateChain(genesis.Config, parent, backend.chain.Engine(), backend.db, 1, func(i int, b *core.BlockGen) {
+		b.SetPoS()
+		b.SetCoinbase(coinbase)
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			Nonce:     0,
+			To:        &accounts[1].addr,
+			Gas:       params.TxGas,
+			GasTipCap: common.Big1,
+			GasFeeCap: new(big.Int).Mul(b.BaseFee(), common.Big2),
+		}), signer, accounts[0].key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+	backend.setPendingBlock(blocks[0])
+
+	result, err = api.SimBundle(context.Background(), bundle(next, 0, accounts[1].addr, 10), SimMevBundleAuxArgs{ParentBlock: &pending})
+	require.NoError(t, err)
+	require.False(t, result.Success)
+
+	result, err = api.SimBundle(context.Background(), bundle(next, 1, storer, 30), SimMevBundleAuxArgs{ParentBlock: &pending})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+	require.Equal(t, blocks[0].Hash(), *result.PendingBlock)
+	require.Equal(t, hexutil.Uint64(head.Number.Uint64()), result.StateBlock)
+}
@@ -0,0 +1,123 @@
+package ethapi
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// SimMevBundleAccountState holds the changed fields of an account.
+type SimMevBundleAccountState struct {
+	Balance *hexutil.U256               `json:"balance,omitempty"`
+	Nonce   *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// SimMevBundleStateDiff is the state changed by a bundle, with the values of the changed
+// accounts before and after the bundle.
+type SimMevBundleStateDiff struct {
+	Pre  map[common.Address]*SimMevBundleAccountState `json:"pre"`
+	Post map[common.Address]*SimMevBundleAccountState `json:"post"`
+}
+
+// touchedStateTracer collects the accounts and storage slots that transactions can change:
+// the accounts of the call frames and the slots written to.
+type touchedStateTracer struct {
+	accounts map[common.Address]map[common.Hash]struct{}
+}
+
+func newTouchedStateTracer() *touchedStateTracer {
+	return &touchedStateTracer{accounts: make(map[common.Address]map[common.Hash]struct{})}
+}
+
+func (t *touchedStateTracer) touch(addr common.Address) map[common.Hash]struct{} {
+	slots, ok := t.accounts[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		t.accounts[addr] = slots
+	}
+	return slots
+}
+
+func (t *touchedStateTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *touchedStateTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *touchedStateTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.touch(from)
+	t.touch(to)
+}
+
+func (t *touchedStateTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *touchedStateTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.touch(from)
+	t.touch(to)
+}
+
+func (t *touchedStateTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *touchedStateTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op != vm.SSTORE || err != nil || len(scope.Stack.Data()) < 1 {
+		return
+	}
+	slot := common.Hash(scope.Stack.Back(0).Bytes32())
+	t.touch(scope.Contract.Address())[slot] = struct{}{}
+}
+
+func (t *touchedStateTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// diff compares the touched accounts between the states before and after the
+// transactions, leaving out the unchanged accounts and fields.
+func (t *touchedStateTracer) diff(pre, post *state.StateDB) *SimMevBundleStateDiff {
+	diff := &SimMevBundleStateDiff{
+		Pre:  make(map[common.Address]*SimMevBundleAccountState),
+		Post: make(map[common.Address]*SimMevBundleAccountState),
+	}
+	for addr, slots := range t.accounts {
+		var (
+			preAccount  = new(SimMevBundleAccountState)
+			postAccount = new(SimMevBundleAccountState)
+			changed     bool
+		)
+		if preBalance, postBalance := pre.GetBalance(addr), post.GetBalance(addr); !preBalance.Eq(postBalance) {
+			preAccount.Balance = (*hexutil.U256)(new(uint256.Int).Set(preBalance))
+			postAccount.Balance = (*hexutil.U256)(new(uint256.Int).Set(postBalance))
+			changed = true
+		}
+		if preNonce, postNonce := pre.GetNonce(addr), post.GetNonce(addr); preNonce != postNonce {
+			preAccount.Nonce = (*hexutil.Uint64)(&preNonce)
+			postAccount.Nonce = (*hexutil.Uint64)(&postNonce)
+			changed = true
+		}
+		if pre.GetCodeHash(addr) != post.GetCodeHash(addr) {
+			preAccount.Code = pre.GetCode(addr)
+			postAccount.Code = post.GetCode(addr)
+			changed = true
+		}
+		for slot := range slots {
+			preValue, postValue := pre.GetState(addr, slot), post.GetState(addr, slot)
+			if preValue == postValue {
+				continue
+			}
+			if preAccount.Storage == nil {
+				preAccount.Storage = make(map[common.Hash]common.Hash)
+				postAccount.Storage = make(map[common.Hash]common.Hash)
+			}
+			preAccount.Storage[slot] = preValue
+			postAccount.Storage[slot] = postValue
+			changed = true
+		}
+		if changed {
+			diff.Pre[addr] = preAccount
+			diff.Post[addr] = postAccount
+		}
+	}
+	return diff
+}
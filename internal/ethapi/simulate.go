@@ -0,0 +1,498 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated, including
+	// the empty blocks filling the gaps between the requested ones
+	maxSimulateBlocks = 256
+	// timestampIncrement is the default increment between the timestamps of simulated
+	// blocks
+	timestampIncrement = 12
+)
+
+// simBlock is a block of calls to simulate, with the overrides applied before the calls.
+type simBlock struct {
+	BlockOverrides *BlockOverrides
+	StateOverrides *StateOverride
+	Calls          []TransactionArgs
+}
+
+// simCallResult is the result of a simulated call.
+type simCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *callError     `json:"error,omitempty"`
+}
+
+// simOpts are the inputs of eth_simulateV1.
+type simOpts struct {
+	BlockStateCalls        []simBlock
+	TraceTransfers         bool
+	Validation             bool
+	ReturnFullTransactions bool
+}
+
+// simulator runs simulated blocks in order on a shared state.
+type simulator struct {
+	b              Backend
+	state          *state.StateDB
+	base           *types.Header
+	chainConfig    *params.ChainConfig
+	gasRemaining   uint64
+	traceTransfers bool
+	validate       bool
+	fullTx         bool
+}
+
+// SimulateV1 executes series of transactions on top of a base state. The transactions
+// are packed into blocks. For each block, block header fields can be overridden. The
+// state can also be overridden prior to the execution of each block.
+//
+// Note, this function doesn't make any changes in the state/blockchain and is useful to
+// execute and retrieve values.
+func (s *BlockChainAPI) SimulateV1(ctx context.Context, opts simOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, &invalidParamsError{message: "empty input"}
+	} else if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, &clientLimitExceededError{message: "too many blocks"}
+	}
+	if blockNrOrHash == nil {
+		n := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &n
+	}
+	state, base, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	gasCap := s.b.RPCGasCap()
+	if gasCap == 0 {
+		gasCap = math.MaxUint64
+	}
+	sim := &simulator{
+		b:              s.b,
+		state:          state,
+		base:           base,
+		chainConfig:    s.b.ChainConfig(),
+		gasRemaining:   gasCap,
+		traceTransfers: opts.TraceTransfers,
+		validate:       opts.Validation,
+		fullTx:         opts.ReturnFullTransactions,
+	}
+	return sim.execute(ctx, opts.BlockStateCalls)
+}
+
+// execute runs the simulation of a series of blocks.
+func (sim *simulator) execute(ctx context.Context, blocks []simBlock) ([]map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var (
+		cancel  context.CancelFunc
+		timeout = sim.b.RPCEVMTimeout()
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	// Make sure the context is cancelled when the call has completed
+	// this makes sure resources are cleaned up.
+	defer cancel()
+
+	blocks, err := sim.sanitizeChain(blocks)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := sim.makeHeaders(blocks)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results = make([]map[string]interface{}, len(blocks))
+		parent  = sim.base
+		// Assume same total difficulty for all simulated blocks.
+		td = sim.b.GetTd(ctx, sim.base.Hash())
+	)
+	for bi, block := range blocks {
+		result, callResults, senders, err := sim.processBlock(ctx, &block, headers[bi], parent, headers[:bi], timeout)
+		if err != nil {
+			return nil, err
+		}
+		enc := RPCMarshalBlock(result, true, sim.fullTx, sim.chainConfig)
+		if sim.fullTx {
+			setSimulatedSenders(enc, senders)
+		}
+		enc["totalDifficulty"] = (*hexutil.Big)(td)
+		enc["calls"] = callResults
+		results[bi] = enc
+
+		parent = headers[bi]
+	}
+	return results, nil
+}
+
+func (sim *simulator) processBlock(ctx context.Context, block *simBlock, header, parent *types.Header, headers []*types.Header, timeout time.Duration) (*types.Block, []simCallResult, []common.Address, error) {
+	// Set header fields that depend only on parent block.
+	// Parent hash is needed for evm.GetHashFn to work.
+	header.ParentHash = parent.Hash()
+	if sim.chainConfig.IsLondon(header.Number) {
+		// In non-validation mode base fee is set to 0 if it is not overridden.
+		// This is because it creates an edge case in EVM where gasPrice < baseFee.
+		// Base fee could have been overridden.
+		if header.BaseFee == nil {
+			if sim.validate {
+				header.BaseFee = eip1559.CalcBaseFee(sim.chainConfig, parent)
+			} else {
+				header.BaseFee = big.NewInt(0)
+			}
+		}
+	}
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		var excess uint64
+		if sim.chainConfig.IsCancun(parent.Number, parent.Time) && parent.ExcessBlobGas != nil && parent.BlobGasUsed != nil {
+			excess = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		}
+		header.ExcessBlobGas = &excess
+	}
+	blockContext := core.NewEVMBlockContext(header, sim.newSimulatedChainContext(ctx, headers), &header.Coinbase)
+	if block.BlockOverrides != nil && block.BlockOverrides.BlobBaseFee != nil {
+		blockContext.BlobBaseFee = block.BlockOverrides.BlobBaseFee.ToInt()
+	}
+	// State overrides are applied prior to execution of a block
+	if err := block.StateOverrides.Apply(sim.state); err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		gasUsed, blobGasUsed uint64
+		txes                 = make([]*types.Transaction, len(block.Calls))
+		callResults          = make([]simCallResult, len(block.Calls))
+		receipts             = make([]*types.Receipt, len(block.Calls))
+		senders              = make([]common.Address, len(block.Calls))
+		tracer               = newTracer(sim.traceTransfers)
+		vmConfig             = &vm.Config{
+			NoBaseFee: !sim.validate,
+			Tracer:    tracer,
+		}
+		evm = vm.NewEVM(blockContext, vm.TxContext{GasPrice: new(big.Int)}, sim.state, sim.chainConfig, *vmConfig)
+	)
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, evm, sim.state)
+	}
+	// Cancel the EVM when the context is done, even if it has finished.
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	gp := new(core.GasPool).AddGas(header.GasLimit)
+	for i, call := range block.Calls {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		msg, tx, err := sim.sanitizeCall(&call, header, gp)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		txes[i], senders[i] = tx, msg.From
+
+		nonce := sim.state.GetNonce(msg.From)
+		sim.state.SetTxContext(tx.Hash(), i)
+		evm.Reset(core.NewEVMTxContext(msg), sim.state)
+		result, err := core.ApplyMessage(evm, msg, gp)
+		if err != nil {
+			return nil, nil, nil, txValidationError(err)
+		}
+		if evm.Cancelled() {
+			return nil, nil, nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		// Update the state with pending changes.
+		var root []byte
+		if sim.chainConfig.IsByzantium(header.Number) {
+			sim.state.Finalise(true)
+		} else {
+			root = sim.state.IntermediateRoot(sim.chainConfig.IsEIP158(header.Number)).Bytes()
+		}
+		gasUsed += result.UsedGas
+		sim.gasRemaining -= result.UsedGas
+		if tx.Type() == types.BlobTxType {
+			blobGasUsed += tx.BlobGas()
+		}
+
+		logs := sim.state.GetLogs(tx.Hash(), header.Number.Uint64(), common.Hash{})
+		if sim.traceTransfers {
+			logs = tracer.logs
+			for _, l := range logs {
+				l.TxHash, l.TxIndex, l.BlockNumber = tx.Hash(), uint(i), header.Number.Uint64()
+			}
+		}
+		callRes := simCallResult{ReturnValue: result.Return(), Logs: logs, GasUsed: hexutil.Uint64(result.UsedGas)}
+		if result.Failed() {
+			callRes.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			callRes.Logs = []*types.Log{}
+			if errors.Is(result.Err, vm.ErrExecutionReverted) {
+				// If the result contains a revert reason, try to unpack it.
+				revertErr := newRevertError(result.Revert())
+				callRes.Error = &callError{Message: revertErr.Error(), Code: errCodeReverted, Data: revertErr.ErrorData().(string)}
+			} else {
+				callRes.Error = &callError{Message: result.Err.Error(), Code: errCodeVMError}
+			}
+		} else {
+			callRes.Status = hexutil.Uint64(types.ReceiptStatusSuccessful)
+		}
+		callResults[i] = callRes
+
+		receipt := &types.Receipt{
+			Type:              tx.Type(),
+			PostState:         root,
+			Status:            uint64(callRes.Status),
+			CumulativeGasUsed: gasUsed,
+			Logs:              callRes.Logs,
+			TxHash:            tx.Hash(),
+			GasUsed:           result.UsedGas,
+			BlockNumber:       header.Number,
+			TransactionIndex:  uint(i),
+		}
+		if msg.To == nil {
+			receipt.ContractAddress = crypto.CreateAddress(msg.From, nonce)
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		receipts[i] = receipt
+	}
+	header.Root = sim.state.IntermediateRoot(true)
+	header.GasUsed = gasUsed
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		header.BlobGasUsed = &blobGasUsed
+	}
+	var withdrawals types.Withdrawals
+	if sim.chainConfig.IsShanghai(header.Number, header.Time) {
+		withdrawals = make([]*types.Withdrawal, 0)
+	}
+	b := types.NewBlockWithWithdrawals(header, txes, nil, receipts, withdrawals, trie.NewStackTrie(nil))
+	repairLogs(callResults, b.Hash())
+	// The following blocks look up the hash of the final header
+	*header = *b.Header()
+	return b, callResults, senders, nil
+}
+
+// repairLogs updates the block hash in the logs present in the result of a simulated
+// block. This is needed as the logs are collected before the block is sealed.
+func repairLogs(calls []simCallResult, hash common.Hash) {
+	var logIndex uint
+	for i := range calls {
+		for j := range calls[i].Logs {
+			calls[i].Logs[j].BlockHash = hash
+			calls[i].Logs[j].Index = logIndex
+			logIndex++
+		}
+	}
+}
+
+// sanitizeCall fills in the defaults of the call and converts it to the message to
+// execute and the transaction of the simulated block.
+func (sim *simulator) sanitizeCall(call *TransactionArgs, header *types.Header, gp *core.GasPool) (*core.Message, *types.Transaction, error) {
+	if call.Nonce == nil {
+		nonce := sim.state.GetNonce(call.from())
+		call.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	// Let the call use the rest of the block and of the gas cap unless specified.
+	if call.Gas == nil {
+		remaining := gp.Gas()
+		if sim.gasRemaining < remaining {
+			remaining = sim.gasRemaining
+		}
+		call.Gas = (*hexutil.Uint64)(&remaining)
+	}
+	if gp.Gas() < uint64(*call.Gas) {
+		return nil, nil, &blockGasLimitReachedError{fmt.Sprintf("block gas limit reached: remaining: %d, required: %d", gp.Gas(), *call.Gas)}
+	}
+	if sim.gasRemaining < uint64(*call.Gas) {
+		return nil, nil, &clientLimitExceededError{message: fmt.Sprintf("gas cap reached: remaining: %d, required: %d", sim.gasRemaining, *call.Gas)}
+	}
+	msg, err := call.ToMessage(0, header.BaseFee)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg.Nonce = uint64(*call.Nonce)
+	msg.SkipAccountChecks = !sim.validate
+	return msg, sim.transaction(msg), nil
+}
+
+// transaction returns the unsigned transaction of the message, identifying the call in
+// the simulated block.
+func (sim *simulator) transaction(msg *core.Message) *types.Transaction {
+	if len(msg.BlobHashes) > 0 && msg.To != nil {
+		return types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(sim.chainConfig.ChainID),
+			Nonce:      msg.Nonce,
+			GasTipCap:  uint256.MustFromBig(msg.GasTipCap),
+			GasFeeCap:  uint256.MustFromBig(msg.GasFeeCap),
+			Gas:        msg.GasLimit,
+			To:         *msg.To,
+			Value:      uint256.MustFromBig(msg.Value),
+			Data:       msg.Data,
+			AccessList: msg.AccessList,
+			BlobFeeCap: uint256.MustFromBig(msg.BlobGasFeeCap),
+			BlobHashes: msg.BlobHashes,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    sim.chainConfig.ChainID,
+		Nonce:      msg.Nonce,
+		GasTipCap:  msg.GasTipCap,
+		GasFeeCap:  msg.GasFeeCap,
+		Gas:        msg.GasLimit,
+		To:         msg.To,
+		Value:      msg.Value,
+		Data:       msg.Data,
+		AccessList: msg.AccessList,
+	})
+}
+
+// setSimulatedSenders sets the senders of the unsigned transactions of a marshalled
+// simulated block.
+func setSimulatedSenders(block map[string]interface{}, senders []common.Address) {
+	txs, ok := block["transactions"].([]interface{})
+	if !ok {
+		return
+	}
+	for i, tx := range txs {
+		if rpcTx, ok := tx.(*RPCTransaction); ok && i < len(senders) {
+			rpcTx.From = senders[i]
+		}
+	}
+}
+
+// sanitizeChain checks the ordering of the block numbers and timestamps, and fills the
+// gaps between block numbers with empty blocks.
+func (sim *simulator) sanitizeChain(blocks []simBlock) ([]simBlock, error) {
+	var (
+		res           = make([]simBlock, 0, len(blocks))
+		base          = sim.base
+		prevNumber    = base.Number
+		prevTimestamp = base.Time
+	)
+	for _, block := range blocks {
+		if block.BlockOverrides == nil {
+			block.BlockOverrides = new(BlockOverrides)
+		}
+		if block.BlockOverrides.Number == nil {
+			n := new(big.Int).Add(prevNumber, big.NewInt(1))
+			block.BlockOverrides.Number = (*hexutil.Big)(n)
+		}
+		diff := new(big.Int).Sub(block.BlockOverrides.Number.ToInt(), prevNumber)
+		if diff.Cmp(common.Big0) <= 0 {
+			return nil, &invalidBlockNumberError{fmt.Sprintf("block numbers must be in order: %d <= %d", block.BlockOverrides.Number.ToInt().Uint64(), prevNumber)}
+		}
+		if total := new(big.Int).Sub(block.BlockOverrides.Number.ToInt(), base.Number); total.Cmp(big.NewInt(maxSimulateBlocks)) > 0 {
+			return nil, &clientLimitExceededError{message: "too many blocks"}
+		}
+		if diff.Cmp(big.NewInt(1)) > 0 {
+			// Fill the gap with empty blocks.
+			gap := new(big.Int).Sub(diff, big.NewInt(1))
+			// Assign block number to the empty blocks.
+			for i := uint64(0); i < gap.Uint64(); i++ {
+				n := new(big.Int).Add(prevNumber, big.NewInt(int64(i+1)))
+				t := prevTimestamp + timestampIncrement
+				b := simBlock{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(n), Time: (*hexutil.Uint64)(&t)}}
+				prevTimestamp = t
+				res = append(res, b)
+			}
+		}
+		// Only append block after filling a potential gap.
+		prevNumber = block.BlockOverrides.Number.ToInt()
+		var t uint64
+		if block.BlockOverrides.Time == nil {
+			t = prevTimestamp + timestampIncrement
+			block.BlockOverrides.Time = (*hexutil.Uint64)(&t)
+		} else {
+			t = uint64(*block.BlockOverrides.Time)
+			if t <= prevTimestamp {
+				return nil, &invalidBlockTimestampError{fmt.Sprintf("block timestamps must be in order: %d <= %d", t, prevTimestamp)}
+			}
+		}
+		prevTimestamp = t
+		res = append(res, block)
+	}
+	return res, nil
+}
+
+// makeHeaders makes header object with preliminary fields based on a simulated block.
+// Some fields have to be filled post-execution. It assumes blocks are in order and
+// numbers have been validated.
+func (sim *simulator) makeHeaders(blocks []simBlock) ([]*types.Header, error) {
+	var (
+		res    = make([]*types.Header, len(blocks))
+		base   = sim.base
+		header = base
+	)
+	for bi, block := range blocks {
+		if block.BlockOverrides == nil || block.BlockOverrides.Number == nil {
+			return nil, errors.New("empty block number")
+		}
+		overrides := block.BlockOverrides
+
+		var withdrawalsHash *common.Hash
+		if sim.chainConfig.IsShanghai(overrides.Number.ToInt(), (uint64)(*overrides.Time)) {
+			withdrawalsHash = &types.EmptyWithdrawalsHash
+		}
+		var parentBeaconRoot *common.Hash
+		if sim.chainConfig.IsCancun(overrides.Number.ToInt(), (uint64)(*overrides.Time)) {
+			parentBeaconRoot = &common.Hash{}
+		}
+		header = overrides.MakeHeader(&types.Header{
+			UncleHash:        types.EmptyUncleHash,
+			ReceiptHash:      types.EmptyReceiptsHash,
+			TxHash:           types.EmptyTxsHash,
+			Coinbase:         header.Coinbase,
+			Difficulty:       header.Difficulty,
+			GasLimit:         header.GasLimit,
+			WithdrawalsHash:  withdrawalsHash,
+			ParentBeaconRoot: parentBeaconRoot,
+		})
+		res[bi] = header
+	}
+	return res, nil
+}
+
+// simChainHeadReader resolves the headers of the simulated blocks, next to the headers
+// of the chain, for the BLOCKHASH opcode.
+type simChainHeadReader struct {
+	*ChainContext
+	headers []*types.Header
+}
+
+func (sim *simulator) newSimulatedChainContext(ctx context.Context, headers []*types.Header) *simChainHeadReader {
+	return &simChainHeadReader{ChainContext: NewChainContext(ctx, sim.b), headers: headers}
+}
+
+func (m *simChainHeadReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	for _, header := range m.headers {
+		if header.Number.Uint64() == number && header.Hash() == hash {
+			return header
+		}
+	}
+	return m.ChainContext.GetHeader(hash, number)
+}
@@ -0,0 +1,144 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateV1(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(3)
+		// logs 0x2a with topic 0xff
+		logger = common.HexToAddress("0x1066e7")
+		// reverts without data
+		reverter = common.HexToAddress("0x7e7e7")
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				logger:           {Code: common.FromHex("0x602a60005260ff60206000a100")},
+				reverter:         {Code: common.FromHex("0x60006000fd")},
+			},
+		}
+	)
+	backend := newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	})
+	api := NewBlockChainAPI(backend)
+	head := backend.chain.CurrentBlock()
+
+	value := big.NewInt(1000)
+	balance := (*hexutil.Big)(big.NewInt(params.Ether))
+	third := hexutil.Big(*new(big.Int).Add(head.Number, big.NewInt(3)))
+	results, err := api.SimulateV1(context.Background(), simOpts{
+		BlockStateCalls: []simBlock{
+			{
+				StateOverrides: &StateOverride{accounts[1].addr: OverrideAccount{Balance: &balance}},
+				Calls: []TransactionArgs{
+					{From: &accounts[1].addr, To: &accounts[2].addr, Value: (*hexutil.Big)(value)},
+					{From: &accounts[2].addr, To: &logger},
+					{From: &accounts[2].addr, To: &reverter},
+				},
+			},
+			{
+				// The gap before the block is filled with an empty block.
+				BlockOverrides: &BlockOverrides{Number: &third},
+				Calls: []TransactionArgs{
+					// Spends the balance received in the first block.
+					{From: &accounts[2].addr, To: &accounts[0].addr, Value: (*hexutil.Big)(value)},
+				},
+			},
+		},
+		TraceTransfers: true,
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	var parent common.Hash = head.Hash()
+	for i, block := range results {
+		require.Equal(t, head.Number.Uint64()+uint64(i)+1, block["number"].(*hexutil.Big).ToInt().Uint64())
+		require.Equal(t, parent, block["parentHash"])
+		parent = block["hash"].(common.Hash)
+	}
+
+	calls := results[0]["calls"].([]simCallResult)
+	require.Len(t, calls, 3)
+	require.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), calls[0].Status)
+	require.Equal(t, hexutil.Uint64(params.TxGas), calls[0].GasUsed)
+	require.Len(t, calls[0].Logs, 1)
+	transfer := calls[0].Logs[0]
+	require.Equal(t, transferAddress, transfer.Address)
+	require.Equal(t, []common.Hash{transferTopic, common.BytesToHash(accounts[1].addr.Bytes()), common.BytesToHash(accounts[2].addr.Bytes())}, transfer.Topics)
+	require.Equal(t, common.BigToHash(value).Bytes(), transfer.Data)
+	require.Equal(t, results[0]["hash"], transfer.BlockHash)
+
+	require.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), calls[1].Status)
+	require.Len(t, calls[1].Logs, 1)
+	require.Equal(t, logger, calls[1].Logs[0].Address)
+	require.Equal(t, []common.Hash{common.BigToHash(big.NewInt(0xff))}, calls[1].Logs[0].Topics)
+	require.Equal(t, common.BigToHash(big.NewInt(0x2a)).Bytes(), calls[1].Logs[0].Data)
+	require.Equal(t, uint(1), calls[1].Logs[0].Index)
+
+	require.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), calls[2].Status)
+	require.Equal(t, errCodeReverted, calls[2].Error.Code)
+	require.Empty(t, calls[2].Logs)
+
+	require.Empty(t, results[1]["calls"])
+	calls = results[2]["calls"].([]simCallResult)
+	require.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), calls[0].Status, calls[0].Error)
+}
+
+func TestSimulateV1Errors(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+	)
+	backend := newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	})
+	api := NewBlockChainAPI(backend)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	head := backend.chain.CurrentBlock()
+
+	_, err := api.SimulateV1(context.Background(), simOpts{}, &latest)
+	require.Error(t, err)
+
+	// Block numbers must increase.
+	past := hexutil.Big(*head.Number)
+	_, err = api.SimulateV1(context.Background(), simOpts{
+		BlockStateCalls: []simBlock{{BlockOverrides: &BlockOverrides{Number: &past}}},
+	}, &latest)
+	var numberErr *invalidBlockNumberError
+	require.True(t, errors.As(err, &numberErr), err)
+
+	// The nonces of the calls are checked in validation mode only.
+	nonce := hexutil.Uint64(5)
+	calls := []TransactionArgs{{From: &accounts[0].addr, To: &accounts[1].addr, Nonce: &nonce}}
+	_, err = api.SimulateV1(context.Background(), simOpts{BlockStateCalls: []simBlock{{Calls: calls}}}, &latest)
+	require.NoError(t, err)
+
+	calls = []TransactionArgs{{From: &accounts[0].addr, To: &accounts[1].addr, Nonce: &nonce}}
+	_, err = api.SimulateV1(context.Background(), simOpts{BlockStateCalls: []simBlock{{Calls: calls}}, Validation: true}, &latest)
+	var txErr *invalidTxError
+	require.True(t, errors.As(err, &txErr), err)
+	require.Equal(t, errCodeNonceTooHigh, txErr.Code)
+}
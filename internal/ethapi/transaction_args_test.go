@@ -264,7 +264,7 @@ func (b *backendMock) SendMegabundle(ctx context.Context, txs types.Transactions
 	return nil
 }
 
-func (b *backendMock) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+func (b *backendMock) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash, topOfBlock bool) error {
 	return nil
 }
 
@@ -375,6 +375,7 @@ func (b *backendMock) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrO
 	return nil, nil, nil
 }
 func (b *backendMock) PendingBlockAndReceipts() (*types.Block, types.Receipts) { return nil, nil }
+func (b *backendMock) BestPendingBlock() *types.Block                          { return nil }
 func (b *backendMock) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	return nil, nil
 }
@@ -412,6 +413,7 @@ func (b *backendMock) TxPoolContentFrom(addr common.Address) ([]*types.Transacti
 }
 func (b *backendMock) SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription      { return nil }
 func (b *backendMock) BloomStatus() (uint64, uint64)                                        { return 0, 0 }
+func (b *backendMock) LogIndexStatus() (uint64, uint64)                                     { return 0, 0 }
 func (b *backendMock) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {}
 func (b *backendMock) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription         { return nil }
 func (b *backendMock) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
@@ -420,5 +422,11 @@ func (b *backendMock) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Su
 func (b *backendMock) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return nil
 }
+func (b *backendMock) SubscribeNewBundlesEvent(ch chan<- core.NewBundlesEvent) event.Subscription {
+	return nil
+}
+func (b *backendMock) SubscribePendingBlockEvent(ch chan<- core.NewPendingBlockEvent) event.Subscription {
+	return nil
+}
 
 func (b *backendMock) Engine() consensus.Engine { return nil }
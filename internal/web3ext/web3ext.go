@@ -153,6 +153,15 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'exportValidatorRegistrations',
+			call: 'admin_exportValidatorRegistrations'
+		}),
+		new web3._extend.Method({
+			name: 'importValidatorRegistrations',
+			call: 'admin_importValidatorRegistrations',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -705,6 +714,16 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'searcherReputation',
+			call: 'miner_searcherReputation',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'searcherReputations',
+			call: 'miner_searcherReputations'
+		}),
 	],
 	properties: []
 });
@@ -0,0 +1,178 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// blobOption is one way of filling blob space with the orders of a blobGroup.
+type blobOption struct {
+	blobs int
+	value *big.Int
+}
+
+// blobGroup is a set of mutually exclusive options, at most one of which is packed. The
+// first option of every group packs nothing.
+type blobGroup struct {
+	options []blobOption
+	// sender is the sender of the pooled transactions of the group, whose options pack an
+	// increasingly long prefix of its blob transactions. A zero sender denotes a bundle.
+	sender common.Address
+	bundle int
+}
+
+// selectBlobOrders treats blob gas as a second resource next to gas: it chooses the pooled
+// blob transactions and blob carrying bundles that maximise the total value of the block
+// within the blob limit, and drops the other ones from the orders handed to the greedy
+// algorithms. Left to themselves, the algorithms order by gas price and pack blob orders
+// first come first served, so a single well paying transaction can use up the blob space
+// of several orders that are worth more together.
+//
+// The value of an order is the payment to the fee recipient, which for pooled transactions
+// is estimated from their gas limit. The blob base fee is burnt rather than paid to the fee
+// recipient, so it does not add to the value, and orders compete for the blob space by the
+// profit per blob they pay. Transactions whose blob fee cap is below the blob base fee
+// cannot be included and are dropped. Only the prefix of the blob transactions of a sender
+// may be packed, as they have to be included in nonce order.
+func selectBlobOrders(env *environment, simBundles []types.SimulatedBundle, pending map[common.Address][]*txpool.LazyTransaction) ([]types.SimulatedBundle, map[common.Address][]*txpool.LazyTransaction) {
+	header := env.header
+	if header.ExcessBlobGas == nil {
+		return simBundles, pending
+	}
+	var (
+		capacity = params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob - env.blobs
+		blobFee  = eip4844.CalcBlobFee(*header.ExcessBlobGas)
+		groups   []blobGroup
+		// cutoff is the number of transactions of a sender that can be packed at most.
+		cutoff = make(map[common.Address]int)
+	)
+	for sender, txs := range pending {
+		group := blobGroup{sender: sender, options: []blobOption{{value: new(big.Int)}}}
+		last := group.options[0]
+		for i, lazy := range txs {
+			if lazy.BlobGas == 0 {
+				continue
+			}
+			tx := lazy.Resolve()
+			if tx == nil || tx.BlobGasFeeCap().Cmp(blobFee) < 0 {
+				cutoff[sender] = i
+				break
+			}
+			tip, err := tx.EffectiveGasTip(header.BaseFee)
+			if err != nil {
+				cutoff[sender] = i
+				break
+			}
+			blobs := len(tx.BlobHashes())
+			value := new(big.Int).Mul(tip, new(big.Int).SetUint64(tx.Gas()))
+			last = blobOption{blobs: last.blobs + blobs, value: value.Add(value, last.value)}
+			group.options = append(group.options, last)
+		}
+		if len(group.options) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	for i, bundle := range simBundles {
+		blobs := 0
+		for _, tx := range bundle.OriginalBundle.Txs {
+			blobs += len(tx.BlobHashes())
+		}
+		if blobs == 0 {
+			continue
+		}
+		groups = append(groups, blobGroup{bundle: i, options: []blobOption{{value: new(big.Int)}, {blobs: blobs, value: bundle.TotalEth.ToBig()}}})
+	}
+	if len(groups) == 0 && len(cutoff) == 0 {
+		return simBundles, pending
+	}
+
+	chosen := packBlobGroups(groups, capacity)
+	droppedBundles := make(map[int]struct{})
+	for i, group := range groups {
+		if group.sender == (common.Address{}) {
+			if chosen[i] == 0 {
+				droppedBundles[group.bundle] = struct{}{}
+			}
+			continue
+		}
+		// Cut the transactions of the sender before the first blob transaction left out.
+		blobTxs := 0
+		for j, lazy := range pending[group.sender] {
+			if lazy.BlobGas == 0 {
+				continue
+			}
+			if blobTxs == chosen[i] {
+				if limit, ok := cutoff[group.sender]; !ok || j < limit {
+					cutoff[group.sender] = j
+				}
+				break
+			}
+			blobTxs++
+		}
+	}
+
+	selectedTxs := make(map[common.Address][]*txpool.LazyTransaction, len(pending))
+	for sender, txs := range pending {
+		if limit, ok := cutoff[sender]; ok {
+			txs = txs[:limit]
+		}
+		if len(txs) > 0 {
+			selectedTxs[sender] = txs
+		}
+	}
+	selectedBundles := simBundles
+	if len(droppedBundles) > 0 {
+		selectedBundles = make([]types.SimulatedBundle, 0, len(simBundles)-len(droppedBundles))
+		for i, bundle := range simBundles {
+			if _, ok := droppedBundles[i]; !ok {
+				selectedBundles = append(selectedBundles, bundle)
+			}
+		}
+	}
+	log.Trace("Selected blob orders", "candidates", len(groups), "droppedBundles", len(droppedBundles), "blobFee", blobFee)
+	return selectedBundles, selectedTxs
+}
+
+// packBlobGroups solves the multiple choice knapsack over the blob capacity, and returns
+// the index of the option chosen for each group.
+func packBlobGroups(groups []blobGroup, capacity int) []int {
+	if capacity < 0 {
+		capacity = 0
+	}
+	// best[i][c] is the highest value packing the first i groups into c blobs, and
+	// choice[i][c] the option of group i-1 achieving it.
+	best := make([][]*big.Int, len(groups)+1)
+	choice := make([][]int, len(groups)+1)
+	best[0] = make([]*big.Int, capacity+1)
+	for c := range best[0] {
+		best[0][c] = new(big.Int)
+	}
+	for i, group := range groups {
+		best[i+1] = make([]*big.Int, capacity+1)
+		choice[i+1] = make([]int, capacity+1)
+		for c := 0; c <= capacity; c++ {
+			best[i+1][c] = best[i][c]
+			for j, option := range group.options[1:] {
+				if option.blobs > c {
+					continue
+				}
+				value := new(big.Int).Add(best[i][c-option.blobs], option.value)
+				if value.Cmp(best[i+1][c]) > 0 {
+					best[i+1][c], choice[i+1][c] = value, j+1
+				}
+			}
+		}
+	}
+	chosen := make([]int, len(groups))
+	for i, c := len(groups), capacity; i > 0; i-- {
+		chosen[i-1] = choice[i][c]
+		c -= groups[i-1].options[chosen[i-1]].blobs
+	}
+	return chosen
+}
@@ -0,0 +1,106 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBlobOrders(t *testing.T) {
+	statedb, chData, signers := genTestSetup(GasLimit)
+	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+	env.signer = types.NewCancunSigner(chData.chainConfig.ChainID)
+	env.header.ExcessBlobGas = new(uint64)
+
+	var nonces [10]uint64
+	blobTx := func(sender, blobs int, tip int64, gas uint64, blobFeeCap int64) *txpool.LazyTransaction {
+		tx := types.MustSignNewTx(signers.signers[sender], env.signer, &types.BlobTx{
+			ChainID:    uint256.MustFromBig(chData.chainConfig.ChainID),
+			Nonce:      nonces[sender],
+			GasTipCap:  uint256.NewInt(uint64(tip)),
+			GasFeeCap:  uint256.NewInt(uint64(tip) + 1),
+			Gas:        gas,
+			BlobFeeCap: uint256.NewInt(uint64(blobFeeCap)),
+			BlobHashes: make([]common.Hash, blobs),
+		})
+		nonces[sender]++
+		return &txpool.LazyTransaction{
+			Hash:      tx.Hash(),
+			Tx:        tx,
+			Time:      tx.Time(),
+			GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+			GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+			Gas:       tx.Gas(),
+			BlobGas:   tx.BlobGas(),
+		}
+	}
+
+	// The best paying transaction per gas uses all the blob space (210000 gwei for 6 blobs),
+	// while the two others pay more in total (500000 gwei for 3 blobs each). The greedy
+	// algorithms would only pack the first one.
+	pending := map[common.Address][]*txpool.LazyTransaction{
+		signers.addresses[1]: {blobTx(1, 6, 10*params.GWei, 21000, 1)},
+		signers.addresses[2]: {blobTx(2, 3, 5*params.GWei, 100000, 1)},
+		signers.addresses[3]: {blobTx(3, 3, 5*params.GWei, 100000, 1)},
+		// Priced out by the blob base fee.
+		signers.addresses[4]: {blobTx(4, 1, 20*params.GWei, 1000000, 0)},
+	}
+	_, selected := selectBlobOrders(env, nil, pending)
+	require.Equal(t, map[common.Address][]*txpool.LazyTransaction{
+		signers.addresses[2]: pending[signers.addresses[2]],
+		signers.addresses[3]: pending[signers.addresses[3]],
+	}, selected)
+
+	// A cheap blob transaction is packed to reach a valuable one of the same sender with a
+	// higher nonce, and the rest of the blob space goes to the bundle that fits.
+	pending = map[common.Address][]*txpool.LazyTransaction{
+		signers.addresses[5]: {blobTx(5, 1, 1, 21000, 1), blobTx(5, 4, 10*params.GWei, 100000, 1)},
+		signers.addresses[6]: {blobTx(6, 5, 2*params.GWei, 100000, 1)},
+	}
+	bundleTx := func(sender, blobs int) *types.Transaction {
+		return blobTx(sender, blobs, 1, 21000, 1).Tx
+	}
+	bundles := []types.SimulatedBundle{
+		{TotalEth: uint256.NewInt(100000 * params.GWei), OriginalBundle: types.MevBundle{Txs: types.Transactions{bundleTx(7, 2)}}},
+		{TotalEth: uint256.NewInt(params.GWei), OriginalBundle: types.MevBundle{Txs: types.Transactions{bundleTx(8, 1)}}},
+		{TotalEth: uint256.NewInt(params.GWei), OriginalBundle: types.MevBundle{Txs: types.Transactions{bundleTx(9, 3)}}},
+	}
+	selectedBundles, selected := selectBlobOrders(env, bundles, pending)
+	require.Equal(t, map[common.Address][]*txpool.LazyTransaction{signers.addresses[5]: pending[signers.addresses[5]]}, selected)
+	require.Len(t, selectedBundles, 1)
+	require.Equal(t, bundles[1].OriginalBundle.Txs, selectedBundles[0].OriginalBundle.Txs)
+
+	// The burnt blob base fee is not profit: with 2 blobs left, the transaction paying 10 gwei
+	// for 1 blob beats the one paying 8 gwei for 2 blobs, even though the latter burns
+	// about 2.9 gwei more.
+	env.blobs = 4
+	*env.header.ExcessBlobGas = 10 * params.BlobTxBlobGaspriceUpdateFraction
+	pending = map[common.Address][]*txpool.LazyTransaction{
+		signers.addresses[1]: {blobTx(1, 1, 100000, 100000, params.GWei)},
+		signers.addresses[2]: {blobTx(2, 2, 80000, 100000, params.GWei)},
+	}
+	selectedBundles, selected = selectBlobOrders(env, nil, pending)
+	require.Empty(t, selectedBundles)
+	require.Equal(t, map[common.Address][]*txpool.LazyTransaction{signers.addresses[1]: pending[signers.addresses[1]]}, selected)
+
+	// The same goes for bundles, valued by the payment to the fee recipient alone.
+	bundles = []types.SimulatedBundle{
+		{TotalEth: uint256.NewInt(8 * params.GWei), OriginalBundle: types.MevBundle{Txs: types.Transactions{blobTx(3, 2, 1, 21000, params.GWei).Tx}}},
+		{TotalEth: uint256.NewInt(10 * params.GWei), OriginalBundle: types.MevBundle{Txs: types.Transactions{blobTx(4, 1, 1, 21000, params.GWei).Tx}}},
+	}
+	selectedBundles, _ = selectBlobOrders(env, bundles, nil)
+	require.Equal(t, bundles[1:], selectedBundles)
+	env.blobs = 0
+
+	// Blocks without blobs are left alone.
+	env.header.ExcessBlobGas = nil
+	selectedBundles, selected = selectBlobOrders(env, bundles, pending)
+	require.Equal(t, bundles, selectedBundles)
+	require.Equal(t, pending, selected)
+}
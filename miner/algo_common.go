@@ -37,6 +37,7 @@ var defaultAlgorithmConfig = algorithmConfig{
 	ExpectedProfit:         nil,
 	ProfitThresholdPercent: defaultProfitThresholdPercent,
 	PriceCutoffPercent:     defaultPriceCutoffPercent,
+	TopOfBlockSlots:        defaultTopOfBlockSlots,
 }
 
 var emptyCodeHash = common.HexToHash("c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")
@@ -80,6 +81,11 @@ type algorithmConfig struct {
 	// is 10 (i.e. 10%), then the minimum effective gas price included in the same bucket as the top transaction
 	// is (1000 * 10%) = 100 wei.
 	PriceCutoffPercent int
+	// TopOfBlockSlots is the number of bundles flagged for the top of the block placed before any
+	// other order, by an auction on their coinbase payments. Zero lets them compete like other bundles.
+	TopOfBlockSlots int
+	// TopOfBlockMinBid is the minimum coinbase payment of the winning bids of the top of block auction.
+	TopOfBlockMinBid *big.Int
 }
 
 type chainData struct {
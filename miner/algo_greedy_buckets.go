@@ -224,10 +224,12 @@ func (b *greedyBucketsBuilder) mergeOrdersIntoEnvDiff(
 }
 
 func (b *greedyBucketsBuilder) buildBlock(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle, transactions map[common.Address][]*txpool.LazyTransaction) (*environment, []types.SimulatedBundle, []types.UsedSBundle) {
+	bids, simBundles := splitTopOfBlockBundles(simBundles, b.algoConf.TopOfBlockSlots, b.algoConf.TopOfBlockMinBid)
 	orders := newTransactionsByPriceAndNonce(b.inputEnvironment.signer, transactions, simBundles, simSBundles, b.inputEnvironment.header.BaseFee)
 	envDiff := newEnvironmentDiff(b.inputEnvironment.copy())
 	b.inputEnvironment.state.StopPrefetcher()
+	topBundles := commitTopOfBlockDiff(envDiff, bids, b.algoConf.TopOfBlockSlots, b.chainData, b.interrupt, b.algoConf)
 	usedBundles, usedSbundles := b.mergeOrdersIntoEnvDiff(envDiff, orders)
 	envDiff.applyToBaseEnv()
-	return envDiff.baseEnvironment, usedBundles, usedSbundles
+	return envDiff.baseEnvironment, append(topBundles, usedBundles...), usedSbundles
 }
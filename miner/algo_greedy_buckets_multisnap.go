@@ -245,6 +245,13 @@ func (b *greedyBucketsMultiSnapBuilder) mergeOrdersAndApplyToEnv(
 }
 
 func (b *greedyBucketsMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle, transactions map[common.Address][]*txpool.LazyTransaction) (*environment, []types.SimulatedBundle, []types.UsedSBundle) {
+	bids, simBundles := splitTopOfBlockBundles(simBundles, b.algoConf.TopOfBlockSlots, b.algoConf.TopOfBlockMinBid)
+	topBundles, err := commitTopOfBlockEnv(b.inputEnvironment, bids, b.algoConf.TopOfBlockSlots, b.chainData, b.algoConf)
+	if err != nil {
+		log.Error("Failed to commit top of block bundles", "err", err)
+		return b.inputEnvironment, nil, nil
+	}
 	orders := newTransactionsByPriceAndNonce(b.inputEnvironment.signer, transactions, simBundles, simSBundles, b.inputEnvironment.header.BaseFee)
-	return b.mergeOrdersAndApplyToEnv(orders)
+	env, usedBundles, usedSbundles := b.mergeOrdersAndApplyToEnv(orders)
+	return env, append(topBundles, usedBundles...), usedSbundles
 }
@@ -42,6 +42,7 @@ func newGreedyMultiSnapBuilder(
 }
 
 func (b *greedyMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle, transactions map[common.Address][]*txpool.LazyTransaction) (*environment, []types.SimulatedBundle, []types.UsedSBundle) {
+	bids, simBundles := splitTopOfBlockBundles(simBundles, b.algoConf.TopOfBlockSlots, b.algoConf.TopOfBlockMinBid)
 	orders := newTransactionsByPriceAndNonce(b.inputEnvironment.signer, transactions, simBundles, simSBundles, b.inputEnvironment.header.BaseFee)
 
 	var (
@@ -49,6 +50,12 @@ func (b *greedyMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle,
 		usedSbundles []types.UsedSBundle
 	)
 
+	usedBundles, err := commitTopOfBlockEnv(b.inputEnvironment, bids, b.algoConf.TopOfBlockSlots, b.chainData, b.algoConf)
+	if err != nil {
+		log.Error("Failed to commit top of block bundles", "err", err)
+		return b.inputEnvironment, nil, usedSbundles
+	}
+
 	changes, err := newEnvChanges(b.inputEnvironment)
 	if err != nil {
 		log.Error("Failed to create new environment changes", "err", err)
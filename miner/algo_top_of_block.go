@@ -0,0 +1,130 @@
+package miner
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/holiman/uint256"
+)
+
+// defaultTopOfBlockSlots is the number of bundles placed at the top of the block by the
+// top of block auction, which is disabled unless configured
+const defaultTopOfBlockSlots = 0
+
+// splitTopOfBlockBundles separates the bundles bidding for the top of the block from the
+// other bundles, ordering the bids of the sealed-bid auction by coinbase payment. Without
+// top of block slots, the flagged bundles compete like other bundles.
+//
+// The auction has a reserve price: a bid must pay at least minBid to the coinbase, and
+// be worth at least as much to the block as the most valuable other bundle, which it
+// could otherwise displace. Bids below the reserve compete like other bundles.
+func splitTopOfBlockBundles(simBundles []types.SimulatedBundle, slots int, minBid *big.Int) (bids, rest []types.SimulatedBundle) {
+	if slots <= 0 {
+		return nil, simBundles
+	}
+	var (
+		reserve = new(uint256.Int)
+		minBidU = new(uint256.Int)
+		flagged []types.SimulatedBundle
+	)
+	if minBid != nil {
+		minBidU.SetFromBig(minBid)
+	}
+	rest = make([]types.SimulatedBundle, 0, len(simBundles))
+	for _, bundle := range simBundles {
+		if bundle.OriginalBundle.TopOfBlock {
+			flagged = append(flagged, bundle)
+			continue
+		}
+		rest = append(rest, bundle)
+		if bundle.TotalEth != nil && bundle.TotalEth.Cmp(reserve) > 0 {
+			reserve = bundle.TotalEth
+		}
+	}
+	for _, bundle := range flagged {
+		if bundle.EthSentToCoinbase.Cmp(minBidU) < 0 || bundle.TotalEth.Cmp(reserve) < 0 {
+			log.Trace("Top of block bid below the reserve", "bundle", bundle.OriginalBundle.Hash, "ethToCoinbase", ethIntToFloat(bundle.EthSentToCoinbase), "reserve", ethIntToFloat(reserve))
+			rest = append(rest, bundle)
+			continue
+		}
+		bids = append(bids, bundle)
+	}
+	sort.SliceStable(bids, func(i, j int) bool {
+		if c := bids[i].EthSentToCoinbase.Cmp(bids[j].EthSentToCoinbase); c != 0 {
+			return c > 0
+		}
+		if c := bids[i].TotalEth.Cmp(bids[j].TotalEth); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(bids[i].OriginalBundle.Hash[:], bids[j].OriginalBundle.Hash[:]) < 0
+	})
+	return bids, rest
+}
+
+// commitTopOfBlockDiff commits the winning bids of the top of block auction before any
+// other order, trying the bids in order until the slots are filled. Losing bids are not
+// considered for the rest of the block.
+func commitTopOfBlockDiff(envDiff *environmentDiff, bids []types.SimulatedBundle, slots int,
+	chData chainData, interrupt *atomic.Int32, algoConf algorithmConfig,
+) []types.SimulatedBundle {
+	var winners []types.SimulatedBundle
+	for i := range bids {
+		if len(winners) == slots {
+			break
+		}
+		bid := &bids[i]
+		if err := envDiff.commitBundle(bid, chData, interrupt, algoConf); err != nil {
+			log.Trace("Could not apply top of block bundle", "bundle", bid.OriginalBundle.Hash, "err", err)
+			continue
+		}
+		log.Trace("Included top of block bundle", "bundle", bid.OriginalBundle.Hash, "position", len(winners), "ethToCoinbase", ethIntToFloat(bid.EthSentToCoinbase))
+		winners = append(winners, *bid)
+	}
+	return winners
+}
+
+// commitTopOfBlockEnv commits the winning bids of the top of block auction to the
+// environment, like commitTopOfBlockDiff.
+func commitTopOfBlockEnv(env *environment, bids []types.SimulatedBundle, slots int,
+	chData chainData, algoConf algorithmConfig,
+) ([]types.SimulatedBundle, error) {
+	if len(bids) == 0 {
+		return nil, nil
+	}
+	changes, err := newEnvChanges(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var winners []types.SimulatedBundle
+	for i := range bids {
+		if len(winners) == slots {
+			break
+		}
+		bid := &bids[i]
+		if err := changes.env.state.NewMultiTxSnapshot(); err != nil {
+			return nil, err
+		}
+		if err := changes.commitBundle(bid, chData, algoConf); err != nil {
+			log.Trace("Could not apply top of block bundle", "bundle", bid.OriginalBundle.Hash, "err", err)
+			if err := changes.env.state.MultiTxSnapshotRevert(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := changes.env.state.MultiTxSnapshotCommit(); err != nil {
+			return nil, err
+		}
+		log.Trace("Included top of block bundle", "bundle", bid.OriginalBundle.Hash, "position", len(winners), "ethToCoinbase", ethIntToFloat(bid.EthSentToCoinbase))
+		winners = append(winners, *bid)
+	}
+
+	if err := changes.apply(); err != nil {
+		return nil, err
+	}
+	return winners, nil
+}
@@ -0,0 +1,126 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopOfBlockAuction(t *testing.T) {
+	algos := []AlgoType{ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP}
+	for _, algo := range algos {
+		for _, slots := range []int{1, 2} {
+			statedb, chData, signers := genTestSetup(GasLimit)
+			env := newEnvironment(chData, statedb, signers.addresses[0], 21000*10, big.NewInt(1))
+
+			// A mempool transaction paying more per gas than any top of block bundle
+			mempoolTx := signers.signTx(1, 21000, big.NewInt(5), big.NewInt(10), signers.addresses[2], big.NewInt(0), []byte{})
+			txs := map[common.Address][]*txpool.LazyTransaction{
+				signers.addresses[1]: {{
+					Hash:      mempoolTx.Hash(),
+					Tx:        mempoolTx,
+					Time:      mempoolTx.Time(),
+					GasFeeCap: uint256.MustFromBig(mempoolTx.GasFeeCap()),
+					GasTipCap: uint256.MustFromBig(mempoolTx.GasTipCap()),
+					GasPrice:  uint256.MustFromBig(mempoolTx.GasPrice()),
+				}},
+			}
+
+			bid := func(signer int, nonce uint64, payment uint64) types.SimulatedBundle {
+				signers.nonces[signer] = nonce
+				tx := signers.signTx(signer, 21000, big.NewInt(1), big.NewInt(10), signers.addresses[2], big.NewInt(0), []byte{})
+				return types.SimulatedBundle{
+					MevGasPrice:       uint256.NewInt(1),
+					TotalEth:          uint256.NewInt(payment),
+					EthSentToCoinbase: uint256.NewInt(payment),
+					TotalGasUsed:      21000,
+					OriginalBundle: types.MevBundle{
+						Txs:        types.Transactions{tx},
+						Hash:       common.BigToHash(big.NewInt(int64(signer))),
+						TopOfBlock: true,
+					},
+				}
+			}
+			low, high := bid(3, 0, 10), bid(4, 0, 20)
+			// The highest bid cannot be committed, its nonce is too high
+			invalid := bid(5, 7, 30)
+			simBundles := []types.SimulatedBundle{low, invalid, high}
+
+			algoConf := defaultAlgorithmConfig
+			algoConf.TopOfBlockSlots = slots
+			var (
+				result      *environment
+				usedBundles []types.SimulatedBundle
+			)
+			switch algo {
+			case ALGO_GREEDY:
+				builder := newGreedyBuilder(chData.chain, chData.chainConfig, &algoConf, nil, env, nil, nil)
+				result, usedBundles, _ = builder.buildBlock(simBundles, nil, txs)
+			case ALGO_GREEDY_MULTISNAP:
+				builder := newGreedyMultiSnapBuilder(chData.chain, chData.chainConfig, &algoConf, nil, env, nil, nil)
+				result, usedBundles, _ = builder.buildBlock(simBundles, nil, txs)
+			case ALGO_GREEDY_BUCKETS:
+				builder := newGreedyBucketsBuilder(chData.chain, chData.chainConfig, &algoConf, nil, env, nil, nil)
+				result, usedBundles, _ = builder.buildBlock(simBundles, nil, txs)
+			case ALGO_GREEDY_BUCKETS_MULTISNAP:
+				builder := newGreedyBucketsMultiSnapBuilder(chData.chain, chData.chainConfig, &algoConf, nil, env, nil, nil)
+				result, usedBundles, _ = builder.buildBlock(simBundles, nil, txs)
+			}
+
+			// The winning bids open the block, in bid order, losing bids are dropped.
+			expected := []common.Hash{high.OriginalBundle.Txs[0].Hash()}
+			if slots == 2 {
+				expected = append(expected, low.OriginalBundle.Txs[0].Hash())
+			}
+			expected = append(expected, mempoolTx.Hash())
+
+			var included []common.Hash
+			for _, tx := range result.txs {
+				included = append(included, tx.Hash())
+			}
+			require.Equal(t, expected, included, "algo %s, slots %d", algo, slots)
+			require.Len(t, usedBundles, slots, "algo %s, slots %d", algo, slots)
+			require.Equal(t, high.OriginalBundle.Hash, usedBundles[0].OriginalBundle.Hash)
+		}
+	}
+}
+
+func TestTopOfBlockReserve(t *testing.T) {
+	bundle := func(hash byte, value uint64, topOfBlock bool) types.SimulatedBundle {
+		return types.SimulatedBundle{
+			TotalEth:          uint256.NewInt(value),
+			EthSentToCoinbase: uint256.NewInt(value),
+			OriginalBundle:    types.MevBundle{Hash: common.Hash{hash}, TopOfBlock: topOfBlock},
+		}
+	}
+	var (
+		regular = bundle(1, 15, false)
+		low     = bundle(2, 10, true)
+		high    = bundle(3, 20, true)
+		hashes  = func(bundles []types.SimulatedBundle) (res []common.Hash) {
+			for _, b := range bundles {
+				res = append(res, b.OriginalBundle.Hash)
+			}
+			return res
+		}
+	)
+	// Bids worth less than the most valuable other bundle compete like other bundles.
+	bids, rest := splitTopOfBlockBundles([]types.SimulatedBundle{low, regular, high}, 1, nil)
+	require.Equal(t, hashes([]types.SimulatedBundle{high}), hashes(bids))
+	require.Equal(t, hashes([]types.SimulatedBundle{regular, low}), hashes(rest))
+
+	// So do the bids paying less than the minimum bid.
+	bids, rest = splitTopOfBlockBundles([]types.SimulatedBundle{low, regular, high}, 1, big.NewInt(25))
+	require.Empty(t, bids)
+	require.Equal(t, hashes([]types.SimulatedBundle{regular, low, high}), hashes(rest))
+
+	// Without slots, there is no auction.
+	bids, rest = splitTopOfBlockBundles([]types.SimulatedBundle{low, regular, high}, 0, nil)
+	require.Empty(t, bids)
+	require.Len(t, rest, 3)
+}
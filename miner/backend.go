@@ -914,8 +914,18 @@ func (fb *filterBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeNewBundlesEvent(ch chan<- core.NewBundlesEvent) event.Subscription {
+	return nullSubscription()
+}
+
+func (fb *filterBackend) SubscribePendingBlockEvent(ch chan<- core.NewPendingBlockEvent) event.Subscription {
+	return nullSubscription()
+}
+
 func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
 
+func (fb *filterBackend) LogIndexStatus() (uint64, uint64) { return 4096, 0 }
+
 func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
 	panic("not supported")
 }
@@ -0,0 +1,119 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// BacktestResult is the outcome of replaying a recorded slot with one algorithm.
+type BacktestResult struct {
+	Algo      AlgoType
+	Block     *types.Block
+	Value     *big.Int      // payment to the proposer fee recipient
+	GasUsed   uint64        // gas used by the block
+	Txs       int           // number of transactions in the block
+	Bundles   int           // number of bundles in the block
+	SBundles  int           // number of sbundles in the block
+	BuildTime time.Duration // time spent simulating the bundles and building the block
+}
+
+// Backtest replays the recorded slot with the given algorithm on top of the recorded
+// parent, whose state must be available in the chain. Only the greedy algorithms are
+// supported, the mev-geth algorithm is deprecated.
+func Backtest(chain *core.BlockChain, config *Config, record *SlotRecord, algo AlgoType) (*BacktestResult, error) {
+	if algo == ALGO_MEV_GETH {
+		return nil, errors.New("backtesting is not supported for the mev-geth algorithm")
+	}
+	if config.BuilderTxSigningKey == nil {
+		return nil, errors.New("builder tx signing key is not set")
+	}
+	w := newBacktestWorker(chain, config, algo)
+
+	validatorCoinbase := record.Attributes.FeeRecipient
+	work, err := w.prepareWork(record.generateParams(w.coinbase))
+	if err != nil {
+		return nil, err
+	}
+	defer work.discard()
+
+	reserve, err := w.proposerTxPrepare(work, &validatorCoinbase)
+	if err != nil {
+		return nil, err
+	}
+	pending := record.pending(work.signer)
+
+	start := time.Now()
+	simBundles, simSBundles, err := w.simulateBundles(work, record.MevBundles, record.SBundles, nil)
+	if err != nil {
+		return nil, err
+	}
+	newEnv, blockBundles, usedSbundles, err := w.buildBlockWithAlgo(algo, nil, work, simBundles, simSBundles, pending)
+	if err != nil {
+		return nil, err
+	}
+	*work = *newEnv
+	buildTime := time.Since(start)
+
+	noTxs := len(work.txs) == 0
+	if !noTxs {
+		if err := w.proposerTxCommit(work, &validatorCoinbase, reserve); err != nil {
+			return nil, err
+		}
+	}
+	block, value, err := w.finalizeBlock(work, record.Attributes.Withdrawals, validatorCoinbase, noTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	var okSbundles int
+	for _, sb := range usedSbundles {
+		if sb.Success {
+			okSbundles++
+		}
+	}
+	return &BacktestResult{
+		Algo:      algo,
+		Block:     block,
+		Value:     value,
+		GasUsed:   block.GasUsed(),
+		Txs:       len(block.Transactions()),
+		Bundles:   len(blockBundles),
+		SBundles:  okSbundles,
+		BuildTime: buildTime,
+	}, nil
+}
+
+// newBacktestWorker creates a worker that only builds blocks on request, without any
+// of the background loops or txpool subscriptions of a regular worker.
+func newBacktestWorker(chain *core.BlockChain, config *Config, algo AlgoType) *worker {
+	blockList := make(map[common.Address]struct{})
+	for _, address := range config.Blocklist {
+		blockList[address] = struct{}{}
+	}
+	tip := new(uint256.Int)
+	if config.GasPrice != nil {
+		tip = uint256.MustFromBig(config.GasPrice)
+	}
+	return &worker{
+		config:      config,
+		chainConfig: chain.Config(),
+		engine:      chain.Engine(),
+		chain:       chain,
+		blockList:   blockList,
+		extra:       config.ExtraData,
+		tip:         tip,
+		coinbase:    crypto.PubkeyToAddress(config.BuilderTxSigningKey.PublicKey),
+		flashbots: &flashbotsData{
+			isFlashbots: true,
+			algoType:    algo,
+			bundleCache: NewBundleCache(),
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndBacktest(t *testing.T) {
+	chainConfig := new(params.ChainConfig)
+	*chainConfig = *ethashChainConfig
+	chainConfig.TerminalTotalDifficulty = big.NewInt(0)
+	engine := ethash.NewFaker()
+
+	searcherKey, _ := crypto.GenerateKey()
+	alloc := types.GenesisAlloc{
+		testBankAddress: {Balance: testBankFunds},
+		crypto.PubkeyToAddress(searcherKey.PublicKey): {Balance: testBankFunds},
+	}
+	b := newTestWorkerBackend(t, chainConfig, engine, rawdb.NewMemoryDatabase(), alloc, 0, params.GenesisGasLimit)
+
+	builderKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	config := *testConfig
+	config.AlgoType = ALGO_GREEDY
+	config.BuilderTxSigningKey = builderKey
+	config.RecordDir = t.TempDir()
+
+	w := newWorker(&config, chainConfig, engine, b, new(event.TypeMux), nil, false, &flashbotsData{
+		isFlashbots: true,
+		algoType:    config.AlgoType,
+		bundleCache: NewBundleCache(),
+	})
+	defer w.close()
+
+	pendingTx := b.newRandomTx(false, testUserAddress, 1000, testBankKey, 0, big.NewInt(10*params.InitialBaseFee))
+	require.Empty(t, b.txPool.Add([]*types.Transaction{pendingTx}, true, true, false)[0])
+	bundleTx := b.newRandomTx(false, testUserAddress, 1000, searcherKey, 0, big.NewInt(20*params.InitialBaseFee))
+	blockNumber := new(big.Int).Add(w.chain.CurrentBlock().Number, common.Big1)
+	require.NoError(t, b.txPool.AddMevBundle(types.Transactions{bundleTx}, blockNumber, types.EmptyUUID, common.Address{}, 0, 0, nil, false))
+
+	r := w.getSealingBlock(&generateParams{
+		parentHash: w.chain.CurrentBlock().Hash(),
+		timestamp:  w.chain.CurrentHeader().Time + 12,
+		coinbase:   testAddress1,
+	})
+	require.NoError(t, r.err)
+	require.Len(t, r.block.Transactions(), 3)
+
+	var paths []string
+	require.Eventually(t, func() bool {
+		paths, err = ListSlotRecords(config.RecordDir)
+		return err == nil && len(paths) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	record, err := ReadSlotRecord(paths[0])
+	require.NoError(t, err)
+	require.Equal(t, w.chain.CurrentBlock().Hash(), record.Parent.Hash())
+	require.Equal(t, testAddress1, record.Attributes.FeeRecipient)
+	require.Len(t, record.PendingTxs, 1)
+	require.Equal(t, pendingTx.Hash(), record.PendingTxs[0].Hash())
+	require.Len(t, record.MevBundles, 1)
+	require.Equal(t, bundleTx.Hash(), record.MevBundles[0].Txs[0].Hash())
+
+	for _, algo := range []AlgoType{ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP} {
+		result, err := Backtest(b.chain, &config, record, algo)
+		require.NoError(t, err, algo.String())
+		require.Equal(t, algo, result.Algo)
+		require.Equal(t, 1, result.Bundles, algo.String())
+		require.Equal(t, r.fees, result.Value, algo.String())
+		require.Equal(t, r.block.GasUsed(), result.GasUsed, algo.String())
+	}
+
+	_, err = Backtest(b.chain, &config, record, ALGO_MEV_GETH)
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FallbackMode is the kind of block built when the builder cannot build regular blocks for
+// a slot, so that the proposer still receives a valid block.
+type FallbackMode string
+
+const (
+	// FallbackNone disables fallback blocks.
+	FallbackNone FallbackMode = ""
+	// FallbackEmpty builds blocks without transactions.
+	FallbackEmpty FallbackMode = "empty"
+	// FallbackPayout builds blocks holding only a proposer payment, paid for by the builder
+	// if there are no fees to pay out.
+	FallbackPayout FallbackMode = "payout"
+	// FallbackMempool builds blocks of mempool transactions by price and nonce, without
+	// bundles, followed by the proposer payment.
+	FallbackMempool FallbackMode = "mempool"
+)
+
+// ParseFallbackMode parses the name of a fallback mode, "none" or empty disabling fallback blocks.
+func ParseFallbackMode(s string) (FallbackMode, error) {
+	switch mode := FallbackMode(s); mode {
+	case FallbackNone, "none":
+		return FallbackNone, nil
+	case FallbackEmpty, FallbackPayout, FallbackMempool:
+		return mode, nil
+	default:
+		return FallbackNone, fmt.Errorf("unknown fallback block mode %q, expected none, empty, payout or mempool", s)
+	}
+}
+
+// BuildFallbackBlock builds a single fallback block of the given mode and passes it to the
+// block hook of the arguments.
+func (miner *Miner) BuildFallbackBlock(args *BuildPayloadArgs, mode FallbackMode) error {
+	if mode == FallbackNone {
+		return fmt.Errorf("fallback blocks are disabled")
+	}
+	result := miner.worker.regularWorker.getSealingBlock(&generateParams{
+		timestamp:   args.Timestamp,
+		forceTime:   true,
+		parentHash:  args.Parent,
+		coinbase:    args.FeeRecipient,
+		gasLimit:    args.GasLimit,
+		random:      args.Random,
+		withdrawals: args.Withdrawals,
+		beaconRoot:  args.BeaconRoot,
+		noTxs:       mode == FallbackEmpty,
+		fallback:    mode,
+		onBlock:     args.BlockHook,
+	})
+	return result.err
+}
+
+// fillFallbackTransactions fills the given sealing block for a payout or mempool fallback
+// block, ending it with the proposer payment if the builder pays proposers.
+func (w *worker) fillFallbackTransactions(env *environment, mode FallbackMode, validatorCoinbase *common.Address) error {
+	reserve, err := w.proposerTxPrepare(env, validatorCoinbase)
+	if err != nil {
+		return err
+	}
+	if mode == FallbackMempool {
+		w.mu.RLock()
+		tip := w.tip
+		w.mu.RUnlock()
+		if err := w.commitMempoolTransactions(env, tip, nil); err != nil {
+			return err
+		}
+	}
+	if reserve == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	sender := w.coinbase
+	w.mu.Unlock()
+	if env.state.GetBalance(sender).ToBig().Cmp(reserve.builderBalance) > 0 {
+		return w.proposerTxCommit(env, validatorCoinbase, reserve)
+	}
+
+	// Without fees to pay out, the builder pays for the gas of a zero value payment so
+	// that the block carries the proposer payment relays expect.
+	env.gasPool.AddGas(reserve.reservedGas)
+	gasFees := new(big.Int).Mul(env.header.BaseFee, new(big.Int).SetUint64(reserve.reservedGas))
+	chainData := chainData{w.chainConfig, w.chain, w.blockList}
+	_, err = insertPayoutTx(env, sender, *validatorCoinbase, reserve.reservedGas, reserve.isEOA, gasFees, w.config.BuilderTxSigningKey, chainData)
+	return err
+}
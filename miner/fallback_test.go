@@ -0,0 +1,60 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFallbackMode(t *testing.T) {
+	for s, mode := range map[string]FallbackMode{
+		"":        FallbackNone,
+		"none":    FallbackNone,
+		"empty":   FallbackEmpty,
+		"payout":  FallbackPayout,
+		"mempool": FallbackMempool,
+	} {
+		parsed, err := ParseFallbackMode(s)
+		require.NoError(t, err, s)
+		require.Equal(t, mode, parsed, s)
+	}
+	_, err := ParseFallbackMode("bundles")
+	require.Error(t, err)
+}
+
+func TestFallbackBlock(t *testing.T) {
+	chainConfig := new(params.ChainConfig)
+	*chainConfig = *ethashChainConfig
+	chainConfig.TerminalTotalDifficulty = big.NewInt(0)
+	engine := ethash.NewFaker()
+	defer engine.Close()
+	w, _ := newTestWorker(t, chainConfig, engine, rawdb.NewMemoryDatabase(), nil, 0)
+	defer w.close()
+
+	build := func(mode FallbackMode) *newPayloadResult {
+		r := w.getSealingBlock(&generateParams{
+			parentHash: w.chain.CurrentBlock().Hash(),
+			timestamp:  uint64(time.Now().Unix()),
+			coinbase:   testUserAddress,
+			forceTime:  true,
+			noTxs:      mode == FallbackEmpty,
+			fallback:   mode,
+		})
+		require.NoError(t, r.err, mode)
+		return r
+	}
+
+	// Empty and payout fallback blocks leave the pending transactions out.
+	require.Empty(t, build(FallbackEmpty).block.Transactions())
+	require.Empty(t, build(FallbackPayout).block.Transactions())
+
+	// Mempool fallback blocks include them.
+	block := build(FallbackMempool).block
+	require.Len(t, block.Transactions(), len(pendingTxs))
+	require.Equal(t, pendingTxs[0].Hash(), block.Transactions()[0].Hash())
+}
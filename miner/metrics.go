@@ -19,6 +19,8 @@ var (
 	simulationMeter          = metrics.NewRegisteredMeter("miner/block/simulation", nil)
 	simulationCommittedMeter = metrics.NewRegisteredMeter("miner/block/simulation/committed", nil)
 	simulationRevertedMeter  = metrics.NewRegisteredMeter("miner/block/simulation/reverted", nil)
+	simulationSkippedMeter   = metrics.NewRegisteredMeter("miner/block/simulation/skipped", nil)
+	bundleRateLimitedMeter   = metrics.NewRegisteredMeter("miner/bundle/ratelimited", nil)
 
 	gasUsedGauge        = metrics.NewRegisteredGauge("miner/block/gasused", nil)
 	transactionNumGauge = metrics.NewRegisteredGauge("miner/block/txnum", nil)
@@ -108,6 +108,13 @@ type Config struct {
 	NewPayloadTimeout        time.Duration    // The maximum time allowance for creating a new payload
 	PriceCutoffPercent       int              // Effective gas price cutoff % used for bucketing transactions by price (only useful in greedy-buckets AlgoType)
 	DiscardRevertibleTxOnErr bool             // When enabled, if bundle revertible transaction has error on commit, builder will discard the transaction
+	RecordDir                string           `toml:",omitempty"` // Directory to record the inputs of every block building job to, for backtesting
+	SearcherReputation       bool             `toml:",omitempty"` // Prioritise bundle simulation by the reputation of the searcher signing the bundle
+	SearcherRateLimit        float64          `toml:",omitempty"` // Bundle simulations per second allowed to a reliable searcher, 0 for no limit (only used with SearcherReputation)
+	BundleSimulationTimeout  time.Duration    `toml:",omitempty"` // Time budget of bundle simulation per block, 0 for no limit (only used with SearcherReputation)
+	BlobAwarePacking         bool             `toml:",omitempty"` // Choose the blob transactions and bundles that maximise block value within the blob limit before building
+	TopOfBlockSlots          int              // Number of bundles flagged for the top of the block placed first by an auction on their coinbase payments
+	TopOfBlockMinBid         *big.Int         `toml:",omitempty"` // Minimum coinbase payment of the winning bids of the top of block auction
 }
 
 // DefaultConfig contains default settings for miner.
@@ -122,6 +129,7 @@ var DefaultConfig = Config{
 	Recommit:           2 * time.Second,
 	NewPayloadTimeout:  2 * time.Second,
 	PriceCutoffPercent: defaultPriceCutoffPercent,
+	TopOfBlockSlots:    defaultTopOfBlockSlots,
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -294,6 +302,13 @@ func (miner *Miner) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return miner.worker.pendingBlockAndReceipts()
 }
 
+// BestPendingBlock returns the most profitable block built on top of the current head
+// for the next slot. The returned block is nil if no block was built on the head yet.
+func (miner *Miner) BestPendingBlock() *types.Block {
+	head := miner.eth.BlockChain().CurrentBlock()
+	return miner.worker.bestPendingBlock(head.Hash())
+}
+
 func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
@@ -310,10 +325,35 @@ func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscript
 	return miner.worker.regularWorker.pendingLogsFeed.Subscribe(ch)
 }
 
+// SubscribePendingBlocks starts delivering the candidate blocks sealed by the workers,
+// with their value, to the given channel.
+func (miner *Miner) SubscribePendingBlocks(ch chan<- core.NewPendingBlockEvent) event.Subscription {
+	return miner.worker.subscribePendingBlocks(ch)
+}
+
 // Accepts the block, time at which orders were taken, bundles which were used to build the block and all bundles that were considered for the block
 // TODO (deneb): refactor into block hook args
 type BlockHookFn = func(*types.Block, *big.Int, []*types.BlobTxSidecar, time.Time, []types.SimulatedBundle, []types.SimulatedBundle, []types.UsedSBundle)
 
+// SearcherScore returns the reputation of the searcher signing bundles with the given address.
+func (miner *Miner) SearcherScore(signer common.Address) (SearcherScore, bool, error) {
+	reputation := miner.worker.searcherReputation()
+	if reputation == nil {
+		return SearcherScore{}, false, ErrSearcherReputationDisabled
+	}
+	score, ok := reputation.score(signer)
+	return score, ok, nil
+}
+
+// SearcherScores returns the reputation of all tracked searchers, best first.
+func (miner *Miner) SearcherScores() ([]SearcherScore, error) {
+	reputation := miner.worker.searcherReputation()
+	if reputation == nil {
+		return nil, ErrSearcherReputationDisabled
+	}
+	return reputation.scores(), nil
+}
+
 // BuildPayload builds the payload according to the provided parameters.
 func (miner *Miner) BuildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	return miner.worker.buildPayload(args)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -57,6 +58,31 @@ func (w *multiWorker) pendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return w.regularWorker.pendingBlockAndReceipts()
 }
 
+// bestPendingBlock returns the most profitable block of the workers built on the given
+// parent, nil if there is none.
+func (w *multiWorker) bestPendingBlock(parent common.Hash) *types.Block {
+	var (
+		best       *types.Block
+		bestProfit *big.Int
+	)
+	for _, worker := range w.workers {
+		block, profit := worker.bestPendingBlock(parent)
+		if block != nil && (best == nil || profit.Cmp(bestProfit) > 0) {
+			best, bestProfit = block, profit
+		}
+	}
+	return best
+}
+
+// subscribePendingBlocks subscribes to the candidate blocks sealed by the workers.
+func (w *multiWorker) subscribePendingBlocks(ch chan<- core.NewPendingBlockEvent) event.Subscription {
+	subs := make([]event.Subscription, len(w.workers))
+	for i, worker := range w.workers {
+		subs[i] = worker.pendingBlockFeed.Subscribe(ch)
+	}
+	return event.JoinSubscriptions(subs...)
+}
+
 func (w *multiWorker) setGasCeil(ceil uint64) {
 	for _, worker := range w.workers {
 		worker.setGasCeil(ceil)
@@ -87,6 +113,11 @@ func (w *multiWorker) setGasTip(tip *big.Int) {
 	}
 }
 
+// searcherReputation returns the searcher reputation shared by the workers, nil if disabled.
+func (w *multiWorker) searcherReputation() *searcherReputation {
+	return w.regularWorker.flashbots.reputation
+}
+
 func (w *multiWorker) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	// Build the initial version with no transaction included. It should be fast
 	// enough to run. The empty payload can at least make sure there is something
@@ -180,6 +211,7 @@ func newMultiWorkerGreedy(config *Config, chainConfig *params.ChainConfig, engin
 		algoType:         config.AlgoType,
 		maxMergedBundles: config.MaxMergedBundles,
 		bundleCache:      NewBundleCache(),
+		reputation:       newSearcherReputationFromConfig(config),
 	})
 
 	log.Info("creating new greedy worker")
@@ -194,6 +226,7 @@ func newMultiWorkerMevGeth(config *Config, chainConfig *params.ChainConfig, engi
 	queue := make(chan *task)
 
 	bundleCache := NewBundleCache()
+	reputation := newSearcherReputationFromConfig(config)
 
 	regularWorker := newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, init, &flashbotsData{
 		isFlashbots:      false,
@@ -201,6 +234,7 @@ func newMultiWorkerMevGeth(config *Config, chainConfig *params.ChainConfig, engi
 		algoType:         ALGO_MEV_GETH,
 		maxMergedBundles: config.MaxMergedBundles,
 		bundleCache:      bundleCache,
+		reputation:       reputation,
 	})
 
 	workers := []*worker{regularWorker}
@@ -213,6 +247,7 @@ func newMultiWorkerMevGeth(config *Config, chainConfig *params.ChainConfig, engi
 					algoType:         ALGO_MEV_GETH,
 					maxMergedBundles: i,
 					bundleCache:      bundleCache,
+					reputation:       reputation,
 				}))
 		}
 	}
@@ -230,4 +265,5 @@ type flashbotsData struct {
 	maxMergedBundles int
 	algoType         AlgoType
 	bundleCache      *BundleCache
+	reputation       *searcherReputation // nil if bundle simulation is not prioritised
 }
@@ -0,0 +1,255 @@
+package miner
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxTrackedSearchers bounds the memory used by the reputation of one-off signers.
+	maxTrackedSearchers = 16384
+	// maxCreditedBundles is the number of included bundle hashes remembered, so that a
+	// bundle included in every rebuild of a block is only credited once.
+	maxCreditedBundles = 8192
+
+	// successRateAlpha is the weight of the latest simulation in the success rate.
+	successRateAlpha = 0.05
+	// initialSuccessRate is the success rate of unknown searchers.
+	initialSuccessRate = 0.5
+	// minRateLimitShare is the share of the rate limit left to searchers whose bundles
+	// always fail, so that they can recover their reputation.
+	minRateLimitShare = 0.1
+)
+
+// ErrSearcherReputationDisabled is returned when querying the reputation of searchers
+// while bundle simulation is not prioritised.
+var ErrSearcherReputationDisabled = errors.New("searcher reputation is disabled")
+
+// SearcherScore is the reputation of a searcher.
+type SearcherScore struct {
+	Address       common.Address `json:"address"`
+	Score         float64        `json:"score"`
+	SuccessRate   float64        `json:"successRate"`
+	Simulations   uint64         `json:"simulations"`
+	Failures      uint64         `json:"failures"`
+	RateLimited   uint64         `json:"rateLimited"`
+	Inclusions    uint64         `json:"inclusions"`
+	IncludedValue *hexutil.Big   `json:"includedValue"`
+	LastSeen      time.Time      `json:"lastSeen"`
+}
+
+type searcherStats struct {
+	simulations   uint64
+	failures      uint64
+	rateLimited   uint64
+	inclusions    uint64
+	includedValue *big.Int
+	successRate   float64 // exponentially weighted success rate of the simulations
+	lastSeen      time.Time
+	limiter       *rate.Limiter // nil if simulations are not rate limited
+}
+
+// score ranks searchers by the success rate of their bundles, boosted by the value
+// their bundles contributed to built blocks.
+func (s *searcherStats) score() float64 {
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(s.includedValue), big.NewFloat(params.GWei)).Float64()
+	return s.successRate * (1 + math.Log10(1+gwei))
+}
+
+// searcherReputation tracks the reputation of searchers, keyed by the authenticated signing
+// address of their bundles. It orders bundle simulation by reputation and rate limits the
+// simulation of bundles from unreliable searchers. Bundles without an authenticated signer
+// cannot be attributed: they share a single rate limited bucket, simulated after the
+// bundles of all the known searchers.
+type searcherReputation struct {
+	mu        sync.Mutex
+	searchers lru.BasicLRU[common.Address, *searcherStats]
+	unsigned  *searcherStats // shared by the bundles without an authenticated signer
+	credited  lru.BasicLRU[common.Hash, struct{}]
+	rateLimit float64 // simulations per second of a reliable searcher, 0 if unlimited
+}
+
+func newSearcherReputation(rateLimit float64) *searcherReputation {
+	r := &searcherReputation{
+		searchers: lru.NewBasicLRU[common.Address, *searcherStats](maxTrackedSearchers),
+		credited:  lru.NewBasicLRU[common.Hash, struct{}](maxCreditedBundles),
+		rateLimit: rateLimit,
+	}
+	r.unsigned = r.newStats()
+	return r
+}
+
+// searcherOf returns the authenticated signer of the bundle, the zero address if the bundle
+// has none. The signing address chosen by the sender of a bundle is not trusted, as anyone
+// could claim the reputation of another searcher.
+func searcherOf(bundle *types.MevBundle) common.Address {
+	if !bundle.SignerAuthenticated {
+		return common.Address{}
+	}
+	return bundle.SigningAddress
+}
+
+// newSearcherReputationFromConfig returns the reputation tracker shared by the workers, nil
+// if bundle simulation is not prioritised.
+func newSearcherReputationFromConfig(config *Config) *searcherReputation {
+	if !config.SearcherReputation {
+		return nil
+	}
+	return newSearcherReputation(config.SearcherRateLimit)
+}
+
+// stats returns the stats of the searcher, creating them if unknown. The zero address
+// denotes the bucket of the unsigned bundles. The lock must be held.
+func (r *searcherReputation) stats(signer common.Address) *searcherStats {
+	if signer == (common.Address{}) {
+		return r.unsigned
+	}
+	if s, ok := r.searchers.Get(signer); ok {
+		return s
+	}
+	s := r.newStats()
+	r.searchers.Add(signer, s)
+	return s
+}
+
+// newStats returns the stats of an unknown searcher.
+func (r *searcherReputation) newStats() *searcherStats {
+	s := &searcherStats{
+		includedValue: new(big.Int),
+		successRate:   initialSuccessRate,
+	}
+	if r.rateLimit > 0 {
+		s.limiter = rate.NewLimiter(r.limit(s.successRate), int(math.Ceil(r.rateLimit)))
+	}
+	return s
+}
+
+// limit scales the rate limit with the success rate of the searcher.
+func (r *searcherReputation) limit(successRate float64) rate.Limit {
+	return rate.Limit(r.rateLimit * math.Max(successRate, minRateLimitShare))
+}
+
+// schedule drops the bundles of rate limited searchers from the given bundle indexes and
+// orders the remaining ones by the reputation of their searchers, best first, the unsigned
+// bundles last.
+func (r *searcherReputation) schedule(bundles []types.MevBundle, indexes []int) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	scores := make(map[common.Address]float64)
+	scheduled := indexes[:0:0]
+	for _, idx := range indexes {
+		signer := searcherOf(&bundles[idx])
+		s := r.stats(signer)
+		s.lastSeen = now
+		if s.limiter != nil && !s.limiter.AllowN(now, 1) {
+			s.rateLimited++
+			if metrics.EnabledBuilder {
+				bundleRateLimitedMeter.Mark(1)
+			}
+			continue
+		}
+		if signer == (common.Address{}) {
+			scores[signer] = -1 // scores of searchers are never negative
+		} else {
+			scores[signer] = s.score()
+		}
+		scheduled = append(scheduled, idx)
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scores[searcherOf(&bundles[scheduled[i]])] > scores[searcherOf(&bundles[scheduled[j]])]
+	})
+	return scheduled
+}
+
+// recordSimulation updates the success rate of the searcher of the bundle with a simulation
+// outcome. The outcomes of the unsigned bundles scale the rate limit of their bucket.
+func (r *searcherReputation) recordSimulation(bundle *types.MevBundle, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stats(searcherOf(bundle))
+	s.simulations++
+	outcome := 1.0
+	if !success {
+		s.failures++
+		outcome = 0
+	}
+	s.successRate += successRateAlpha * (outcome - s.successRate)
+	if s.limiter != nil {
+		s.limiter.SetLimit(r.limit(s.successRate))
+	}
+}
+
+// recordInclusions credits the searchers of the bundles included in a built block.
+func (r *searcherReputation) recordInclusions(bundles []types.SimulatedBundle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, bundle := range bundles {
+		signer := searcherOf(&bundle.OriginalBundle)
+		if signer == (common.Address{}) || r.credited.Contains(bundle.OriginalBundle.Hash) {
+			continue
+		}
+		r.credited.Add(bundle.OriginalBundle.Hash, struct{}{})
+
+		s := r.stats(signer)
+		s.inclusions++
+		if bundle.TotalEth != nil {
+			s.includedValue.Add(s.includedValue, bundle.TotalEth.ToBig())
+		}
+	}
+}
+
+func (s *searcherStats) export(signer common.Address) SearcherScore {
+	return SearcherScore{
+		Address:       signer,
+		Score:         s.score(),
+		SuccessRate:   s.successRate,
+		Simulations:   s.simulations,
+		Failures:      s.failures,
+		RateLimited:   s.rateLimited,
+		Inclusions:    s.inclusions,
+		IncludedValue: (*hexutil.Big)(new(big.Int).Set(s.includedValue)),
+		LastSeen:      s.lastSeen,
+	}
+}
+
+// score returns the reputation of the given searcher.
+func (r *searcherReputation) score(signer common.Address) (SearcherScore, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.searchers.Peek(signer)
+	if !ok {
+		return SearcherScore{}, false
+	}
+	return s.export(signer), true
+}
+
+// scores returns the reputation of all tracked searchers, best first.
+func (r *searcherReputation) scores() []SearcherScore {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scores := make([]SearcherScore, 0, r.searchers.Len())
+	for _, signer := range r.searchers.Keys() {
+		s, _ := r.searchers.Peek(signer)
+		scores = append(scores, s.export(signer))
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores
+}
@@ -0,0 +1,126 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	reliableSearcher   = common.HexToAddress("0x01")
+	unreliableSearcher = common.HexToAddress("0x02")
+	unknownSearcher    = common.HexToAddress("0x03")
+)
+
+// signedBundle returns a bundle authenticated as signed by the given searcher.
+func signedBundle(signer common.Address) types.MevBundle {
+	return types.MevBundle{SigningAddress: signer, SignerAuthenticated: true}
+}
+
+func recordSimulations(r *searcherReputation, signer common.Address, n int, success bool) {
+	bundle := signedBundle(signer)
+	for i := 0; i < n; i++ {
+		r.recordSimulation(&bundle, success)
+	}
+}
+
+func TestSearcherReputationSchedule(t *testing.T) {
+	r := newSearcherReputation(0)
+	recordSimulations(r, reliableSearcher, 20, true)
+	recordSimulations(r, unreliableSearcher, 20, false)
+
+	bundles := []types.MevBundle{
+		signedBundle(unreliableSearcher),
+		{},
+		signedBundle(reliableSearcher),
+		signedBundle(unknownSearcher),
+		{SigningAddress: reliableSearcher}, // claimed rather than authenticated
+	}
+	scheduled := r.schedule(bundles, []int{0, 1, 2, 3, 4})
+	// Unsigned bundles rank after all the searchers, in submission order.
+	require.Equal(t, []int{2, 3, 0, 1, 4}, scheduled)
+
+	score, ok := r.score(reliableSearcher)
+	require.True(t, ok)
+	require.EqualValues(t, 20, score.Simulations)
+	require.Zero(t, score.Failures)
+	require.Greater(t, score.SuccessRate, initialSuccessRate)
+
+	score, ok = r.score(unreliableSearcher)
+	require.True(t, ok)
+	require.EqualValues(t, 20, score.Failures)
+	require.Less(t, score.SuccessRate, initialSuccessRate)
+
+	_, ok = r.score(common.HexToAddress("0x04"))
+	require.False(t, ok)
+
+	// Unsigned bundles are not tracked as searchers.
+	require.Len(t, r.scores(), 3)
+	require.Equal(t, reliableSearcher, r.scores()[0].Address)
+}
+
+func TestSearcherReputationRateLimit(t *testing.T) {
+	r := newSearcherReputation(2)
+	recordSimulations(r, unreliableSearcher, 50, false)
+
+	bundles := make([]types.MevBundle, 10)
+	indexes := make([]int, len(bundles))
+	for i := range bundles {
+		bundles[i] = signedBundle(unreliableSearcher)
+		indexes[i] = i
+	}
+	// The burst allows as many simulations as the unscaled rate limit.
+	require.Len(t, r.schedule(bundles, indexes), 2)
+	require.Empty(t, r.schedule(bundles, indexes))
+
+	score, ok := r.score(unreliableSearcher)
+	require.True(t, ok)
+	require.EqualValues(t, 18, score.RateLimited)
+
+	// Unsigned bundles share a single rate limit, whatever signer they claim.
+	for i := range bundles {
+		bundles[i] = types.MevBundle{}
+	}
+	require.Len(t, r.schedule(bundles, indexes), 2)
+	for i := range bundles {
+		bundles[i] = types.MevBundle{SigningAddress: common.BigToAddress(big.NewInt(int64(i + 1)))}
+	}
+	require.Empty(t, r.schedule(bundles, indexes))
+}
+
+func TestSearcherReputationInclusions(t *testing.T) {
+	r := newSearcherReputation(0)
+	included := []types.SimulatedBundle{
+		{
+			TotalEth:       uint256.NewInt(1e18),
+			OriginalBundle: types.MevBundle{SigningAddress: reliableSearcher, SignerAuthenticated: true, Hash: common.HexToHash("0xaa")},
+		},
+		{
+			TotalEth:       uint256.NewInt(1e9),
+			OriginalBundle: types.MevBundle{SigningAddress: unknownSearcher, SignerAuthenticated: true, Hash: common.HexToHash("0xbb")},
+		},
+		{
+			// Claiming the address of a searcher doesn't credit them.
+			TotalEth:       uint256.NewInt(1e18),
+			OriginalBundle: types.MevBundle{SigningAddress: reliableSearcher, Hash: common.HexToHash("0xcc")},
+		},
+	}
+	// Bundles included in successive rebuilds of a block are credited once.
+	r.recordInclusions(included)
+	r.recordInclusions(included)
+
+	score, ok := r.score(reliableSearcher)
+	require.True(t, ok)
+	require.EqualValues(t, 1, score.Inclusions)
+	require.Equal(t, uint256.NewInt(1e18).ToBig(), score.IncludedValue.ToInt())
+
+	// With equal success rates, the searcher that contributed more value ranks first.
+	scores := r.scores()
+	require.Len(t, scores, 2)
+	require.Equal(t, reliableSearcher, scores[0].Address)
+	require.Greater(t, scores[0].Score, scores[1].Score)
+}
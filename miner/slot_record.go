@@ -0,0 +1,218 @@
+package miner
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/holiman/uint256"
+)
+
+const slotRecordSuffix = ".json.gz"
+
+// SlotAttributes are the payload attributes a block was built for.
+type SlotAttributes struct {
+	Timestamp    uint64            `json:"timestamp"`
+	FeeRecipient common.Address    `json:"feeRecipient"`
+	GasLimit     uint64            `json:"gasLimit"`
+	Random       common.Hash       `json:"prevRandao"`
+	Withdrawals  types.Withdrawals `json:"withdrawals"`
+	BeaconRoot   *common.Hash      `json:"parentBeaconBlockRoot,omitempty"`
+}
+
+// SlotRecord holds the inputs of a block building job: the parent, the payload attributes
+// and the order flow the algorithm saw. It is enough to replay the job with any algorithm.
+type SlotRecord struct {
+	Parent     *types.Header        `json:"parent"`
+	Attributes SlotAttributes       `json:"attributes"`
+	PendingTxs []*types.Transaction `json:"pendingTxs"`
+	MevBundles []types.MevBundle    `json:"mevBundles"`
+	SBundles   []*types.SBundle     `json:"sbundles"`
+	RecordedAt time.Time            `json:"recordedAt"`
+}
+
+func newSlotRecord(parent *types.Header, params *generateParams, validatorCoinbase common.Address) *SlotRecord {
+	return &SlotRecord{
+		Parent: types.CopyHeader(parent),
+		Attributes: SlotAttributes{
+			Timestamp:    params.timestamp,
+			FeeRecipient: validatorCoinbase,
+			GasLimit:     params.gasLimit,
+			Random:       params.random,
+			Withdrawals:  params.withdrawals,
+			BeaconRoot:   params.beaconRoot,
+		},
+	}
+}
+
+// addPending records the resolved pending transactions of the txpool.
+func (r *SlotRecord) addPending(pending map[common.Address][]*txpool.LazyTransaction) {
+	for _, txs := range pending {
+		for _, lazyTx := range txs {
+			if tx := lazyTx.Resolve(); tx != nil {
+				r.PendingTxs = append(r.PendingTxs, tx)
+			}
+		}
+	}
+}
+
+// generateParams returns the parameters to build a block for the recorded slot.
+func (r *SlotRecord) generateParams(coinbase common.Address) *generateParams {
+	return &generateParams{
+		timestamp:   r.Attributes.Timestamp,
+		forceTime:   true,
+		parentHash:  r.Parent.Hash(),
+		coinbase:    coinbase,
+		gasLimit:    r.Attributes.GasLimit,
+		random:      r.Attributes.Random,
+		withdrawals: r.Attributes.Withdrawals,
+		beaconRoot:  r.Attributes.BeaconRoot,
+	}
+}
+
+// pending groups the recorded transactions by sender and nonce, the way the txpool returns them.
+func (r *SlotRecord) pending(signer types.Signer) map[common.Address][]*txpool.LazyTransaction {
+	pending := make(map[common.Address][]*txpool.LazyTransaction)
+	for _, tx := range r.PendingTxs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			log.Debug("Skipping recorded tx with invalid signature", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		pending[from] = append(pending[from], &txpool.LazyTransaction{
+			Hash:      tx.Hash(),
+			Tx:        tx,
+			Time:      tx.Time(),
+			GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+			GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+			Gas:       tx.Gas(),
+			BlobGas:   tx.BlobGas(),
+			GasPrice:  uint256.MustFromBig(tx.GasPrice()),
+		})
+	}
+	for _, txs := range pending {
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Tx.Nonce() < txs[j].Tx.Nonce() })
+	}
+	return pending
+}
+
+// slotRecorder writes the inputs of every block building job to a directory. The inputs
+// of the latest job of a slot overwrite the earlier ones.
+type slotRecorder struct {
+	dir string
+}
+
+func newSlotRecorder(dir string) (*slotRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &slotRecorder{dir: dir}, nil
+}
+
+// save writes the record in the background.
+func (r *slotRecorder) save(record *SlotRecord) {
+	record.RecordedAt = time.Now()
+	go func() {
+		if err := WriteSlotRecord(r.dir, record); err != nil {
+			log.Error("Failed to write slot record", "parent", record.Parent.Hash(), "err", err)
+		}
+	}()
+}
+
+// slotRecordName returns the file name of the record, unique per parent and slot.
+func slotRecordName(record *SlotRecord) string {
+	return fmt.Sprintf("%d-%d-%s%s", record.Parent.Number.Uint64()+1, record.Attributes.Timestamp, record.Parent.Hash().Hex(), slotRecordSuffix)
+}
+
+// WriteSlotRecord writes the gzipped json encoded record to the given directory.
+func WriteSlotRecord(dir string, record *SlotRecord) error {
+	path := filepath.Join(dir, slotRecordName(record))
+	tmp, err := os.CreateTemp(dir, ".slot-record-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(record); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadSlotRecord reads a record written by WriteSlotRecord.
+func ReadSlotRecord(path string) (*SlotRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var record SlotRecord
+	if err := json.NewDecoder(zr).Decode(&record); err != nil {
+		return nil, fmt.Errorf("invalid slot record %s: %w", path, err)
+	}
+	if record.Parent == nil {
+		return nil, fmt.Errorf("invalid slot record %s: missing parent", path)
+	}
+	return &record, nil
+}
+
+// ListSlotRecords returns the paths of all records in the given directory, ordered by slot.
+func ListSlotRecords(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type named struct {
+		path   string
+		number uint64
+		time   uint64
+	}
+	var records []named
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), slotRecordSuffix) {
+			continue
+		}
+		var n named
+		if _, err := fmt.Sscanf(entry.Name(), "%d-%d-", &n.number, &n.time); err != nil {
+			continue
+		}
+		n.path = filepath.Join(dir, entry.Name())
+		records = append(records, n)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].number != records[j].number {
+			return records[i].number < records[j].number
+		}
+		return records[i].time < records[j].time
+	})
+	paths := make([]string, len(records))
+	for i, r := range records {
+		paths[i] = r.path
+	}
+	return paths, nil
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -58,6 +59,10 @@ const (
 	// resubmitAdjustChanSize is the size of resubmitting interval adjustment channel.
 	resubmitAdjustChanSize = 10
 
+	// pendingBlockChanSize is the number of sealed blocks queued for the pending block
+	// subscribers, newer blocks are dropped while the queue is full.
+	pendingBlockChanSize = 10
+
 	// minRecommitInterval is the minimal time interval to recreate the sealing block with
 	// any newly arrived transactions.
 	minRecommitInterval = 1 * time.Second
@@ -102,6 +107,8 @@ type environment struct {
 	receipts []*types.Receipt
 	sidecars []*types.BlobTxSidecar
 	blobs    int
+
+	slotRecord *SlotRecord // inputs of the building job, recorded if not nil
 }
 
 // copy creates a deep copy of environment.
@@ -194,7 +201,8 @@ type worker struct {
 	blockList   map[common.Address]struct{}
 
 	// Feeds
-	pendingLogsFeed event.Feed
+	pendingLogsFeed  event.Feed
+	pendingBlockFeed event.Feed
 
 	// Subscriptions
 	mux          *event.TypeMux
@@ -212,6 +220,7 @@ type worker struct {
 	exitCh             chan struct{}
 	resubmitIntervalCh chan time.Duration
 	resubmitAdjustCh   chan *intervalAdjust
+	pendingBlockCh     chan core.NewPendingBlockEvent
 
 	wg sync.WaitGroup
 
@@ -230,6 +239,9 @@ type worker struct {
 	snapshotReceipts types.Receipts
 	snapshotState    *state.StateDB
 
+	bestBlockMu sync.RWMutex // The lock used to protect the best block below
+	bestBlock   *types.Block // Most profitable block built on the latest parent
+	bestProfit  *big.Int
 	// atomic status counters
 	running atomic.Bool  // The indicator whether the consensus engine is running or not.
 	newTxs  atomic.Int32 // New arrival transaction count since last sealing work submitting.
@@ -250,6 +262,7 @@ type worker struct {
 	isLocalBlock func(header *types.Header) bool // Function used to determine whether the specified block is mined by local miner.
 
 	flashbots *flashbotsData
+	recorder  *slotRecorder // records the inputs of every building job if set
 
 	// Test hooks
 	newTaskHook  func(*task)                        // Method to call upon receiving a new sealing task.
@@ -321,9 +334,18 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		exitCh:             exitCh,
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
+		pendingBlockCh:     make(chan core.NewPendingBlockEvent, pendingBlockChanSize),
 		coinbase:           builderCoinbase,
 		flashbots:          flashbots,
 	}
+	if config.RecordDir != "" && flashbots.isFlashbots {
+		recorder, err := newSlotRecorder(config.RecordDir)
+		if err != nil {
+			log.Error("Could not create slot recorder", "dir", config.RecordDir, "err", err)
+		} else {
+			worker.recorder = recorder
+		}
+	}
 	// Subscribe for transaction insertion events (whether from network or resurrects)
 	worker.txsSub = eth.TxPool().SubscribeTransactions(worker.txsCh, true)
 	// Subscribe events for blockchain
@@ -348,9 +370,10 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 	}
 	worker.newpayloadTimeout = newpayloadTimeout
 
-	worker.wg.Add(2)
+	worker.wg.Add(3)
 	go worker.mainLoop()
 	go worker.newWorkLoop(recommit)
+	go worker.pendingBlockLoop()
 	if flashbots.algoType != ALGO_MEV_GETH || !flashbots.isFlashbots {
 		// only mine if not flashbots
 		worker.wg.Add(2)
@@ -426,6 +449,35 @@ func (w *worker) pendingBlock() *types.Block {
 	return w.snapshotBlock
 }
 
+// recordBestBlock keeps the block if it is the most profitable one built on its parent.
+// Blocks built on another parent replace the best block, unless they are older.
+func (w *worker) recordBestBlock(block *types.Block, profit *big.Int) {
+	w.bestBlockMu.Lock()
+	defer w.bestBlockMu.Unlock()
+
+	if best := w.bestBlock; best != nil {
+		if block.NumberU64() < best.NumberU64() {
+			return
+		}
+		if block.ParentHash() == best.ParentHash() && profit.Cmp(w.bestProfit) <= 0 {
+			return
+		}
+	}
+	w.bestBlock, w.bestProfit = block, profit
+}
+
+// bestPendingBlock returns the most profitable block built on the given parent, nil if
+// there is none.
+func (w *worker) bestPendingBlock(parent common.Hash) (*types.Block, *big.Int) {
+	w.bestBlockMu.RLock()
+	defer w.bestBlockMu.RUnlock()
+
+	if w.bestBlock == nil || w.bestBlock.ParentHash() != parent {
+		return nil, nil
+	}
+	return w.bestBlock, w.bestProfit
+}
+
 // pendingBlockAndReceipts returns pending block and corresponding receipts.
 // The returned values can be nil in case the pending block is not initialized.
 func (w *worker) pendingBlockAndReceipts() (*types.Block, types.Receipts) {
@@ -672,6 +724,20 @@ func (w *worker) mainLoop() {
 	}
 }
 
+// pendingBlockLoop is a standalone goroutine to deliver the sealed blocks to the pending
+// block subscribers, off the block building path.
+func (w *worker) pendingBlockLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case ev := <-w.pendingBlockCh:
+			w.pendingBlockFeed.Send(ev)
+		case <-w.exitCh:
+			return
+		}
+	}
+}
+
 // taskLoop is a standalone goroutine to fetch sealing task from the generator and
 // push them to consensus engine.
 func (w *worker) taskLoop() {
@@ -1163,6 +1229,7 @@ type generateParams struct {
 	withdrawals types.Withdrawals // List of withdrawals to include in block.
 	beaconRoot  *common.Hash      // The beacon root (cancun field).
 	noTxs       bool              // Flag whether an empty block without any transaction is expected
+	fallback    FallbackMode      // Kind of fallback block expected, none for regular blocks
 	onBlock     BlockHookFn       // Callback to call for each produced block
 }
 
@@ -1304,33 +1371,6 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) ([]
 		}
 	}
 
-	if env.header.BaseFee != nil {
-		filter.BaseFee = uint256.MustFromBig(env.header.BaseFee)
-	}
-	if env.header.ExcessBlobGas != nil {
-		filter.BlobFee = uint256.MustFromBig(eip4844.CalcBlobFee(*env.header.ExcessBlobGas))
-	}
-	filter.OnlyPlainTxs, filter.OnlyBlobTxs = true, false
-	pendingPlainTxs := w.eth.TxPool().Pending(filter)
-
-	filter.OnlyPlainTxs, filter.OnlyBlobTxs = false, true
-	pendingBlobTxs := w.eth.TxPool().Pending(filter)
-
-	// Split the pending transactions into locals and remotes.
-	localPlainTxs, remotePlainTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingPlainTxs
-	localBlobTxs, remoteBlobTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingBlobTxs
-
-	for _, account := range w.eth.TxPool().Locals() {
-		if txs := remotePlainTxs[account]; len(txs) > 0 {
-			delete(remotePlainTxs, account)
-			localPlainTxs[account] = txs
-		}
-		if txs := remoteBlobTxs[account]; len(txs) > 0 {
-			delete(remoteBlobTxs, account)
-			localBlobTxs[account] = txs
-		}
-	}
-
 	var blockBundles []types.SimulatedBundle
 	var allBundles []types.SimulatedBundle
 	if w.flashbots.isFlashbots {
@@ -1361,13 +1401,53 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) ([]
 		env.profit.Add(env.profit, resultingBundle.EthSentToCoinbase)
 	}
 
+	if err := w.commitMempoolTransactions(env, tip, interrupt); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return blockBundles, allBundles, mempoolTxHashes, nil
+}
+
+// commitMempoolTransactions fills the given sealing block with the pending transactions of
+// the txpool paying at least the given tip, local transactions first, by price and nonce.
+func (w *worker) commitMempoolTransactions(env *environment, tip *uint256.Int, interrupt *atomic.Int32) error {
+	filter := txpool.PendingFilter{
+		MinTip: tip,
+	}
+	if env.header.BaseFee != nil {
+		filter.BaseFee = uint256.MustFromBig(env.header.BaseFee)
+	}
+	if env.header.ExcessBlobGas != nil {
+		filter.BlobFee = uint256.MustFromBig(eip4844.CalcBlobFee(*env.header.ExcessBlobGas))
+	}
+	filter.OnlyPlainTxs, filter.OnlyBlobTxs = true, false
+	pendingPlainTxs := w.eth.TxPool().Pending(filter)
+
+	filter.OnlyPlainTxs, filter.OnlyBlobTxs = false, true
+	pendingBlobTxs := w.eth.TxPool().Pending(filter)
+
+	// Split the pending transactions into locals and remotes.
+	localPlainTxs, remotePlainTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingPlainTxs
+	localBlobTxs, remoteBlobTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingBlobTxs
+
+	for _, account := range w.eth.TxPool().Locals() {
+		if txs := remotePlainTxs[account]; len(txs) > 0 {
+			delete(remotePlainTxs, account)
+			localPlainTxs[account] = txs
+		}
+		if txs := remoteBlobTxs[account]; len(txs) > 0 {
+			delete(remoteBlobTxs, account)
+			localBlobTxs[account] = txs
+		}
+	}
+
 	// Fill the block with all available pending transactions.
 	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
 		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, nil, nil, env.header.BaseFee)
 		blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, nil, nil, env.header.BaseFee)
 
 		if err := w.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
-			return nil, nil, nil, err
+			return err
 		}
 	}
 	if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
@@ -1375,11 +1455,11 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) ([]
 		blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, nil, nil, env.header.BaseFee)
 
 		if err := w.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
-			return nil, nil, nil, err
+			return err
 		}
 	}
 
-	return blockBundles, allBundles, mempoolTxHashes, nil
+	return nil
 }
 
 // fillTransactionsAlgoWorker retrieves the pending transactions and bundles from the txpool and fills them
@@ -1406,23 +1486,44 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *atomic.Int32, env *enviro
 			mempoolTxHashes[tx.Hash] = struct{}{}
 		}
 	}
+	if env.slotRecord != nil {
+		env.slotRecord.addPending(pending)
+	}
 
 	bundlesToConsider, sbundlesToConsider, err := w.getSimulatedBundles(env)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
+	start := time.Now()
+	newEnv, blockBundles, usedSbundle, err := w.buildBlockWithAlgo(w.flashbots.algoType, interrupt, env, bundlesToConsider, sbundlesToConsider, pending)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if metrics.EnabledBuilder {
+		mergeAlgoTimer.Update(time.Since(start))
+	}
+	*env = *newEnv
+
+	return blockBundles, bundlesToConsider, usedSbundle, mempoolTxHashes, err
+}
+
+// buildBlockWithAlgo fills the given environment with the simulated bundles, sbundles and pending transactions
+// using the given algorithm. It returns the resulting environment, the bundles and sbundles that made it into the block.
+func (w *worker) buildBlockWithAlgo(algoType AlgoType, interrupt *atomic.Int32, env *environment, simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle, pending map[common.Address][]*txpool.LazyTransaction) (*environment, []types.SimulatedBundle, []types.UsedSBundle, error) {
 	var (
 		newEnv       *environment
 		blockBundles []types.SimulatedBundle
 		usedSbundle  []types.UsedSBundle
-		start        = time.Now()
 	)
-	switch w.flashbots.algoType {
+	if w.config.BlobAwarePacking {
+		simBundles, pending = selectBlobOrders(env, simBundles, pending)
+	}
+	switch algoType {
 	case ALGO_GREEDY_BUCKETS:
 		priceCutoffPercent := w.config.PriceCutoffPercent
 		if !(priceCutoffPercent >= 0 && priceCutoffPercent <= 100) {
-			return nil, nil, nil, nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
+			return nil, nil, nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
 		}
 
 		algoConf := &algorithmConfig{
@@ -1430,17 +1531,19 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *atomic.Int32, env *enviro
 			EnforceProfit:          true,
 			ProfitThresholdPercent: defaultProfitThresholdPercent,
 			PriceCutoffPercent:     priceCutoffPercent,
+			TopOfBlockSlots:        w.config.TopOfBlockSlots,
+			TopOfBlockMinBid:       w.config.TopOfBlockMinBid,
 		}
 		builder := newGreedyBucketsBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList, env,
 			w.config.BuilderTxSigningKey, interrupt,
 		)
 
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+		newEnv, blockBundles, usedSbundle = builder.buildBlock(simBundles, simSBundles, pending)
 	case ALGO_GREEDY_BUCKETS_MULTISNAP:
 		priceCutoffPercent := w.config.PriceCutoffPercent
 		if !(priceCutoffPercent >= 0 && priceCutoffPercent <= 100) {
-			return nil, nil, nil, nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
+			return nil, nil, nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
 		}
 
 		algoConf := &algorithmConfig{
@@ -1448,12 +1551,14 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *atomic.Int32, env *enviro
 			EnforceProfit:          true,
 			ProfitThresholdPercent: defaultProfitThresholdPercent,
 			PriceCutoffPercent:     priceCutoffPercent,
+			TopOfBlockSlots:        w.config.TopOfBlockSlots,
+			TopOfBlockMinBid:       w.config.TopOfBlockMinBid,
 		}
 		builder := newGreedyBucketsMultiSnapBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList, env,
 			w.config.BuilderTxSigningKey, interrupt,
 		)
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+		newEnv, blockBundles, usedSbundle = builder.buildBlock(simBundles, simSBundles, pending)
 	case ALGO_GREEDY_MULTISNAP:
 		// For greedy multi-snap builder, set algorithm configuration to default values,
 		// except DropRevertibleTxOnErr which is passed in from worker config
@@ -1461,13 +1566,15 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *atomic.Int32, env *enviro
 			DropRevertibleTxOnErr:  w.config.DiscardRevertibleTxOnErr,
 			EnforceProfit:          defaultAlgorithmConfig.EnforceProfit,
 			ProfitThresholdPercent: defaultAlgorithmConfig.ProfitThresholdPercent,
+			TopOfBlockSlots:        w.config.TopOfBlockSlots,
+			TopOfBlockMinBid:       w.config.TopOfBlockMinBid,
 		}
 
 		builder := newGreedyMultiSnapBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList, env,
 			w.config.BuilderTxSigningKey, interrupt,
 		)
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+		newEnv, blockBundles, usedSbundle = builder.buildBlock(simBundles, simSBundles, pending)
 	case ALGO_GREEDY:
 		fallthrough
 	default:
@@ -1477,21 +1584,18 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *atomic.Int32, env *enviro
 			DropRevertibleTxOnErr:  w.config.DiscardRevertibleTxOnErr,
 			EnforceProfit:          defaultAlgorithmConfig.EnforceProfit,
 			ProfitThresholdPercent: defaultAlgorithmConfig.ProfitThresholdPercent,
+			TopOfBlockSlots:        w.config.TopOfBlockSlots,
+			TopOfBlockMinBid:       w.config.TopOfBlockMinBid,
 		}
 
 		builder := newGreedyBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList,
 			env, w.config.BuilderTxSigningKey, interrupt,
 		)
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
-	}
-
-	if metrics.EnabledBuilder {
-		mergeAlgoTimer.Update(time.Since(start))
+		newEnv, blockBundles, usedSbundle = builder.buildBlock(simBundles, simSBundles, pending)
 	}
-	*env = *newEnv
 
-	return blockBundles, bundlesToConsider, usedSbundle, mempoolTxHashes, err
+	return newEnv, blockBundles, usedSbundle, nil
 }
 
 func (w *worker) getSimulatedBundles(env *environment) ([]types.SimulatedBundle, []*types.SimSBundle, error) {
@@ -1510,6 +1614,10 @@ func (w *worker) getSimulatedBundles(env *environment) ([]types.SimulatedBundle,
 	}
 
 	ccBundles := <-ccBundlesCh
+	if env.slotRecord != nil {
+		env.slotRecord.MevBundles = append(append(env.slotRecord.MevBundles, bundles...), ccBundles...)
+		env.slotRecord.SBundles = append(env.slotRecord.SBundles, sbundles...)
+	}
 	if ccBundles == nil {
 		return simBundles, simSBundles, nil
 	}
@@ -1557,6 +1665,9 @@ func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 			"height", block.Number().String(), "blockProfit", ethIntToFloat(uint256.MustFromBig(profit)),
 			"txs", len(env.txs), "bundles", len(blockBundles), "okSbundles", okSbundles, "totalSbundles", totalSbundles,
 			"gasUsed", block.GasUsed(), "time", time.Since(start))
+		if w.flashbots.reputation != nil {
+			w.flashbots.reputation.recordInclusions(blockBundles)
+		}
 		if metrics.EnabledBuilder {
 			buildBlockTimer.Update(time.Since(start))
 			blockProfitHistogram.Update(profit.Int64())
@@ -1565,6 +1676,12 @@ func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 			gasUsedGauge.Update(int64(block.GasUsed()))
 			transactionNumGauge.Update(int64(len(env.txs)))
 		}
+		w.recordBestBlock(block, profit)
+		select {
+		case w.pendingBlockCh <- core.NewPendingBlockEvent{Block: block, Profit: profit}:
+		default:
+			log.Debug("Dropped pending block event", "height", block.Number(), "hash", block.Hash())
+		}
 		if params.onBlock != nil {
 			go params.onBlock(block, profit, work.sidecars, orderCloseTime, blockBundles, allBundles, usedSbundles)
 		}
@@ -1580,6 +1697,13 @@ func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 		return finalizeFn(work, time.Now(), nil, nil, nil, true)
 	}
 
+	if params.fallback != FallbackNone {
+		if err := w.fillFallbackTransactions(work, params.fallback, &validatorCoinbase); err != nil {
+			return &newPayloadResult{err: err}
+		}
+		return finalizeFn(work, time.Now(), nil, nil, nil, len(work.txs) == 0)
+	}
+
 	paymentTxReserve, err := w.proposerTxPrepare(work, &validatorCoinbase)
 	if err != nil {
 		return &newPayloadResult{err: err}
@@ -1587,7 +1711,17 @@ func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 
 	orderCloseTime := time.Now()
 
+	var record *SlotRecord
+	if w.recorder != nil {
+		if parent := w.chain.GetHeaderByHash(work.header.ParentHash); parent != nil {
+			record = newSlotRecord(parent, params, validatorCoinbase)
+			work.slotRecord = record
+		}
+	}
 	blockBundles, allBundles, usedSbundles, mempoolTxHashes, err := w.fillTransactionsSelectAlgo(nil, work)
+	if record != nil {
+		w.recorder.save(record)
+	}
 	if err != nil {
 		return &newPayloadResult{err: err}
 	}
@@ -1875,48 +2009,95 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 	simResult := make([]*simulatedBundle, len(bundles))
 	sbSimResult := make([]*types.SimSBundle, len(sbundles))
 
-	var wg sync.WaitGroup
-	for i, bundle := range bundles {
-		if simmed, ok := simCache.GetSimulatedBundle(bundle.Hash); ok {
-			simResult[i] = simmed
-			continue
+	simulateBundle := func(idx int, bundle types.MevBundle, state *state.StateDB) {
+		start := time.Now()
+		if metrics.EnabledBuilder {
+			bundleTxNumHistogram.Update(int64(len(bundle.Txs)))
 		}
 
-		wg.Add(1)
-		go func(idx int, bundle types.MevBundle, state *state.StateDB) {
-			defer wg.Done()
+		if len(bundle.Txs) == 0 {
+			return
+		}
+		gasPool := new(core.GasPool).AddGas(env.header.GasLimit)
+		simmed, err := w.computeBundleGas(env, bundle, state, gasPool, pendingTxs, 0)
 
-			start := time.Now()
+		if metrics.EnabledBuilder {
+			simulationMeter.Mark(1)
+		}
+		if w.flashbots.reputation != nil {
+			w.flashbots.reputation.recordSimulation(&bundle, err == nil)
+		}
+
+		if err != nil {
 			if metrics.EnabledBuilder {
-				bundleTxNumHistogram.Update(int64(len(bundle.Txs)))
+				simulationRevertedMeter.Mark(1)
+				failedBundleSimulationTimer.UpdateSince(start)
 			}
 
-			if len(bundle.Txs) == 0 {
-				return
-			}
-			gasPool := new(core.GasPool).AddGas(env.header.GasLimit)
-			simmed, err := w.computeBundleGas(env, bundle, state, gasPool, pendingTxs, 0)
+			log.Trace("Error computing gas for a bundle", "error", err)
+			return
+		}
+		simResult[idx] = &simmed
 
-			if metrics.EnabledBuilder {
-				simulationMeter.Mark(1)
-			}
+		if metrics.EnabledBuilder {
+			simulationCommittedMeter.Mark(1)
+			successfulBundleSimulationTimer.UpdateSince(start)
+		}
+	}
 
-			if err != nil {
-				if metrics.EnabledBuilder {
-					simulationRevertedMeter.Mark(1)
-					failedBundleSimulationTimer.UpdateSince(start)
-				}
+	var (
+		wg       sync.WaitGroup
+		uncached = make([]int, 0, len(bundles))
+	)
+	for i, bundle := range bundles {
+		if simmed, ok := simCache.GetSimulatedBundle(bundle.Hash); ok {
+			simResult[i] = simmed
+			continue
+		}
+		uncached = append(uncached, i)
+	}
 
-				log.Trace("Error computing gas for a bundle", "error", err)
-				return
+	// Bundles which were not simulated, because their searcher is rate limited or the
+	// simulation ran out of time, are neither returned nor cached as failed.
+	skipped := make([]bool, len(bundles))
+	if reputation := w.flashbots.reputation; reputation != nil {
+		scheduled := reputation.schedule(bundles, uncached)
+		for _, idx := range uncached {
+			skipped[idx] = true
+		}
+		var deadline time.Time
+		if w.config.BundleSimulationTimeout > 0 {
+			deadline = start.Add(w.config.BundleSimulationTimeout)
+		}
+		sem := make(chan struct{}, runtime.NumCPU())
+		for _, idx := range scheduled {
+			sem <- struct{}{}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				<-sem
+				break
 			}
-			simResult[idx] = &simmed
-
-			if metrics.EnabledBuilder {
-				simulationCommittedMeter.Mark(1)
-				successfulBundleSimulationTimer.UpdateSince(start)
+			skipped[idx] = false
+			wg.Add(1)
+			go func(idx int, bundle types.MevBundle, state *state.StateDB) {
+				defer func() { <-sem; wg.Done() }()
+				simulateBundle(idx, bundle, state)
+			}(idx, bundles[idx], env.state.Copy())
+		}
+		if metrics.EnabledBuilder {
+			for _, idx := range scheduled {
+				if skipped[idx] {
+					simulationSkippedMeter.Mark(1)
+				}
 			}
-		}(i, bundle, env.state.Copy())
+		}
+	} else {
+		for _, idx := range uncached {
+			wg.Add(1)
+			go func(idx int, bundle types.MevBundle, state *state.StateDB) {
+				defer wg.Done()
+				simulateBundle(idx, bundle, state)
+			}(idx, bundles[idx], env.state.Copy())
+		}
 	}
 
 	for i, sbundle := range sbundles {
@@ -1978,7 +2159,16 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 
 	wg.Wait()
 
-	simCache.UpdateSimulatedBundles(simResult, bundles)
+	cacheResult, cacheBundles := simResult, bundles
+	if w.flashbots.reputation != nil {
+		cacheResult, cacheBundles = make([]*simulatedBundle, 0, len(bundles)), make([]types.MevBundle, 0, len(bundles))
+		for i := range bundles {
+			if !skipped[i] {
+				cacheResult, cacheBundles = append(cacheResult, simResult[i]), append(cacheBundles, bundles[i])
+			}
+		}
+	}
+	simCache.UpdateSimulatedBundles(cacheResult, cacheBundles)
 	simBundleCount := 0
 	for _, bundle := range simResult {
 		if bundle != nil {
@@ -665,7 +665,7 @@ func testBundles(t *testing.T) {
 
 		blockNumber := big.NewInt(0).Add(w.chain.CurrentBlock().Number, big.NewInt(1))
 		for _, bundle := range bundles {
-			err := b.txPool.AddMevBundle(bundle.Txs, blockNumber, types.EmptyUUID, common.Address{}, 0, 0, nil)
+			err := b.txPool.AddMevBundle(bundle.Txs, blockNumber, types.EmptyUUID, common.Address{}, 0, 0, nil, false)
 			require.NoError(t, err)
 		}
 
@@ -693,3 +693,31 @@ func testBundles(t *testing.T) {
 		t.Log("Balances", balancePre, balancePost)
 	}
 }
+
+func TestRecordBestBlock(t *testing.T) {
+	w := &worker{}
+	parent, otherParent := common.Hash{1}, common.Hash{2}
+	block := func(parent common.Hash, number int64, extra byte) *types.Block {
+		return types.NewBlockWithHeader(&types.Header{ParentHash: parent, Number: big.NewInt(number), Extra: []byte{extra}})
+	}
+
+	low, high := block(parent, 10, 0), block(parent, 10, 1)
+	w.recordBestBlock(low, big.NewInt(1))
+	w.recordBestBlock(high, big.NewInt(2))
+	w.recordBestBlock(block(parent, 10, 2), big.NewInt(2))
+	best, profit := w.bestPendingBlock(parent)
+	require.Equal(t, high.Hash(), best.Hash())
+	require.Equal(t, big.NewInt(2), profit)
+
+	// Blocks on older parents are ignored, blocks on new parents replace the best block.
+	w.recordBestBlock(block(otherParent, 9, 0), big.NewInt(5))
+	best, _ = w.bestPendingBlock(parent)
+	require.Equal(t, high.Hash(), best.Hash())
+
+	reorged := block(otherParent, 10, 0)
+	w.recordBestBlock(reorged, big.NewInt(0))
+	best, _ = w.bestPendingBlock(parent)
+	require.Nil(t, best)
+	best, _ = w.bestPendingBlock(otherParent)
+	require.Equal(t, reorged.Hash(), best.Hash())
+}
@@ -204,6 +204,19 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCLimits holds the API keys accepted on the HTTP and WebSocket endpoints, with
+	// their call limits per method. All calls are accepted when not set.
+	RPCLimits *rpc.LimitsConfig `toml:",omitempty"`
+
+	// RPCRecordFile is the file the calls served over HTTP and WebSocket are recorded
+	// to, rotated once larger than RPCRecordMaxSize megabytes. Calls aren't recorded
+	// when not set.
+	RPCRecordFile    string `toml:",omitempty"`
+	RPCRecordMaxSize int    `toml:",omitempty"`
+
+	// RPCRecordMethods restricts the recorded calls to these methods.
+	RPCRecordMethods []string `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
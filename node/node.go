@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -38,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gofrs/flock"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Node is a container on which services can be registered.
@@ -55,14 +57,15 @@ type Node struct {
 	state         int           // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	httpAuth      *httpServer //
-	wsAuth        *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle    // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API      // List of APIs currently provided by the node
+	http          *httpServer    //
+	ws            *httpServer    //
+	httpAuth      *httpServer    //
+	wsAuth        *httpServer    //
+	ipc           *ipcServer     // Stores information about the ipc http server
+	inprocHandler *rpc.Server    // In-process RPC request handler to process the API requests
+	rpcRecorder   io.WriteCloser // Rotating file the served HTTP and WebSocket calls are recorded to
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -153,6 +156,12 @@ func New(conf *Config) (*Node, error) {
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	if conf.RPCRecordFile != "" {
+		node.rpcRecorder = &lumberjack.Logger{
+			Filename: conf.RPCRecordFile,
+			MaxSize:  conf.RPCRecordMaxSize,
+		}
+	}
 
 	return node, nil
 }
@@ -239,6 +248,11 @@ func (n *Node) doClose(errs []error) error {
 	if err := n.accman.Close(); err != nil {
 		errs = append(errs, err)
 	}
+	if n.rpcRecorder != nil {
+		if err := n.rpcRecorder.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if n.keyDirTemp {
 		if err := os.RemoveAll(n.keyDir); err != nil {
 			errs = append(errs, err)
@@ -407,6 +421,9 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		limits:                 n.config.RPCLimits,
+		recorder:               n.rpcRecorder,
+		recordMethods:          n.config.RPCRecordMethods,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -57,6 +57,9 @@ type rpcEndpointConfig struct {
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	limits                 *rpc.LimitsConfig
+	recorder               io.Writer
+	recordMethods          []string
 }
 
 type rpcHandler struct {
@@ -308,6 +311,12 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	if err := srv.SetLimits(config.limits); err != nil {
+		return err
+	}
+	if config.recorder != nil {
+		srv.SetRecorder(config.recorder, config.recordMethods)
+	}
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -343,6 +352,12 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	if err := srv.SetLimits(config.limits); err != nil {
+		return err
+	}
+	if config.recorder != nil {
+		srv.SetRecorder(config.recorder, config.recordMethods)
+	}
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
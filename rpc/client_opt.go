@@ -41,6 +41,8 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	limiter            *limiter
+	recorder           *recorder
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -55,12 +55,15 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(limitExceededError)
+	_ Error = new(unauthorizedError)
 )
 
 const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeLimitExceeded    = -32005
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -154,3 +157,19 @@ type internalServerError struct {
 func (e *internalServerError) ErrorCode() int { return e.code }
 
 func (e *internalServerError) Error() string { return e.message }
+
+// limitExceededError is returned when a call exceeds the rate or concurrency limit of
+// the method for the API key of the client.
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return errcodeLimitExceeded }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+// unauthorizedError is returned when the API key of the client is unknown, or missing
+// on servers not allowing anonymous calls.
+type unauthorizedError struct{}
+
+func (e *unauthorizedError) ErrorCode() int { return errcodeDefault }
+
+func (e *unauthorizedError) Error() string { return "unauthorized: invalid or missing API key" }
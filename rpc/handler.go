@@ -62,6 +62,8 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	limiter              *limiter  // call limits of the server, nil on clients
+	recorder             *recorder // records the served calls, nil on clients
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -468,6 +470,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 	case msg.isCall():
 		resp := h.handleCall(ctx, msg)
+		if h.recorder != nil {
+			h.recorder.record(ctx.ctx, msg, resp, start)
+		}
 		var ctx []interface{}
 		ctx = append(ctx, "reqid", idForLog{msg.ID}, "duration", time.Since(start))
 		if resp.Error != nil {
@@ -503,6 +508,13 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	if callb != h.unsubscribeCb {
+		release, err := h.acquireLimits(cp, msg.Method)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
@@ -543,6 +555,11 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
+	release, err := h.acquireLimits(cp, msg.Method)
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	defer release()
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
@@ -560,6 +577,15 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	return h.runMethod(ctx, msg, callb, args)
 }
 
+// acquireLimits checks the call limits of the method for the API key of the client.
+// The returned function must be called once the call is done.
+func (h *handler) acquireLimits(cp *callProc, method string) (func(), error) {
+	if h.limiter == nil {
+		return func() {}, nil
+	}
+	return h.limiter.acquire(PeerInfoFromContext(cp.ctx).apiKey, method)
+}
+
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
@@ -0,0 +1,173 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// APIKeyHeader is the HTTP header holding the API key of HTTP and WebSocket clients.
+	APIKeyHeader = "X-Api-Key"
+
+	// anyMethod is the method whose limits apply to the methods without their own.
+	anyMethod = "*"
+
+	// anonymousKeyName identifies the calls without API key in the metrics.
+	anonymousKeyName = "anonymous"
+)
+
+// MethodLimit limits the calls of a method made with an API key.
+type MethodLimit struct {
+	Rate          float64 `json:"rate"`          // calls per second, 0 for no rate limit
+	Burst         int     `json:"burst"`         // calls allowed at once over the rate, 1 if not set
+	MaxConcurrent int     `json:"maxConcurrent"` // calls running at the same time, 0 for no limit
+}
+
+// APIKey is a key authenticating clients, with its call limits per method. The limits
+// of the "*" method apply to each method without its own limits.
+type APIKey struct {
+	Name   string                 `json:"name"` // identifies the key in the logs and metrics
+	Key    string                 `json:"key"`
+	Limits map[string]MethodLimit `json:"limits"`
+}
+
+// LimitsConfig holds the API keys accepted by a server. Calls without an API key are
+// limited by the Anonymous limits, and rejected when Anonymous is nil.
+type LimitsConfig struct {
+	Keys      []APIKey               `json:"keys"`
+	Anonymous map[string]MethodLimit `json:"anonymous"`
+}
+
+// limiter enforces the call limits of the API keys.
+type limiter struct {
+	keys      map[string]*keyLimiter
+	anonymous *keyLimiter
+}
+
+func newLimiter(config *LimitsConfig) (*limiter, error) {
+	l := &limiter{keys: make(map[string]*keyLimiter)}
+	names := make(map[string]bool)
+	for _, key := range config.Keys {
+		if key.Key == "" || key.Name == "" {
+			return nil, fmt.Errorf("API key %q: key and name must be set", key.Name)
+		}
+		if names[key.Name] || key.Name == anonymousKeyName {
+			return nil, fmt.Errorf("API key %q: duplicate name", key.Name)
+		}
+		if l.keys[key.Key] != nil {
+			return nil, fmt.Errorf("API key %q: duplicate key", key.Name)
+		}
+		names[key.Name] = true
+		l.keys[key.Key] = newKeyLimiter(key.Name, key.Limits)
+	}
+	if config.Anonymous != nil {
+		l.anonymous = newKeyLimiter(anonymousKeyName, config.Anonymous)
+	}
+	return l, nil
+}
+
+// acquire checks the limits of the method for the key. The returned function must be
+// called once the call is done.
+func (l *limiter) acquire(key, method string) (func(), error) {
+	kl := l.anonymous
+	if key != "" {
+		kl = l.keys[key]
+	}
+	if kl == nil {
+		unauthorizedMeter.Mark(1)
+		return nil, &unauthorizedError{}
+	}
+	return kl.acquire(method)
+}
+
+// keyLimiter enforces the call limits of an API key, the limiters of the methods
+// being created on their first call.
+type keyLimiter struct {
+	name    string
+	limits  map[string]MethodLimit
+	mu      sync.Mutex
+	methods map[string]*methodLimiter
+}
+
+type methodLimiter struct {
+	rate *rate.Limiter // nil without rate limit
+	sem  chan struct{} // nil without concurrency limit
+}
+
+func newKeyLimiter(name string, limits map[string]MethodLimit) *keyLimiter {
+	return &keyLimiter{
+		name:    name,
+		limits:  limits,
+		methods: make(map[string]*methodLimiter),
+	}
+}
+
+func (kl *keyLimiter) method(method string) *methodLimiter {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	if ml, ok := kl.methods[method]; ok {
+		return ml
+	}
+	limit, ok := kl.limits[method]
+	if !ok {
+		limit, ok = kl.limits[anyMethod]
+	}
+	var ml *methodLimiter
+	if ok {
+		ml = new(methodLimiter)
+		if limit.Rate > 0 {
+			burst := limit.Burst
+			if burst < 1 {
+				burst = 1
+			}
+			ml.rate = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+		}
+		if limit.MaxConcurrent > 0 {
+			ml.sem = make(chan struct{}, limit.MaxConcurrent)
+		}
+	}
+	kl.methods[method] = ml
+	return ml
+}
+
+func (kl *keyLimiter) acquire(method string) (func(), error) {
+	ml := kl.method(method)
+	if ml == nil {
+		return func() {}, nil
+	}
+	if ml.rate != nil && !ml.rate.Allow() {
+		markLimitsMeter(kl.name, method, "ratelimited")
+		return nil, &limitExceededError{fmt.Sprintf("rate limit exceeded for %s", method)}
+	}
+	if ml.sem == nil {
+		markLimitsMeter(kl.name, method, "accepted")
+		return func() {}, nil
+	}
+	select {
+	case ml.sem <- struct{}{}:
+		markLimitsMeter(kl.name, method, "accepted")
+		return func() { <-ml.sem }, nil
+	default:
+		markLimitsMeter(kl.name, method, "concurrency")
+		return nil, &limitExceededError{fmt.Sprintf("too many concurrent calls of %s", method)}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func checkErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected error with code %d, got %v", code, err)
+	}
+	if rpcErr.ErrorCode() != code {
+		t.Fatalf("wrong error code %d, want %d: %v", rpcErr.ErrorCode(), code, err)
+	}
+}
+
+func TestServerLimits(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	err := server.SetLimits(&LimitsConfig{
+		Keys: []APIKey{{
+			Name: "searcher",
+			Key:  "secret",
+			Limits: map[string]MethodLimit{
+				"test_echo": {Rate: 0.001, Burst: 2},
+				"*":         {MaxConcurrent: 1},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(wssrv.URL, "http:")
+
+	// Calls without key are rejected when anonymous calls aren't allowed.
+	anonymous, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer anonymous.Close()
+	err = anonymous.Call(nil, "test_echo", "x", 1)
+	checkErrorCode(t, err, errcodeDefault)
+
+	// The burst of the rate limit is allowed.
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithHeader(APIKeyHeader, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for i := 0; i < 2; i++ {
+		if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	err = client.Call(nil, "test_echo", "x", 1)
+	checkErrorCode(t, err, errcodeLimitExceeded)
+
+	// Concurrent calls are limited across the connections of the key.
+	ws1, err := DialOptions(context.Background(), wsURL, WithHeader(APIKeyHeader, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws1.Close()
+	ws2, err := DialOptions(context.Background(), wsURL, WithHeader(APIKeyHeader, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws2.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- ws1.Call(nil, "test_sleep", 2*time.Second) }()
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := ws2.Call(nil, "test_sleep", 0)
+		if err != nil {
+			checkErrorCode(t, err, errcodeLimitExceeded)
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("concurrent call not limited")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	// The call slot is released once the call is done.
+	if err := ws2.Call(nil, "test_sleep", 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitsConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []*LimitsConfig{
+		{Keys: []APIKey{{Name: "a"}}},
+		{Keys: []APIKey{{Key: "k"}}},
+		{Keys: []APIKey{{Name: "a", Key: "k1"}, {Name: "a", Key: "k2"}}},
+		{Keys: []APIKey{{Name: "a", Key: "k"}, {Name: "b", Key: "k"}}},
+		{Keys: []APIKey{{Name: anonymousKeyName, Key: "k"}}},
+	} {
+		if err := NewServer().SetLimits(config); err == nil {
+			t.Errorf("expected error for %+v", config.Keys)
+		}
+	}
+}
@@ -32,6 +32,11 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// limitsMeterName is the prefix of the per-key and per-method call limit meters.
+	limitsMeterName = "rpc/limits"
+
+	unauthorizedMeter = metrics.NewRegisteredMeter("rpc/limits/unauthorized", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -48,3 +53,10 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 	}
 	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Nanoseconds())
 }
+
+// markLimitsMeter tracks the outcome of the call limit check of a call, the key being
+// identified by its name.
+func markLimitsMeter(key, method, outcome string) {
+	m := fmt.Sprintf("%s/%s/%s/%s", limitsMeterName, key, method, outcome)
+	metrics.GetOrRegisterMeter(m, nil).Mark(1)
+}
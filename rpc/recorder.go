@@ -0,0 +1,100 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RecordedCall is a call recorded by the server, written as a line of JSON.
+type RecordedCall struct {
+	Time     time.Time       `json:"time"`
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+	Duration time.Duration   `json:"duration"`
+	Peer     PeerInfo        `json:"peer"`
+}
+
+// recorder writes the calls served by a server.
+type recorder struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	methods map[string]bool // nil to record all methods
+}
+
+func newRecorder(w io.Writer, methods []string) *recorder {
+	r := &recorder{enc: json.NewEncoder(w)}
+	if len(methods) > 0 {
+		r.methods = make(map[string]bool, len(methods))
+		for _, method := range methods {
+			r.methods[method] = true
+		}
+	}
+	return r
+}
+
+func (r *recorder) record(ctx context.Context, msg, resp *jsonrpcMessage, start time.Time) {
+	if r.methods != nil && !r.methods[msg.Method] {
+		return
+	}
+	call := &RecordedCall{
+		Time:     start,
+		Method:   msg.Method,
+		Params:   msg.Params,
+		Result:   resp.Result,
+		Duration: time.Since(start),
+		Peer:     PeerInfoFromContext(ctx),
+	}
+	if resp.Error != nil {
+		blob, err := json.Marshal(resp.Error)
+		if err != nil {
+			log.Warn("Failed to encode recorded RPC error", "method", msg.Method, "err", err)
+			return
+		}
+		call.Error = blob
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(call); err != nil {
+		log.Warn("Failed to record RPC call", "method", msg.Method, "err", err)
+	}
+}
+
+// ReadRecordedCalls reads the calls recorded by a server, calling fn for each call in
+// order.
+func ReadRecordedCalls(r io.Reader, fn func(*RecordedCall) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var call RecordedCall
+		if err := dec.Decode(&call); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(&call); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 flashbots
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+func TestServerRecorder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		server = newTestServer()
+		buf    = new(syncBuffer)
+	)
+	defer server.Stop()
+	server.SetRecorder(buf, []string{"test_echo", "test_returnError"})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	// Calls of other methods aren't recorded.
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	var calls []*RecordedCall
+	err = ReadRecordedCalls(bytes.NewReader(buf.Bytes()), func(call *RecordedCall) error {
+		calls = append(calls, call)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("wrong number of recorded calls %d, want 2", len(calls))
+	}
+	echo := calls[0]
+	if echo.Method != "test_echo" {
+		t.Fatalf("wrong method %q", echo.Method)
+	}
+	if string(echo.Params) != `["x",1]` {
+		t.Fatalf("wrong params %s", echo.Params)
+	}
+	if string(echo.Result) != `{"String":"x","Int":1,"Args":null}` {
+		t.Fatalf("wrong result %s", echo.Result)
+	}
+	if echo.Error != nil {
+		t.Fatalf("unexpected error %s", echo.Error)
+	}
+	if echo.Peer.Transport != "http" || echo.Peer.RemoteAddr == "" {
+		t.Fatalf("wrong peer info %+v", echo.Peer)
+	}
+	if echo.Time.IsZero() || echo.Duration <= 0 {
+		t.Fatalf("wrong timing %v %v", echo.Time, echo.Duration)
+	}
+	failed := calls[1]
+	if failed.Method != "test_returnError" || failed.Result != nil {
+		t.Fatalf("wrong failed call %+v", failed)
+	}
+	if string(failed.Error) != `{"code":444,"message":"testError","data":"testError data"}` {
+		t.Fatalf("wrong error %s", failed.Error)
+	}
+}
@@ -53,6 +53,8 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+	limiter            *limiter
+	recorder           *recorder
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -88,6 +90,38 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetLimits sets the API keys accepted by the server, with their call limits per
+// method. Without limits, all calls are accepted.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetLimits(config *LimitsConfig) error {
+	if config == nil {
+		s.limiter = nil
+		return nil
+	}
+	l, err := newLimiter(config)
+	if err != nil {
+		return err
+	}
+	s.limiter = l
+	return nil
+}
+
+// SetRecorder makes the server write the calls it serves to w, as lines of JSON encoded
+// RecordedCalls. Only the calls of the given methods are recorded, unless methods is
+// empty. A nil writer disables the recording.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRecorder(w io.Writer, methods []string) {
+	if w == nil {
+		s.recorder = nil
+		return
+	}
+	s.recorder = newRecorder(w, methods)
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -113,6 +147,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		limiter:            s.limiter,
+		recorder:           s.recorder,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -148,6 +184,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.limiter = s.limiter
+	h.recorder = s.recorder
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -220,6 +258,9 @@ type PeerInfo struct {
 		Origin    string
 		Host      string
 	}
+
+	// API key sent by HTTP and WebSocket clients, checked by the server limits.
+	apiKey string
 }
 
 type peerInfoContextKey struct{}